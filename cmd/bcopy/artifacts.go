@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/spf13/viper"
+)
+
+// defaultArtifacts match payloads saved by earlier runs, following the
+// convention of naming them *.bcopy.md
+var defaultArtifacts = []string{"*.bcopy.md"}
+
+// excludeArtifacts keeps the files and directories this run writes to,
+// those earlier runs wrote to, and earlier payloads matching the artifacts
+// config key, out of the collection, so a payload saved inside the scanned
+// tree is never embedded in the next one
+func excludeArtifacts(filter *analyzer.Filter, root string, sinks []sink) {
+	patterns := defaultArtifacts
+	if viper.IsSet("artifacts") {
+		patterns = configStringSlice("artifacts")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return
+	}
+	outputs := outputPaths(sinks)
+	if earlier, err := history.Outputs(); err == nil {
+		outputs = append(outputs, earlier...)
+	} else {
+		slog.Debug("failed to read earlier outputs", "error", err)
+	}
+	for _, absOut := range outputs {
+		rel, err := filepath.Rel(absRoot, absOut)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		slog.Debug("output excluded from collection", "path", rel)
+		patterns = append(patterns, "/"+glob.QuoteMeta(filepath.ToSlash(rel)))
+	}
+	filter.IgnorePatterns(patterns)
+}
+
+// recordOutputs remembers the files and directories this run wrote to, so
+// later runs leave them out even when they are named like source files
+func recordOutputs(sinks []sink) {
+	if noHistory {
+		return
+	}
+	if err := history.RecordOutputs(outputPaths(sinks)); err != nil {
+		slog.Warn("failed to record outputs", "error", err)
+	}
+}
+
+// outputPaths returns the absolute paths of the file sinks
+func outputPaths(sinks []sink) []string {
+	var paths []string
+	for _, s := range sinks {
+		target, out := sinkTarget(s)
+		if target != "file" || out == "" {
+			continue
+		}
+		absOut, err := filepath.Abs(out)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, absOut)
+	}
+	return paths
+}
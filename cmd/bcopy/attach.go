@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/language"
+)
+
+// readAttachments reads the --attach arguments, each a file or - for
+// stdin, optionally preceded by "title=". Without a title a file is named
+// by its path and stdin "stdin". stdinTaken reports that stdin was already
+// read for something else.
+func readAttachments(specs []string, stdinTaken bool) ([]collector.Attachment, error) {
+	var attachments []collector.Attachment
+	readStdin := false
+	for _, spec := range specs {
+		title, source := "", spec
+		// A file whose name contains "=" is taken as is
+		if t, s, ok := strings.Cut(spec, "="); ok && t != "" {
+			if _, err := os.Stat(spec); err != nil {
+				title, source = t, s
+			}
+		}
+
+		var data []byte
+		var err error
+		if source == "-" {
+			switch {
+			case stdinTaken:
+				return nil, errors.New("stdin is already read by --selection -")
+			case readStdin:
+				return nil, errors.New("stdin can only be attached once")
+			}
+			readStdin = true
+			data, err = io.ReadAll(os.Stdin)
+			if title == "" {
+				title = "stdin"
+			}
+		} else {
+			if bannedByPolicy(source) {
+				return nil, fmt.Errorf("%s is banned by the policy in %s", source, orgPolicy.Path)
+			}
+			data, err = os.ReadFile(source)
+			if title == "" {
+				title = source
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", source, err)
+		}
+
+		attachments = append(attachments, collector.Attachment{
+			Title:    title,
+			Language: language.Detect(source),
+			Content:  string(data),
+		})
+	}
+	return attachments, nil
+}
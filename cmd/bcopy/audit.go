@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/audit"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/viper"
+)
+
+// auditRun appends the run to the audit log when audit is on in the
+// config, with one destination per sink. written is the most bytes any
+// sink wrote.
+func auditRun(root string, result *collector.CollectionResult, written int64, sinks []sink) {
+	var destinations []string
+	for _, s := range sinks {
+		target, path := sinkTarget(s)
+		if path != "" {
+			if target == "file" {
+				if abs, err := filepath.Abs(path); err == nil {
+					path = abs
+				}
+			}
+			target += ":" + path
+		}
+		destinations = append(destinations, target)
+	}
+	auditResult(root, result, written, destinations...)
+}
+
+// auditResult appends a collected result sent to destinations, each a
+// policy target optionally followed by ":" and a path or name. result must
+// hold the files' real paths, before --strip-prefix, --add-prefix, or
+// path anonymization rewrite them, and written is the size of the output
+// as sent, framing and appendix included.
+func auditResult(root string, result *collector.CollectionResult, written int64, destinations ...string) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		absRoot = root
+	}
+	paths := make([]string, len(result.Files))
+	for i, file := range result.Files {
+		paths[i] = file.RelPath
+	}
+	auditPayload(audit.Record{
+		Root:         absRoot,
+		PathsHash:    audit.HashPaths(paths),
+		FileCount:    result.FileCount,
+		Bytes:        written,
+		Tokens:       collector.EstimateTokens(written),
+		Destinations: destinations,
+	})
+}
+
+// auditPayload fills in the time, user and host of rec and appends it to
+// the audit log when audit is on in the config. audit-log names the file
+// (default: audit.jsonl in the bcopy cache directory) or "syslog". A failed
+// write is reported but doesn't fail the command, whose content has
+// already gone out.
+func auditPayload(rec audit.Record) {
+	if !viper.GetBool("audit") {
+		return
+	}
+
+	dest := viper.GetString("audit-log")
+	if dest == "" {
+		dir, err := history.Dir()
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			ui.Status("warn.audit", err)
+			return
+		}
+		dest = filepath.Join(dir, "audit.jsonl")
+	}
+
+	rec.Time = time.Now()
+	rec.User = audit.CurrentUser()
+	rec.Host, _ = os.Hostname()
+	if err := audit.Write(dest, rec); err != nil {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("warn.audit", err)
+	}
+}
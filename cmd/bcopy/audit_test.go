@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nodelike/bcopy/internal/audit"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/spf13/viper"
+)
+
+func TestAuditRecordsRealPathsAndSentBytes(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "audit.jsonl")
+	viper.Set("audit", true)
+	viper.Set("audit-log", log)
+	t.Cleanup(func() {
+		viper.Set("audit", nil)
+		viper.Set("audit-log", nil)
+	})
+	discardStdout(t)
+
+	// As with --add-prefix: the sink writes the rewritten paths, plus an
+	// appendix the file sizes don't count
+	result := testResult()
+	renamed := result.Renamed(func(relPath string) string { return "vendor/app/" + relPath })
+	out := fileSink{path: filepath.Join(dir, "out.md")}
+	written, err := out.write(renamed, collector.FormatOptions{Appendix: "## Attachment: notes.txt\n\nnotes\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRun(dir, result, written, []sink{out})
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec audit.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("audit log %q: %v", data, err)
+	}
+	if want := audit.HashPaths([]string{"main.go"}); rec.PathsHash != want {
+		t.Errorf("PathsHash = %s, want the hash of the real paths %s", rec.PathsHash, want)
+	}
+	info, err := os.Stat(out.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Bytes != info.Size() || rec.Bytes <= result.TotalSize {
+		t.Errorf("Bytes = %d, want the %d bytes written (files alone: %d)", rec.Bytes, info.Size(), result.TotalSize)
+	}
+}
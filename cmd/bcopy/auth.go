@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/credentials"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage tokens for upload and LLM services",
+	Long: `Manage the tokens bcopy uses for gist uploads and LLM submission. Tokens are
+kept in the OS keychain (macOS Keychain, Windows Credential Manager, or the
+Secret Service on Linux), never in the config file. A BCOPY_<SERVICE>_TOKEN
+environment variable takes precedence over the keychain.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login <service>",
+	Short: "Store a token in the OS keychain",
+	Example: `  bcopy auth login github
+  echo "$TOKEN" | bcopy auth login anthropic`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service, err := credentials.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if err := orgPolicy.Check(service.Name); err != nil {
+			return err
+		}
+
+		var token string
+		if isInteractive() {
+			fmt.Fprintf(os.Stderr, "Paste %s: ", service.Description)
+			raw, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return err
+			}
+			token = string(raw)
+		} else {
+			line, err := stdinReader.ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("reading token from stdin: %w", err)
+			}
+			token = line
+		}
+
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return errors.New("empty token")
+		}
+		if err := credentials.Set(service, token); err != nil {
+			return fmt.Errorf("storing token in keychain: %w", err)
+		}
+		ui.Status("auth.stored", service.Name)
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:          "logout <service>",
+	Short:        "Remove a token from the OS keychain",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service, err := credentials.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if err := credentials.Delete(service); err != nil {
+			return fmt.Errorf("%s: %w", service.Name, err)
+		}
+		ui.Status("auth.removed", service.Name)
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "Show which services have a token configured",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
+		for _, service := range credentials.Services {
+			_, source, err := credentials.Get(service)
+			var status string
+			switch {
+			case err == nil:
+				status = ui.Paint(ui.Done, "configured") + " (" + string(source) + ")"
+			case errors.Is(err, credentials.ErrNotFound):
+				status = "not configured"
+			default:
+				status = ui.Paint(ui.Warning, "unavailable") + " (" + err.Error() + ")"
+			}
+			fmt.Fprintf(out, "%-10s %s\n", service.Name, status)
+		}
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authStatusCmd)
+	rootCmd.AddCommand(authCmd)
+}
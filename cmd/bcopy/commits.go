@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// commitHistory renders the last limit commits of the repository around
+// root as a payload section for --with-history. With perPath only commits
+// touching the selected files count. It returns "" outside a git
+// repository or when history can't be read.
+func commitHistory(root string, result *collector.CollectionResult, limit int, perPath bool) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+	repoRoot, err := analyzer.GetRepoRoot(absRoot)
+	if err != nil {
+		slog.Debug("no commit history: not a git repository", "path", absRoot)
+		return ""
+	}
+
+	var paths map[string]bool
+	if perPath {
+		paths = make(map[string]bool, len(result.Files))
+		for _, file := range result.Files {
+			paths[filepath.Join(absRoot, file.RelPath)] = true
+		}
+	}
+
+	commits, err := analyzer.RecentCommits(repoRoot, limit, paths)
+	if err != nil {
+		slog.Warn("failed to read commit history", "error", err)
+		return ""
+	}
+	if len(commits) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n---\n\n## Recent commits (%d)\n", len(commits))
+	for _, c := range commits {
+		subject, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		fmt.Fprintf(&sb, "\n### %s %s\n\n%s, %s\n", c.Hash[:7], subject, c.Author, c.When.Format("2006-01-02"))
+		// Bodies are quoted so lines like "File: ./x" can't pass for file
+		// headers when the payload is read back
+		if body = strings.TrimSpace(body); body != "" {
+			sb.WriteString("\n")
+			for _, line := range strings.Split(body, "\n") {
+				sb.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
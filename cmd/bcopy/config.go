@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// envPrefix namespaces the environment variables bcopy reads
+const envPrefix = "BCOPY"
+
+// envKeyReplacer maps config keys to environment variable suffixes, so
+// hard-max becomes BCOPY_HARD_MAX and always-exclude.add
+// BCOPY_ALWAYS_EXCLUDE_ADD
+var envKeyReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// configSectionKeys are config keys without a matching flag
+var configSectionKeys = map[string]string{
+	"artifacts":              "Patterns of earlier payloads left out of the collection (default: *.bcopy.md)",
+	"audit":                  "Append a record of every run to the audit log",
+	"audit-log":              "Audit log file, or syslog (default: audit.jsonl in the cache directory)",
+	"always-exclude.add":     "Patterns added to the built-in exclusion list",
+	"always-exclude.remove":  "Patterns or directory names removed from the built-in exclusion list",
+	"always-exclude.replace": "Replacement for the built-in exclusion list",
+}
+
+// commandLineOnly are flags never read from the config file or the
+// environment: --run and --attach would let a config file checked into a
+// cloned repository run commands or attach any readable file
+var commandLineOnly = map[string]bool{"config": true, "run": true, "attach": true}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect bcopy configuration",
+}
+
+var configEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List the environment variables bcopy reads",
+	Long: `List every environment variable bcopy reads. Each config key can be set as
+BCOPY_<KEY> with dashes and dots replaced by underscores. Environment
+variables override the config file; command-line flags override both.
+List values are comma-separated.`,
+	Example: `  BCOPY_HARD_MAX=100 BCOPY_EXT=go,proto bcopy`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		usage := make(map[string]string)
+		rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			usage[f.Name] = f.Usage
+		})
+		for key, desc := range configSectionKeys {
+			usage[key] = desc
+		}
+
+		keys := viper.AllKeys()
+		for key := range configSectionKeys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		seen := make(map[string]bool)
+		for _, key := range keys {
+			desc, ok := usage[key]
+			if !ok || seen[key] || commandLineOnly[key] {
+				continue
+			}
+			seen[key] = true
+			fmt.Printf("%-32s %s\n", envName(key), desc)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configEnvCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// envName returns the environment variable for a config key
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// configStringSlice reads a list setting. Values from the environment
+// arrive as a single string and are split on commas.
+func configStringSlice(key string) []string {
+	if s, ok := viper.Get(key).(string); ok {
+		var values []string
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	return viper.GetStringSlice(key)
+}
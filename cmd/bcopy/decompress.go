@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nodelike/bcopy/internal/compress"
+	"github.com/spf13/cobra"
+)
+
+var decompressOutput string
+
+var decompressCmd = &cobra.Command{
+	Use:   "decompress <file>",
+	Short: "Decompress a payload written with --compress",
+	Long: `Decompress a gzip or zstd payload written with --compress. The format is
+detected from the file contents. Output goes to stdout unless -o is given.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runDecompress,
+}
+
+func init() {
+	decompressCmd.Flags().StringVarP(&decompressOutput, "output", "o", "", "Write decompressed output to file instead of stdout")
+	rootCmd.AddCommand(decompressCmd)
+}
+
+func runDecompress(cmd *cobra.Command, args []string) error {
+	in, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := compress.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	defer r.Close()
+
+	var out io.Writer = os.Stdout
+	if decompressOutput != "" {
+		f, err := os.Create(decompressOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	_, err = io.Copy(out, r)
+	return err
+}
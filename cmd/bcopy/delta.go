@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+// applyDelta narrows result to the files added or changed since the last
+// recorded run of root and returns a header describing the delta. Without a
+// previous run the full result is returned with an empty header.
+func applyDelta(root string, result *collector.CollectionResult) (*collector.CollectionResult, string) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("error", err)
+		os.Exit(1)
+	}
+
+	last, _, err := history.Last(absRoot)
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("error.history", err)
+		os.Exit(1)
+	}
+	if last == nil {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("warn.no-history")
+		return result, ""
+	}
+
+	current := make(map[string]bool, len(result.Files))
+	subset := result.Subset(func(file collector.FileData) bool {
+		current[file.RelPath] = true
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return true
+		}
+		return last.Files[file.RelPath] != history.Hash(content)
+	})
+
+	var removed []string
+	for path := range last.Files {
+		if !current[path] {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+
+	var sb strings.Builder
+	if reproducible {
+		sb.WriteString("# Delta since the previous run\n\n")
+	} else {
+		fmt.Fprintf(&sb, "# Delta since %s\n\n", last.Time.UTC().Format("2006-01-02 15:04:05 UTC"))
+	}
+	fmt.Fprintf(&sb, "%d files added or changed since the previous payload; unchanged files are omitted.\n", subset.FileCount)
+	if len(removed) > 0 {
+		sb.WriteString("\nRemoved:\n\n")
+		for _, path := range removed {
+			fmt.Fprintf(&sb, "- ./%s\n", path)
+		}
+	}
+	sb.WriteString("\n---\n\n")
+
+	fmt.Fprintln(os.Stderr)
+	ui.Status("delta", last.Time.Local().Format("2006-01-02 15:04:05"), subset.FileCount, len(removed))
+	return subset, sb.String()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+// showOutputDiff prints a unified diff between the --output file as it is
+// and the payload this run would write to it, without writing anything. A
+// missing file diffs as empty. It returns the number of bytes printed.
+func showOutputDiff(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	before, err := os.ReadFile(outputFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+
+	var sb strings.Builder
+	if err := collector.WriteMarkdown(&sb, result, formatOpts); err != nil {
+		return 0, fmt.Errorf("formatting output: %w", err)
+	}
+	after := sb.String()
+
+	if string(before) == after {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("unchanged", outputFile)
+		return 0, nil
+	}
+
+	edits := myers.ComputeEdits(span.URIFromPath(outputFile), string(before), after)
+	diff := gotextdiff.ToUnified(outputFile, outputFile+" (new)", string(before), edits)
+	n, err := fmt.Fprint(os.Stdout, diff)
+	return int64(n), err
+}
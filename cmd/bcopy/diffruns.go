@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/compress"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var diffRunsCmd = &cobra.Command{
+	Use:   "diff-runs [old.md] <new.md>",
+	Short: "Report files added, removed, or changed between two payloads",
+	Long: `Compare two markdown payloads written by bcopy and list the files that were
+added, removed, or changed between them. With a single argument, the payload
+is compared against the last run recorded for the current directory.
+Compressed payloads are decompressed automatically.`,
+	Example: `  bcopy diff-runs old.md new.md
+  bcopy -o new.md && bcopy diff-runs new.md`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE:         runDiffRuns,
+}
+
+func init() {
+	rootCmd.AddCommand(diffRunsCmd)
+}
+
+func runDiffRuns(cmd *cobra.Command, args []string) error {
+	oldPath, newPath := "", args[len(args)-1]
+	if len(args) == 2 {
+		oldPath = args[0]
+	} else {
+		root, err := filepath.Abs(".")
+		if err != nil {
+			return err
+		}
+		run, payload, err := history.Last(root)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return fmt.Errorf("no previous run recorded for %s", root)
+		}
+		fmt.Fprintf(os.Stderr, "Comparing against run from %s\n", run.Time.Local().Format("2006-01-02 15:04:05"))
+		oldPath = payload
+	}
+
+	oldFiles, err := readPayload(oldPath)
+	if err != nil {
+		return err
+	}
+	newFiles, err := readPayload(newPath)
+	if err != nil {
+		return err
+	}
+
+	var added, removed, changed []string
+	for path, content := range newFiles {
+		old, ok := oldFiles[path]
+		switch {
+		case !ok:
+			added = append(added, path)
+		case old != content:
+			changed = append(changed, path)
+		}
+	}
+	for path := range oldFiles {
+		if _, ok := newFiles[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+
+	out := cmd.OutOrStdout()
+	for _, path := range added {
+		fmt.Fprintln(out, ui.Paint(ui.Done, "+ "+path))
+	}
+	for _, path := range removed {
+		fmt.Fprintln(out, ui.Paint(ui.Failure, "- "+path))
+	}
+	for _, path := range changed {
+		fmt.Fprintf(out, "%s (%s → %s)\n", ui.Paint(ui.Warning, "~ "+path),
+			collector.FormatSize(int64(len(oldFiles[path]))), collector.FormatSize(int64(len(newFiles[path]))))
+	}
+	fmt.Fprintf(out, "%d added, %d removed, %d changed, %d unchanged\n",
+		len(added), len(removed), len(changed), len(newFiles)-len(added)-len(changed))
+	return nil
+}
+
+// readPayload parses a payload file, decompressing it if needed, into a map
+// of relative path to content
+func readPayload(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if dr, err := compress.NewReader(f); err == nil {
+		defer dr.Close()
+		r = dr
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	files, err := collector.ParsePayload(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no files found; is this a bcopy payload?", path)
+	}
+
+	byPath := make(map[string]string, len(files))
+	for _, file := range files {
+		byPath[file.RelPath] = file.Content
+	}
+	return byPath, nil
+}
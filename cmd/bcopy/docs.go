@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsDir string
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate man pages or markdown documentation",
+	Long: `Generate reference documentation for bcopy and its subcommands, for
+packagers shipping man pages or projects publishing docs.`,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:     "man",
+	Short:   "Generate man pages",
+	Example: "  bcopy docs man --dir ./man",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		header := &doc.GenManHeader{
+			Title:   "BCOPY",
+			Section: "1",
+			Source:  "bcopy " + rootCmd.Version,
+			Manual:  "bcopy manual",
+		}
+		return generateDocs(func() error {
+			return doc.GenManTree(rootCmd, header, docsDir)
+		})
+	},
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:     "markdown",
+	Short:   "Generate markdown documentation",
+	Example: "  bcopy docs markdown --dir ./docs",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateDocs(func() error {
+			return doc.GenMarkdownTree(rootCmd, docsDir)
+		})
+	},
+}
+
+func init() {
+	docsCmd.PersistentFlags().StringVar(&docsDir, "dir", "docs", "Directory to write the generated files to")
+	docsCmd.AddCommand(docsManCmd, docsMarkdownCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+func generateDocs(gen func() error) error {
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return err
+	}
+
+	rootCmd.DisableAutoGenTag = true
+	if err := gen(); err != nil {
+		return err
+	}
+
+	ui.Status("docs.written", docsDir)
+	return nil
+}
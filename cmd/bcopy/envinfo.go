@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/manifest"
+)
+
+// versionTimeout bounds each installed-toolchain version query
+const versionTimeout = 5 * time.Second
+
+// versionFiles pin a runtime version outside the manifests
+var versionFiles = []struct {
+	file, runtime string
+}{
+	{".nvmrc", "Node.js"},
+	{".node-version", "Node.js"},
+	{".python-version", "Python"},
+}
+
+// toolchains are the commands reporting the installed version of a runtime
+// and the field of their first output line holding it
+var toolchains = map[string]struct {
+	args  []string
+	field int
+}{
+	"Go":      {[]string{"go", "version"}, 2},
+	"Node.js": {[]string{"node", "--version"}, 0},
+	"Python":  {[]string{"python3", "--version"}, 1},
+	"Rust":    {[]string{"rustc", "--version"}, 1},
+}
+
+// environmentInfo renders the --env-info section: the OS, the runtimes the
+// project's manifests and version files ask for with the installed
+// versions, and the direct dependencies of each manifest. Manifests are
+// read from root, or from the repository root when root has none. Nothing
+// comes from environment variables, so no paths, names, or secrets leak.
+func environmentInfo(root string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+	manifests, pins := readManifests(absRoot)
+	if len(manifests) == 0 {
+		if repoRoot, err := analyzer.GetRepoRoot(absRoot); err == nil && repoRoot != absRoot {
+			manifests, pins = readManifests(repoRoot)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n---\n\n## Environment\n\n")
+	fmt.Fprintf(&sb, "- OS: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+	seen := make(map[string]bool)
+	addRuntime := func(name, required, source string) {
+		if required == "" && seen[name] {
+			return
+		}
+		line := "- " + name
+		if required != "" {
+			line += fmt.Sprintf(" %s (%s)", required, source)
+		}
+		if !seen[name] {
+			seen[name] = true
+			if installed := installedVersion(name); installed != "" {
+				line += ", installed: " + installed
+			}
+		}
+		sb.WriteString(line + "\n")
+	}
+	// Stated versions first, so a runtime only some manifest names is
+	// listed once and with its version
+	for _, m := range manifests {
+		if m.RuntimeVersion != "" {
+			addRuntime(m.Runtime, m.RuntimeVersion, m.File)
+		}
+	}
+	for _, pin := range pins {
+		addRuntime(pin.runtime, pin.version, pin.file)
+	}
+	for _, m := range manifests {
+		addRuntime(m.Runtime, "", m.File)
+	}
+
+	for _, m := range manifests {
+		if len(m.Dependencies) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### Dependencies (%s)\n\n", m.File)
+		for _, dep := range m.Dependencies {
+			sb.WriteString("- " + dep.String() + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// versionPin is a runtime version from a file such as .nvmrc
+type versionPin struct {
+	file, runtime, version string
+}
+
+// readManifests parses the manifests and version files directly in dir
+func readManifests(dir string) ([]*manifest.Manifest, []versionPin) {
+	var manifests []*manifest.Manifest
+	for _, name := range manifest.Files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if m := manifest.Parse(name, data); m != nil {
+			manifests = append(manifests, m)
+		} else {
+			slog.Debug("manifest not parsed", "file", name)
+		}
+	}
+
+	var pins []versionPin
+	for _, vf := range versionFiles {
+		data, err := os.ReadFile(filepath.Join(dir, vf.file))
+		if err != nil {
+			continue
+		}
+		if version, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n"); version != "" {
+			pins = append(pins, versionPin{file: vf.file, runtime: vf.runtime, version: strings.TrimSpace(version)})
+		}
+	}
+	return manifests, pins
+}
+
+// installedVersion asks the local toolchain of a runtime for its version,
+// returning "" when it isn't installed
+func installedVersion(runtimeName string) string {
+	tc, ok := toolchains[runtimeName]
+	if !ok {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, tc.args[0], tc.args[1:]...).Output()
+	if err != nil {
+		slog.Debug("toolchain version unavailable", "command", tc.args[0], "error", err)
+		return ""
+	}
+	first, _, _ := strings.Cut(string(out), "\n")
+	fields := strings.Fields(first)
+	if tc.field >= len(fields) {
+		return ""
+	}
+	return fields[tc.field]
+}
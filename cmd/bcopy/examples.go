@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// example is one entry in the structured examples table. Flag ties the
+// example to the flag it demonstrates ("" for general usage), so help text,
+// man pages, and markdown docs all render the same grouped list.
+type example struct {
+	Flag        string
+	Command     string
+	Description string
+}
+
+var rootExamples = []example{
+	{"", "bcopy", "Copy the current directory to the clipboard"},
+	{"", "bcopy ./src", "Copy a specific folder"},
+	{"repo-root", "bcopy --repo-root", "Copy the whole repository from a subdirectory"},
+	{"dry-run", "bcopy --dry-run | head -n 50", "Preview the output on stdout"},
+	{"output", "bcopy -o review.md", "Write the payload to a file"},
+	{"format", "bcopy -o repo.zip", "Write a zip archive with a MANIFEST.md"},
+	{"compress", "bcopy -o ctx.md --compress zstd", "Write a zstd-compressed payload"},
+	{"export-dir", "bcopy --export-dir out/", "Mirror the selected files into out/"},
+	{"per-dir-output", "bcopy --per-dir-output out/", "One payload per top-level directory"},
+	{"toc", "bcopy --toc", "Prepend a table of contents"},
+	{"group-by-dir", "bcopy --group-by-dir", "Group files under a heading per directory"},
+	{"alias-paths", "bcopy --alias-paths", "Shorten deep directories in file headers"},
+	{"metadata", "bcopy --metadata", "Note charset and line endings in headers"},
+	{"strip-prefix", "bcopy --strip-prefix svc/pay --add-prefix pay/", "Emit svc/pay/... as pay/..."},
+	{"run", `bcopy ./pkg --run "go test ./pkg/..."`, "Pair failing test output with the code"},
+	{"attach", `go test ./... 2>&1 | bcopy --attach "test output=-"`, "Add piped output after the files"},
+	{"exclude-tests", "bcopy ./src --exclude-tests", "Just the source code"},
+	{"exclude", `bcopy --exclude "\.pb\.go$"`, "Skip generated protobuf code"},
+	{"ext", "bcopy --ext .go --ext .py", "Only Go and Python files"},
+	{"no-default-excludes", "bcopy --no-default-excludes", "Include bin/, build/, dist/, and friends"},
+	{"max-depth", "bcopy --max-depth 3", "Descend at most three levels"},
+	{"grep", `bcopy --grep "HandleLogin" --context-files`, "Files mentioning a symbol plus their directory siblings"},
+	{"changed-within", "bcopy --changed-within 2d", "Files modified in the last two days"},
+	{"changed-after", "bcopy --changed-after 2024-05-01 --git-dates", "Files committed since a date"},
+	{"owner", "bcopy --owner @team-payments", "Files owned by a team in CODEOWNERS"},
+	{"anonymize", "bcopy --anonymize --anonymize-replace AcmeCorp=Company", "Rewrite identifying strings before sharing"},
+	{"pii-check", "bcopy --pii-check --fail-on-pii", "Abort when personal data is found"},
+	{"license-check", "bcopy --fail-on-license GPL", "Abort when GPL-licensed files are selected"},
+	{"threshold", "bcopy --threshold 5", "Ask before copying more than 5 MB"},
+	{"hard-max", "bcopy --hard-max 100", "Abort above 100 MB"},
+	{"model", "bcopy --model gpt-4o --hard-max 90%", "Abort above 90% of the model's context window"},
+	{"max-files", "bcopy --max-files 500", "Abort when more than 500 files are selected"},
+	{"low-memory", "bcopy --low-memory -o ctx.md", "Stream a huge selection to a file"},
+}
+
+// formatExamples renders examples for cobra's Example field, grouped under
+// the flag they demonstrate
+func formatExamples(examples []example) string {
+	var sb strings.Builder
+	lastFlag := "\x00"
+
+	for _, ex := range examples {
+		if ex.Flag != lastFlag {
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			if ex.Flag == "" {
+				sb.WriteString("  # Basic usage\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("  # --%s\n", ex.Flag))
+			}
+			lastFlag = ex.Flag
+		}
+		sb.WriteString(fmt.Sprintf("  %-50s # %s\n", ex.Command, ex.Description))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/nodelike/bcopy/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var graphDOT bool
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [path]",
+	Short: "Print the dependency graph between the packages of a project",
+	Long: `Detect the packages of a project and print which of them depend on each
+other. Packages are the members of a go.work, pnpm/yarn/npm, or Cargo
+workspace, or the packages of a single Go module. Dependencies come from Go
+imports, package.json dependencies, and Cargo.toml dependencies; external
+dependencies are left out.
+
+Use --dot for Graphviz output, e.g. bcopy graph --dot | dot -Tsvg > deps.svg`,
+	Example: `  bcopy graph
+  bcopy graph --dot | dot -Tpng > deps.png`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runGraph,
+}
+
+func init() {
+	graphCmd.Flags().BoolVar(&graphDOT, "dot", false, "Print the graph in Graphviz DOT format")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	if err := analyzer.ValidatePath(root); err != nil {
+		return err
+	}
+	absRoot, err := analyzer.ResolveRoot(root)
+	if err != nil {
+		return err
+	}
+	if err := analyzer.ValidatePath(absRoot); err != nil {
+		return err
+	}
+
+	g, err := workspace.BuildGraph(absRoot)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return fmt.Errorf("no packages found in %s (expected go.work, go.mod, pnpm-workspace.yaml, package.json workspaces, or a Cargo [workspace])", root)
+	}
+
+	if graphDOT {
+		g.WriteDOT(cmd.OutOrStdout())
+	} else {
+		g.WriteText(cmd.OutOrStdout())
+	}
+	return nil
+}
+
+// resolvePackage finds the packages of root named name and returns their
+// directories, plus those of their internal dependencies when withDeps is
+// set. It exits if no package graph is found or nothing matches.
+func resolvePackage(root, name string, withDeps bool) []string {
+	g, err := workspace.BuildGraph(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: building package graph: %v\n", err)
+		os.Exit(1)
+	}
+	if g == nil {
+		fmt.Fprintf(os.Stderr, "Error: --package needs a workspace root or a Go module (go.work, go.mod, pnpm-workspace.yaml, package.json workspaces, or a Cargo [workspace])\n")
+		os.Exit(1)
+	}
+
+	packages := g.Find(name)
+	if len(packages) == 0 {
+		names := make([]string, len(g.Packages))
+		for i, p := range g.Packages {
+			names[i] = p.Name
+		}
+		fmt.Fprintf(os.Stderr, "Error: no package named %q (packages: %s)\n", name, strings.Join(names, ", "))
+		os.Exit(1)
+	}
+
+	dirs := make([]string, len(packages))
+	for i, p := range packages {
+		dirs[i] = p.Dir
+		ui.Status("package", p.Name, p.Kind, p.Dir)
+	}
+	if !withDeps {
+		return dirs
+	}
+
+	closure := g.Closure(dirs)
+	for _, dir := range closure {
+		if !slices.Contains(dirs, dir) {
+			ui.Status("dependency", dir)
+		}
+	}
+	return closure
+}
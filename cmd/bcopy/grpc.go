@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"syscall"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/rpc"
+	"github.com/nodelike/bcopy/internal/ui"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcChunkSize bounds the payload bytes sent in one Stream message
+const grpcChunkSize = 1 << 20
+
+// grpcInfo is written to grpc.json for clients to discover the server
+type grpcInfo struct {
+	Addr  string `json:"addr"`
+	Token string `json:"token"`
+	PID   int    `json:"pid"`
+}
+
+// runGRPCServer runs the Collector service on listener until interrupted
+func runGRPCServer(listener net.Listener, token string) error {
+	addr := listener.Addr().String()
+	infoPath, err := writeGRPCInfo(grpcInfo{Addr: addr, Token: token, PID: os.Getpid()})
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to write grpc.json: %w", err)
+	}
+	defer os.Remove(infoPath)
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := checkToken(ctx, token); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkToken(ss.Context(), token); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	rpc.RegisterCollectorServer(server, collectorServer{})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	ui.Status("serve.grpc", addr)
+	fmt.Fprintf(os.Stderr, "   Connection details for clients: %s\n", infoPath)
+	if err := server.Serve(listener); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "gRPC server stopped")
+	return nil
+}
+
+// checkToken compares the bearer token in the request metadata in
+// constant time
+func checkToken(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	got := md.Get("authorization")
+	if len(got) != 1 || subtle.ConstantTimeCompare([]byte(got[0]), []byte("Bearer "+token)) != 1 {
+		return status.Error(codes.Unauthenticated, "missing or wrong token")
+	}
+	return nil
+}
+
+// writeGRPCInfo writes grpc.json, readable only by the current user
+func writeGRPCInfo(info grpcInfo) (string, error) {
+	dir, err := history.Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "grpc.json")
+	return path, os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+type collectorServer struct {
+	rpc.UnimplementedCollectorServer
+}
+
+func (collectorServer) Collect(ctx context.Context, req *rpc.CollectRequest) (*rpc.CollectResponse, error) {
+	result, formatOpts, err := collectForRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	payload, err := collector.FormatAsMarkdown(result, formatOpts)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	destination := "serve"
+	if p, ok := peer.FromContext(ctx); ok {
+		destination += ":" + p.Addr.String()
+	}
+	auditResult(req.Root, result, int64(len(payload)), destination)
+	return &rpc.CollectResponse{Payload: payload, Stats: collectStats(result)}, nil
+}
+
+func (collectorServer) Stats(ctx context.Context, req *rpc.CollectRequest) (*rpc.CollectStats, error) {
+	result, _, err := collectForRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	return collectStats(result), nil
+}
+
+func (collectorServer) Stream(req *rpc.CollectRequest, stream rpc.Collector_StreamServer) error {
+	result, formatOpts, err := collectForRequest(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	// Contents stay spilled to disk and go out one chunk at a time, so the
+	// payload is never held whole
+	bw := bufio.NewWriterSize(chunkWriter{stream}, grpcChunkSize)
+	if err := collector.WriteMarkdown(bw, result, formatOpts); err != nil {
+		return streamError(err)
+	}
+	if err := bw.Flush(); err != nil {
+		return streamError(err)
+	}
+	return stream.Send(&rpc.Chunk{Chunk: &rpc.Chunk_Stats{Stats: collectStats(result)}})
+}
+
+// chunkWriter sends what is written to it as data chunks of at most
+// grpcChunkSize bytes
+type chunkWriter struct {
+	stream rpc.Collector_StreamServer
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	for sent := 0; sent < len(p); {
+		end := min(sent+grpcChunkSize, len(p))
+		if err := w.stream.Send(&rpc.Chunk{Chunk: &rpc.Chunk_Data{Data: p[sent:end]}}); err != nil {
+			return sent, err
+		}
+		sent = end
+	}
+	return len(p), nil
+}
+
+// streamError passes gRPC errors from Send through and wraps the rest
+func streamError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// collectForRequest collects the files a request selects. Errors are gRPC
+// statuses. The result spills contents to disk and must be closed.
+func collectForRequest(ctx context.Context, req *rpc.CollectRequest) (*collector.CollectionResult, collector.FormatOptions, error) {
+	var formatOpts collector.FormatOptions
+	// The server has no meaningful working directory to resolve against
+	if !filepath.IsAbs(req.Root) {
+		return nil, formatOpts, status.Error(codes.InvalidArgument, "root must be an absolute path")
+	}
+	if err := analyzer.ValidatePath(req.Root); err != nil {
+		return nil, formatOpts, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.MaxDepth < 0 {
+		return nil, formatOpts, status.Error(codes.InvalidArgument, "max_depth can't be negative")
+	}
+	var grepRe *regexp.Regexp
+	if req.Grep != "" {
+		var err error
+		if grepRe, err = regexp.Compile(req.Grep); err != nil {
+			return nil, formatOpts, status.Errorf(codes.InvalidArgument, "invalid grep pattern: %v", err)
+		}
+	}
+
+	filter := analyzer.NewFilter(req.Ext, alwaysExcludes(), req.Exclude, !req.NoGitignore, req.ExcludeTests)
+	filter.Ban(orgPolicy.Banned())
+	if repoRoot, err := analyzer.GetRepoRoot(req.Root); err == nil && !req.NoGitignore {
+		filter.LoadGitignore(repoRoot)
+	}
+
+	slog.Info("grpc collect", "root", req.Root)
+	var maxTotalSize int64
+	if orgPolicy != nil && orgPolicy.MaxPayloadMB > 0 {
+		maxTotalSize = int64(orgPolicy.MaxPayloadMB * 1024 * 1024)
+	}
+	result, err := collector.Collect(ctx, req.Root, filter, collector.Options{
+		MaxDepth:      int(req.MaxDepth),
+		MaxFileSizeMB: 10,
+		MaxTotalSize:  maxTotalSize,
+		Grep:          grepRe,
+		Transforms:    policyTransforms(),
+		LowMemory:     true,
+	})
+	switch {
+	case errors.Is(err, collector.ErrCanceled):
+		return nil, formatOpts, status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, collector.ErrTooLarge):
+		return nil, formatOpts, status.Errorf(codes.PermissionDenied, "selection exceeds the %.2f MB allowed by the policy: %v", orgPolicy.MaxPayloadMB, err)
+	case err != nil:
+		return nil, formatOpts, status.Error(codes.Internal, err.Error())
+	}
+
+	formatOpts.TOC = req.Toc
+	formatOpts.Delimited = req.Delimited
+	return result, formatOpts, nil
+}
+
+// collectStats summarizes a result for the Stats message
+func collectStats(result *collector.CollectionResult) *rpc.CollectStats {
+	stats := &rpc.CollectStats{
+		Files:   int32(result.FileCount),
+		Size:    result.TotalSize,
+		Tokens:  collector.EstimateTokens(result.TotalSize),
+		Skipped: result.PermissionDenied,
+	}
+	for _, readErr := range result.ReadErrors {
+		stats.Skipped = append(stats.Skipped, readErr.RelPath)
+	}
+	for _, file := range result.Files {
+		stats.Selected = append(stats.Selected, &rpc.File{
+			Path:     filepath.ToSlash(file.RelPath),
+			Language: file.Language,
+			Size:     file.Size,
+		})
+	}
+	return stats
+}
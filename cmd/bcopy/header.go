@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// machineFlags name local file locations that don't affect the payload's
+// content; --reproducible leaves them out of the front matter
+var machineFlags = map[string]bool{
+	"output":            true,
+	"export-dir":        true,
+	"per-dir-output":    true,
+	"config":            true,
+	"log-file":          true,
+	"log-level":         true,
+	"log-json":          true,
+	"lang":              true,
+	"plain-messages":    true,
+	"accessible":        true,
+	"dry-run":           true,
+	"verify":            true,
+	"primary":           true,
+	"primary-only":      true,
+	"clipboard-timeout": true,
+	"suggest-ignores":   true,
+	"yes":               true,
+	"no":                true,
+}
+
+// frontMatter builds the YAML front-matter block written by --header,
+// recording where the payload came from and how it was produced
+func frontMatter(cmd *cobra.Command, root string, result *collector.CollectionResult) string {
+	repo := root
+	if absRoot, err := filepath.Abs(root); err == nil {
+		repo = absRoot
+	}
+	var branch, commit string
+	if repoRoot, err := analyzer.GetRepoRoot(root); err == nil {
+		repo = repoRoot
+		branch, commit, _ = analyzer.Head(repoRoot)
+	}
+
+	var flags []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if reproducible && machineFlags[f.Name] {
+			return
+		}
+		if f.Value.Type() == "bool" {
+			flags = append(flags, fmt.Sprintf("%q", "--"+f.Name))
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				flags = append(flags, fmt.Sprintf("%q", "--"+f.Name+"="+v))
+			}
+			return
+		}
+		flags = append(flags, fmt.Sprintf("%q", "--"+f.Name+"="+f.Value.String()))
+	})
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	if !reproducible {
+		fmt.Fprintf(&sb, "generated_at: %s\n", time.Now().UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(&sb, "repo: %q\n", filepath.Base(repo))
+	if branch != "" {
+		fmt.Fprintf(&sb, "branch: %q\n", branch)
+	}
+	if commit != "" {
+		fmt.Fprintf(&sb, "commit: %s\n", commit)
+	}
+	fmt.Fprintf(&sb, "files: %d\n", result.FileCount)
+	fmt.Fprintf(&sb, "size_bytes: %d\n", result.TotalSize)
+	fmt.Fprintf(&sb, "tokens_estimate: %d\n", collector.EstimateTokens(result.TotalSize))
+	fmt.Fprintf(&sb, "bcopy_version: %q\n", version)
+	fmt.Fprintf(&sb, "flags: [%s]\n", strings.Join(flags, ", "))
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
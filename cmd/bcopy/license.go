@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/license"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+// thirdPartyDirs are directory names that hold code vendored from
+// elsewhere
+var thirdPartyDirs = map[string]bool{
+	"vendor": true, "node_modules": true, "third_party": true, "third-party": true, "external": true,
+}
+
+// licenseFinding is a selected file under a license other than the
+// project's own
+type licenseFinding struct {
+	path       string
+	license    string
+	source     string // "header", or the license file governing the file
+	thirdParty bool
+}
+
+// governingLicense is the nearest license file above a directory
+type governingLicense struct {
+	file, id string
+}
+
+// reportLicenses prints the selected files that declare a license in their
+// header or sit below a license file other than the project's own, and
+// exits when one matches --fail-on-license
+func reportLicenses(result *collector.CollectionResult, root string) {
+	const maxShown = 20
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return
+	}
+	// The license at the top of the repository is the project's own
+	top := absRoot
+	if repoRoot, err := analyzer.GetRepoRoot(absRoot); err == nil {
+		top = repoRoot
+	}
+
+	cache := make(map[string]governingLicense)
+	var findings []licenseFinding
+	for _, file := range result.Files {
+		finding := licenseFinding{path: file.RelPath, thirdParty: isThirdParty(file.RelPath)}
+		if content, err := result.ReadContent(file); err == nil {
+			finding.license = license.FromHeader(content)
+			finding.source = "header"
+		}
+		if finding.license == "" {
+			dir := filepath.Dir(filepath.Join(absRoot, file.RelPath))
+			governing := findLicenseFile(dir, top, cache)
+			if governing.file == "" {
+				continue
+			}
+			finding.license, finding.source = governing.id, governing.file
+			if finding.license == "" {
+				finding.license = "unrecognized"
+			}
+		}
+		findings = append(findings, finding)
+	}
+	if len(findings) == 0 {
+		return
+	}
+
+	var blocked []string
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		for _, prefix := range failOnLicense {
+			if license.Matches(f.license, prefix) && !seen[f.license] {
+				seen[f.license] = true
+				blocked = append(blocked, f.license)
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr)
+	ui.Status("warn.license", len(findings))
+	for i, f := range findings {
+		if i == maxShown {
+			fmt.Fprintf(os.Stderr, "   ...and %d more\n", len(findings)-maxShown)
+			break
+		}
+		source := f.source
+		if f.thirdParty {
+			source += ", third-party"
+		}
+		fmt.Fprintf(os.Stderr, "   ./%s  %s  (%s)\n", f.path, f.license, source)
+	}
+
+	if len(blocked) > 0 {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("abort.license", strings.Join(blocked, ", "))
+		fmt.Fprintln(os.Stderr, "Exclude the files listed above to copy the rest.")
+		os.Exit(1)
+	}
+}
+
+// findLicenseFile returns the nearest license file in dir or its parents
+// below top. Directories are cached, so each is read once per run.
+func findLicenseFile(dir, top string, cache map[string]governingLicense) governingLicense {
+	if dir == top || !strings.HasPrefix(dir, top+string(filepath.Separator)) {
+		return governingLicense{}
+	}
+	if governing, ok := cache[dir]; ok {
+		return governing
+	}
+
+	var governing governingLicense
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.IsDir() || !license.IsLicenseFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		rel, err := filepath.Rel(top, path)
+		if err != nil {
+			continue
+		}
+		data, _ := os.ReadFile(path)
+		governing = governingLicense{file: filepath.ToSlash(rel), id: license.Identify(string(data))}
+		break
+	}
+	if governing.file == "" {
+		governing = findLicenseFile(filepath.Dir(dir), top, cache)
+	}
+	cache[dir] = governing
+	return governing
+}
+
+// isThirdParty reports whether a path lies in a vendored directory
+func isThirdParty(relPath string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if thirdPartyDirs[part] {
+			return true
+		}
+	}
+	return false
+}
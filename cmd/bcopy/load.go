@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/nodelike/bcopy/internal/audit"
+	"github.com/nodelike/bcopy/internal/clipboard"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/slots"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadList   bool
+	loadPrint  bool
+	loadDelete bool
+)
+
+var loadCmd = &cobra.Command{
+	Use:   "load <slot>",
+	Short: "Copy a payload saved with --slot back to the clipboard",
+	Long: `Copy a payload saved with bcopy --slot <name> back to the clipboard, so several
+prepared contexts can be kept and switched between.
+
+Slots are stored in the bcopy cache directory.`,
+	Example: `  bcopy --slot api ./api && bcopy --slot web ./web
+  bcopy load api
+  bcopy load --list`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if loadList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	SilenceUsage: true,
+	RunE:         runLoad,
+}
+
+func init() {
+	loadCmd.Flags().BoolVarP(&loadList, "list", "l", false, "List saved slots")
+	loadCmd.Flags().BoolVar(&loadPrint, "print", false, "Print the slot to stdout instead of copying it")
+	loadCmd.Flags().BoolVar(&loadDelete, "delete", false, "Delete the slot")
+	rootCmd.AddCommand(loadCmd)
+}
+
+func runLoad(cmd *cobra.Command, args []string) error {
+	if loadList {
+		list, err := slots.List()
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Fprintln(os.Stderr, "No slots saved yet; use bcopy --slot <name>")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SLOT\tSIZE\tSAVED")
+		for _, slot := range list {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", slot.Name, collector.FormatSize(slot.Size), slot.ModTime.Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+	}
+
+	name := args[0]
+	if loadDelete {
+		if err := slots.Delete(name); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Deleted slot %s\n", name)
+		return nil
+	}
+
+	target := "clipboard"
+	if loadPrint {
+		target = "stdout"
+	}
+	if err := orgPolicy.Check(target); err != nil {
+		return err
+	}
+
+	payload, err := slots.Load(name)
+	if err != nil {
+		return err
+	}
+	rec := audit.Record{
+		Root:         "slot:" + name,
+		Bytes:        int64(len(payload)),
+		Tokens:       collector.EstimateTokens(int64(len(payload))),
+		Destinations: []string{target},
+	}
+	if loadPrint {
+		fmt.Fprint(cmd.OutOrStdout(), payload)
+		auditPayload(rec)
+		return nil
+	}
+
+	ui.Begin("slot.copying", name, collector.FormatSize(int64(len(payload))))
+	if _, err := clipboard.Copy(context.Background(), payload, clipboard.Clipboard); err != nil {
+		fmt.Fprintln(os.Stderr)
+		return fmt.Errorf("copying to clipboard: %w", err)
+	}
+	ui.Complete("")
+	auditPayload(rec)
+	ui.Status("copied")
+	return nil
+}
@@ -1,34 +1,154 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/nodelike/bcopy/internal/analyzer"
-	"github.com/nodelike/bcopy/internal/clipboard"
 	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/compress"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/limit"
+	"github.com/nodelike/bcopy/internal/logging"
+	"github.com/nodelike/bcopy/internal/manifest"
+	"github.com/nodelike/bcopy/internal/pii"
+	"github.com/nodelike/bcopy/internal/slots"
+	"github.com/nodelike/bcopy/internal/todos"
+	"github.com/nodelike/bcopy/internal/transform"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/nodelike/bcopy/internal/workspace"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	cfgFile        string
+	logLevel       string
+	logJSON        bool
+	logFile        string
+	lang           string
+	plainMessages  bool
+	accessible     bool
 	noGitignore    bool
 	excludeTests   bool
+	ignoreCase     bool
 	customExcludes []string
 	allowedExts    []string
 	path           string
 	maxDepth       int
-	thresholdMB    float64
-	hardMaxMB      float64
+	maxDepthFor    []string
+	oneFileSystem  bool
+	threshold      string
+	hardMax        string
+	model          string
 	maxFileSizeMB  float64
 	dryRun         bool
+	diffOutput     bool
+	toStdout       bool
+	toClipboard    bool
+	clipTimeout    time.Duration
+	verifyCopy     bool
+	primary        bool
+	primaryOnly    bool
+	slotName       string
 	outputFile     string
+	grepPattern    string
+	around         string
+	aroundLines    int
+	contextFiles   bool
+	changedWithin  string
+	changedAfter   string
+	gitDates       bool
+	owner          string
+	noAttributes   bool
+	noEditorConfig bool
+	toc            bool
+	fenceLangAll   string
+	noFences       bool
+	groupByDir     bool
+	aliasPaths     bool
+	fileMetadata   bool
+	stripPrefix    string
+	addPrefix      string
+	compression    string
+	exportDir      string
+	perDirOutput   string
+	perDirTokens   int
+	outputFormat   string
+	anonymize      bool
+	anonReplace    []string
+	anonPaths      bool
+	lowMemory      bool
+	strict         bool
+	onError        string
+	minified       string
+	maxFiles       int
+	piiCheck       bool
+	suggestIgnores bool
+	failOnPII      bool
+	licenseCheck   bool
+	failOnLicense  []string
+	noDefaultExcl  bool
+	assumeYes      bool
+	assumeNo       bool
+	promptTimeout  time.Duration
+	noHistory      bool
+	delta          bool
+	header         bool
+	withTodos      bool
+	envInfo        bool
+	withHistory    int
+	attachFiles    []string
+	runCommand     []string
+	historyPaths   bool
+	withDocs       bool
+	withMeta       bool
+	withFixtures   bool
+	survey         bool
+	surveyTokens   int
+	apiSurface     bool
+	depsSummary    bool
+	locks          string
+	idlMode        bool
+	schemaMode     bool
+	selectionFile  string
+	fromRepoRoot   bool
+	entryPoints    []string
+	ignoreFiles    []string
+	warnFiles      int
+	reproducible   bool
+	normalizeEOL   string
+	retabWidth     int
+	useTabs        bool
+	includeEnv     bool
+	module         string
+	pkgName        string
+	withDeps       bool
+
+	// configErr is the result of reading the config file, logged once the
+	// logger is configured
+	configErr error
+
+	// sizeConfirmed records that the user accepted the threshold prompt
+	sizeConfirmed bool
+
+	// warnLimit and abortLimit are --threshold and --hard-max, parsed
+	warnLimit  limit.Limit
+	abortLimit limit.Limit
 )
 
 var rootCmd = &cobra.Command{
@@ -36,34 +156,216 @@ var rootCmd = &cobra.Command{
 	Short: "Bulk copy codebase files to clipboard",
 	Long: `bcopy is a tool for copying multiple files from your codebase to clipboard,
 with smart filtering and git repository support.`,
-	Version: "1.0.2",
+	Example: formatExamples(rootExamples),
+	Version: version,
 	Args:    cobra.MaximumNArgs(1),
 	Run:     runBcopy,
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initConfig, initLogging, initUI, initPolicy)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .bcopy.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Log level: debug, info, warn, or error")
+	rootCmd.PersistentFlags().BoolVar(&logJSON, "log-json", false, "Write logs as JSON")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "Language of status messages: en, ja, zh, or es (default from LANG)")
+	rootCmd.PersistentFlags().BoolVar(&plainMessages, "plain-messages", false, "Replace emoji in status messages with ASCII tags like [WARN]")
+	rootCmd.PersistentFlags().BoolVar(&accessible, "accessible", false, "Screen-reader friendly output: text prefixes instead of color and emoji, one line per step (also ACCESSIBLE=1)")
 	rootCmd.Flags().BoolVar(&noGitignore, "no-gitignore", false, "Ignore .gitignore patterns (always-excluded patterns still apply)")
 	rootCmd.Flags().BoolVar(&excludeTests, "exclude-tests", false, "Exclude test files (_test.go, test/, tests/, *.test.*, *.spec.*)")
+	rootCmd.Flags().BoolVar(&withFixtures, "with-fixtures", false, "Include test data (testdata/, fixtures/, and golden files referenced by tests), even with --exclude-tests")
+	rootCmd.Flags().BoolVar(&ignoreCase, "ignore-case", false, "Match exclusion patterns, ignore files, and --ext regardless of case")
 	rootCmd.Flags().StringArrayVar(&customExcludes, "exclude", []string{}, "Additional exclusion pattern (can be repeated)")
+	rootCmd.Flags().BoolVar(&noDefaultExcl, "no-default-excludes", false, "Disable the built-in exclusion list (node_modules, dist, build, bin, ...); .git is always excluded")
+	rootCmd.Flags().StringArrayVar(&ignoreFiles, "ignore-file", []string{}, "Also apply an ignore file such as .dockerignore or .npmignore (can be repeated)")
 	rootCmd.Flags().StringArrayVar(&allowedExts, "ext", []string{}, "Override allowed file extensions (can be repeated)")
-	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 0, "Maximum directory traversal depth (0 = unlimited)")
-	rootCmd.Flags().Float64Var(&thresholdMB, "threshold", 1.0, "Size warning threshold in MB")
-	rootCmd.Flags().Float64Var(&hardMaxMB, "hard-max", 50.0, "Hard maximum total size in MB (aborts if exceeded)")
+	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 0, "Maximum depth of selected files (1 = root files only, 0 = unlimited)")
+	rootCmd.Flags().StringArrayVar(&maxDepthFor, "max-depth-for", []string{}, "Depth override for paths matching a glob, e.g. 'docs/**=2' (can be repeated)")
+	rootCmd.Flags().BoolVar(&oneFileSystem, "one-file-system", true, "Don't descend into mount points of other filesystems (network shares, external volumes)")
+	rootCmd.Flags().StringVar(&threshold, "threshold", "1", "Size warning threshold: MB, tokens (128k-tokens), or a percentage of the --model context window (80%)")
+	rootCmd.Flags().StringVar(&hardMax, "hard-max", "50", "Hard maximum total size, aborts if exceeded: MB, tokens (128k-tokens), or a percentage of the --model context window (100%)")
+	rootCmd.Flags().StringVar(&model, "model", "", "Model whose context window percentage limits refer to (e.g. gpt-4o, claude-sonnet-4, gemini-2.5-pro)")
 	rootCmd.Flags().Float64Var(&maxFileSizeMB, "max-file-size", 10.0, "Maximum individual file size in MB")
-	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print output to stdout instead of copying to clipboard")
+	rootCmd.Flags().IntVar(&maxFiles, "max-files", 0, "Maximum number of files (aborts before reading if exceeded, 0 = unlimited)")
+	rootCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to all prompts (for scripts and CI)")
+	rootCmd.Flags().BoolVar(&assumeNo, "no", false, "Answer no to all prompts (for scripts and CI)")
+	rootCmd.Flags().DurationVar(&promptTimeout, "prompt-timeout", 0, "Abort if a prompt gets no answer within this time (e.g. 30s, 0 = wait forever)")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the output to stdout; write no files, slots, or clipboard")
+	rootCmd.Flags().BoolVar(&diffOutput, "diff-output", false, "Show a unified diff between the --output file and what would be written, without writing")
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write output to file instead of clipboard")
+	rootCmd.Flags().BoolVar(&toStdout, "stdout", false, "Print output to stdout; combine with --output and --clipboard to write several destinations in one pass")
+	rootCmd.Flags().StringVar(&slotName, "slot", "", "Save the payload in a named local slot instead of the clipboard (restore with bcopy load)")
+	rootCmd.Flags().BoolVar(&toClipboard, "clipboard", false, "Copy to the clipboard even when --output, --stdout, --slot, --export-dir, or --per-dir-output is given")
+	rootCmd.Flags().BoolVar(&primary, "primary", false, "Also copy to the X11/Wayland primary selection, for middle-click paste")
+	rootCmd.Flags().BoolVar(&primaryOnly, "primary-only", false, "Copy to the primary selection instead of the clipboard")
+	rootCmd.Flags().BoolVar(&verifyCopy, "verify", false, "Read the clipboard back after copying and warn if its content differs from the payload")
+	rootCmd.Flags().DurationVar(&clipTimeout, "clipboard-timeout", 30*time.Second, "Give up copying to the clipboard after this long (0 = wait indefinitely)")
+	rootCmd.Flags().BoolVar(&anonymize, "anonymize", false, "Rewrite emails, internal hostnames, and configured strings before output")
+	rootCmd.Flags().StringArrayVar(&anonReplace, "anonymize-replace", []string{}, "With --anonymize, replace a literal string (old=new, can be repeated)")
+	rootCmd.Flags().BoolVar(&anonPaths, "anonymize-paths", false, "With --anonymize, replace directory names with short hashes")
+	rootCmd.Flags().BoolVar(&suggestIgnores, "suggest-ignores", false, "After the run, suggest exclusion patterns for large or skipped paths")
+	rootCmd.Flags().BoolVar(&piiCheck, "pii-check", false, "Warn about likely personal data (emails, phone numbers, national IDs) before output")
+	rootCmd.Flags().BoolVar(&failOnPII, "fail-on-pii", false, "Abort when --pii-check finds likely personal data (implies --pii-check)")
+	rootCmd.Flags().BoolVar(&licenseCheck, "license-check", false, "Warn about selected files under their own or third-party licenses before output")
+	rootCmd.Flags().StringSliceVar(&failOnLicense, "fail-on-license", []string{}, "Abort when a selected file's license starts with this SPDX identifier, e.g. GPL or AGPL (implies --license-check, can be repeated)")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "", "Output format: markdown, bcopy (checksummed delimiters for bcopy paste), json-string (the payload as one JSON string), jsonl (one JSON object per file), or zip (default: detected from --output extension)")
+	rootCmd.Flags().StringVar(&exportDir, "export-dir", "", "Write each collected file into this directory, mirroring the tree")
+	rootCmd.Flags().StringVar(&perDirOutput, "per-dir-output", "", "Write one payload per top-level directory into this directory (api.md, web.md, ...)")
+	rootCmd.Flags().IntVar(&perDirTokens, "per-dir-tokens", 100000, "Estimated token budget of each --per-dir-output payload; larger directories are split into numbered parts (0 = no limit)")
+	rootCmd.Flags().IntVar(&warnFiles, "warn-files", 20000, "Pre-scan the directory and prompt if it holds more files than this (0 = off)")
+	rootCmd.Flags().StringVar(&compression, "compress", "", "Compress --output with gzip or zstd")
+	rootCmd.Flags().BoolVar(&toc, "toc", false, "Prepend a table of contents with per-file anchors and sizes")
+	rootCmd.Flags().StringVar(&fenceLangAll, "fence-lang-all", "", "Use this language on every code fence instead of the detected one (e.g. text)")
+	rootCmd.Flags().BoolVar(&noFences, "no-fences", false, "Write file contents without code fences")
+	rootCmd.Flags().BoolVar(&groupByDir, "group-by-dir", false, "Sort files by directory and write a \"## directory/\" heading before each group")
+	rootCmd.Flags().BoolVar(&aliasPaths, "alias-paths", false, "Abbreviate long directories repeated in file headers to aliases such as @1, defined in a legend at the top")
+	rootCmd.Flags().BoolVar(&fileMetadata, "metadata", false, "Annotate file headers with each file's charset and line endings, from .editorconfig or detected")
+	rootCmd.Flags().StringVar(&stripPrefix, "strip-prefix", "", "Remove this directory from the start of emitted file paths (e.g. services/payments)")
+	rootCmd.Flags().StringVar(&addPrefix, "add-prefix", "", "Prepend this directory to every emitted file path (e.g. pay/)")
+	rootCmd.Flags().IntVar(&retabWidth, "retab", 0, "Convert leading tabs to this many spaces (0 = off)")
+	rootCmd.Flags().BoolVar(&useTabs, "use-tabs", false, "With --retab, convert leading spaces to tabs instead")
+	rootCmd.Flags().StringVar(&normalizeEOL, "normalize-eol", "keep", "Rewrite line endings in file contents: lf, crlf, editorconfig (each file's end_of_line), or keep")
+	rootCmd.Flags().BoolVar(&reproducible, "reproducible", false, "Byte-identical output for the same commit: no timestamps or machine paths, LF line endings")
+	rootCmd.Flags().BoolVar(&header, "header", false, "Prepend a YAML front-matter block with repo, commit, counts, version, and flags used")
+	rootCmd.Flags().BoolVar(&withTodos, "with-todos", false, "Append a list of TODO, FIXME, HACK, and XXX comments with their git blame authors")
+	rootCmd.Flags().BoolVar(&envInfo, "env-info", false, "Append the OS, runtime versions, and direct dependencies read from the project's manifests")
+	rootCmd.Flags().IntVar(&withHistory, "with-history", 0, "Append the last N commit messages (subject and body)")
+	rootCmd.Flags().StringArrayVar(&runCommand, "run", []string{}, "Run a shell command and append its output (stdout and stderr) after the collected files (can be repeated)")
+	rootCmd.Flags().StringArrayVar(&attachFiles, "attach", []string{}, "Append a file, or - for stdin, after the collected files; prefix with title= to name it (can be repeated)")
+	rootCmd.Flags().BoolVar(&historyPaths, "history-paths", false, "With --with-history, only count commits touching the selected files")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "Fail if any file or directory could not be read (same as --on-error fail)")
+	rootCmd.Flags().StringVar(&onError, "on-error", "warn", "What to do with unreadable or vanished paths: skip, warn, or fail")
+	rootCmd.Flags().StringVar(&minified, "minified", "skip", "What to do with files that look minified (very long lines): skip, truncate (keep the start with a note), or keep")
+	rootCmd.Flags().BoolVar(&delta, "delta", false, "Only emit files changed since the last recorded run of this directory")
+	rootCmd.Flags().BoolVar(&noHistory, "no-history", false, "Don't record this run in the local history used by diff-runs")
+	rootCmd.Flags().BoolVar(&lowMemory, "low-memory", false, "Spill file contents to a temp file and stream output, keeping memory flat on huge selections")
+	rootCmd.Flags().StringVar(&grepPattern, "grep", "", "Only include files whose content matches this regex")
+	rootCmd.Flags().StringVar(&around, "around", "", "Only include the lines around each occurrence of this symbol, per file")
+	rootCmd.Flags().IntVar(&aroundLines, "around-lines", collector.DefaultAroundLines, "Lines of context kept before and after each --around occurrence")
+	rootCmd.Flags().StringVar(&module, "module", "", "Only collect this workspace member (go.work, pnpm/yarn/npm, or Cargo) plus root files")
+	rootCmd.Flags().StringVar(&pkgName, "package", "", "Only collect this package (workspace member or Go package directory) plus root files")
+	rootCmd.Flags().BoolVar(&withDeps, "with-deps", false, "With --package, also collect the packages it depends on inside the project")
+	rootCmd.Flags().StringArrayVar(&entryPoints, "entry", []string{}, "Mark a file as an entry point: always included and placed first (can be repeated)")
+	rootCmd.Flags().BoolVar(&includeEnv, "include-env", false, "Include .env files, with every value masked as ***")
+	rootCmd.Flags().BoolVar(&idlMode, "idl", false, "Only collect API contracts (.proto, .graphql, .thrift, Avro, OpenAPI) plus everything they import")
+	rootCmd.Flags().BoolVar(&schemaMode, "schema", false, "Only collect .sql files, squashing goose/golang-migrate/Flyway migrations into the current schema")
+	rootCmd.Flags().BoolVar(&fromRepoRoot, "repo-root", false, "Collect from the root of the git repository containing the path (or the current directory), like git subcommands do")
+	rootCmd.Flags().StringVar(&selectionFile, "selection", "", "Copy exactly the files and line ranges listed in an editor selection file (- for stdin)")
+	rootCmd.Flags().BoolVar(&withDocs, "with-docs", false, "Always include READMEs, CONTRIBUTING, ARCHITECTURE.md, and docs/**.md, placed first")
+	rootCmd.Flags().BoolVar(&withMeta, "with-meta", false, "Always include .github/ templates and workflows, LICENSE, CODE_OF_CONDUCT, SECURITY, and similar repo files")
+	rootCmd.Flags().BoolVar(&survey, "survey", false, "Collect a representative sample of an unfamiliar repo: manifests, configs, entry points, and one file per directory")
+	rootCmd.Flags().IntVar(&surveyTokens, "survey-tokens", collector.DefaultSurveyTokens, "Estimated token budget of --survey")
+	rootCmd.Flags().BoolVar(&apiSurface, "api-surface", false, "Reduce Go files to exported declarations and their doc comments, without function bodies (implies --ext .go --exclude-tests)")
+	rootCmd.Flags().BoolVar(&depsSummary, "deps-summary", false, "Replace go.mod, package.json, requirements.txt, pyproject.toml, and Cargo.toml with their direct dependencies and versions")
+	rootCmd.Flags().StringVar(&locks, "locks", "exclude", "What to do with package-lock.json, go.sum, and Cargo.lock: exclude, or summary (the locked versions of direct dependencies)")
+	rootCmd.Flags().BoolVar(&contextFiles, "context-files", false, "With --grep, also include files in the same directory as a match")
+	rootCmd.Flags().StringVar(&changedWithin, "changed-within", "", "Only include files modified within this window (e.g. 2d, 1w, 12h)")
+	rootCmd.Flags().StringVar(&changedAfter, "changed-after", "", "Only include files modified after this date (YYYY-MM-DD)")
+	rootCmd.Flags().BoolVar(&gitDates, "git-dates", false, "Use last git commit dates instead of file mtimes for --changed-*")
+	rootCmd.Flags().BoolVar(&noAttributes, "no-gitattributes", false, "Ignore linguist-generated/vendored/language overrides in .gitattributes")
+	rootCmd.Flags().BoolVar(&noEditorConfig, "no-editorconfig", false, "Ignore charset and end_of_line in .editorconfig and rely on detection alone")
+	rootCmd.Flags().StringVar(&owner, "owner", "", "Only include files owned by this user or team in CODEOWNERS (e.g. @team-payments)")
 
+	viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
+	viper.BindPFlag("log-json", rootCmd.PersistentFlags().Lookup("log-json"))
+	viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
+	viper.BindPFlag("lang", rootCmd.PersistentFlags().Lookup("lang"))
+	viper.BindPFlag("plain-messages", rootCmd.PersistentFlags().Lookup("plain-messages"))
+	viper.BindPFlag("accessible", rootCmd.PersistentFlags().Lookup("accessible"))
 	viper.BindPFlag("no-gitignore", rootCmd.Flags().Lookup("no-gitignore"))
 	viper.BindPFlag("exclude-tests", rootCmd.Flags().Lookup("exclude-tests"))
+	viper.BindPFlag("with-fixtures", rootCmd.Flags().Lookup("with-fixtures"))
+	viper.BindPFlag("ignore-case", rootCmd.Flags().Lookup("ignore-case"))
+	viper.BindPFlag("no-gitattributes", rootCmd.Flags().Lookup("no-gitattributes"))
+	viper.BindPFlag("no-editorconfig", rootCmd.Flags().Lookup("no-editorconfig"))
 	viper.BindPFlag("exclude", rootCmd.Flags().Lookup("exclude"))
+	viper.BindPFlag("no-default-excludes", rootCmd.Flags().Lookup("no-default-excludes"))
 	viper.BindPFlag("ext", rootCmd.Flags().Lookup("ext"))
+	viper.BindPFlag("ignore-file", rootCmd.Flags().Lookup("ignore-file"))
 	viper.BindPFlag("max-depth", rootCmd.Flags().Lookup("max-depth"))
+	viper.BindPFlag("max-depth-for", rootCmd.Flags().Lookup("max-depth-for"))
+	viper.BindPFlag("one-file-system", rootCmd.Flags().Lookup("one-file-system"))
 	viper.BindPFlag("threshold", rootCmd.Flags().Lookup("threshold"))
 	viper.BindPFlag("hard-max", rootCmd.Flags().Lookup("hard-max"))
+	viper.BindPFlag("model", rootCmd.Flags().Lookup("model"))
 	viper.BindPFlag("max-file-size", rootCmd.Flags().Lookup("max-file-size"))
+	viper.BindPFlag("max-files", rootCmd.Flags().Lookup("max-files"))
+	viper.BindPFlag("warn-files", rootCmd.Flags().Lookup("warn-files"))
+	viper.BindPFlag("toc", rootCmd.Flags().Lookup("toc"))
+	viper.BindPFlag("fence-lang-all", rootCmd.Flags().Lookup("fence-lang-all"))
+	viper.BindPFlag("no-fences", rootCmd.Flags().Lookup("no-fences"))
+	viper.BindPFlag("group-by-dir", rootCmd.Flags().Lookup("group-by-dir"))
+	viper.BindPFlag("alias-paths", rootCmd.Flags().Lookup("alias-paths"))
+	viper.BindPFlag("metadata", rootCmd.Flags().Lookup("metadata"))
+	viper.BindPFlag("strip-prefix", rootCmd.Flags().Lookup("strip-prefix"))
+	viper.BindPFlag("add-prefix", rootCmd.Flags().Lookup("add-prefix"))
+	viper.BindPFlag("header", rootCmd.Flags().Lookup("header"))
+	viper.BindPFlag("with-todos", rootCmd.Flags().Lookup("with-todos"))
+	viper.BindPFlag("env-info", rootCmd.Flags().Lookup("env-info"))
+	viper.BindPFlag("with-history", rootCmd.Flags().Lookup("with-history"))
+	viper.BindPFlag("history-paths", rootCmd.Flags().Lookup("history-paths"))
+	viper.BindPFlag("reproducible", rootCmd.Flags().Lookup("reproducible"))
+	viper.BindPFlag("normalize-eol", rootCmd.Flags().Lookup("normalize-eol"))
+	viper.BindPFlag("retab", rootCmd.Flags().Lookup("retab"))
+	viper.BindPFlag("use-tabs", rootCmd.Flags().Lookup("use-tabs"))
+	viper.BindPFlag("with-docs", rootCmd.Flags().Lookup("with-docs"))
+	viper.BindPFlag("with-meta", rootCmd.Flags().Lookup("with-meta"))
+	viper.BindPFlag("survey-tokens", rootCmd.Flags().Lookup("survey-tokens"))
+	viper.BindPFlag("per-dir-tokens", rootCmd.Flags().Lookup("per-dir-tokens"))
+	viper.BindPFlag("api-surface", rootCmd.Flags().Lookup("api-surface"))
+	viper.BindPFlag("deps-summary", rootCmd.Flags().Lookup("deps-summary"))
+	viper.BindPFlag("locks", rootCmd.Flags().Lookup("locks"))
+	viper.BindPFlag("around-lines", rootCmd.Flags().Lookup("around-lines"))
+	viper.BindPFlag("idl", rootCmd.Flags().Lookup("idl"))
+	viper.BindPFlag("schema", rootCmd.Flags().Lookup("schema"))
+	viper.BindPFlag("include-env", rootCmd.Flags().Lookup("include-env"))
+	viper.BindPFlag("prompt-timeout", rootCmd.Flags().Lookup("prompt-timeout"))
+	viper.BindPFlag("strict", rootCmd.Flags().Lookup("strict"))
+	viper.BindPFlag("repo-root", rootCmd.Flags().Lookup("repo-root"))
+	viper.BindPFlag("on-error", rootCmd.Flags().Lookup("on-error"))
+	viper.BindPFlag("minified", rootCmd.Flags().Lookup("minified"))
+	viper.BindPFlag("no-history", rootCmd.Flags().Lookup("no-history"))
+	viper.BindPFlag("anonymize-replace", rootCmd.Flags().Lookup("anonymize-replace"))
+	viper.BindPFlag("anonymize-paths", rootCmd.Flags().Lookup("anonymize-paths"))
+	viper.BindPFlag("pii-check", rootCmd.Flags().Lookup("pii-check"))
+	viper.BindPFlag("suggest-ignores", rootCmd.Flags().Lookup("suggest-ignores"))
+	viper.BindPFlag("clipboard-timeout", rootCmd.Flags().Lookup("clipboard-timeout"))
+	viper.BindPFlag("verify", rootCmd.Flags().Lookup("verify"))
+	viper.BindPFlag("primary", rootCmd.Flags().Lookup("primary"))
+	viper.BindPFlag("fail-on-pii", rootCmd.Flags().Lookup("fail-on-pii"))
+	viper.BindPFlag("license-check", rootCmd.Flags().Lookup("license-check"))
+	viper.BindPFlag("fail-on-license", rootCmd.Flags().Lookup("fail-on-license"))
+	viper.BindPFlag("yes", rootCmd.Flags().Lookup("yes"))
+	viper.BindPFlag("no", rootCmd.Flags().Lookup("no"))
+	viper.BindPFlag("dry-run", rootCmd.Flags().Lookup("dry-run"))
+	viper.BindPFlag("diff-output", rootCmd.Flags().Lookup("diff-output"))
+	viper.BindPFlag("output", rootCmd.Flags().Lookup("output"))
+	viper.BindPFlag("stdout", rootCmd.Flags().Lookup("stdout"))
+	viper.BindPFlag("slot", rootCmd.Flags().Lookup("slot"))
+	viper.BindPFlag("clipboard", rootCmd.Flags().Lookup("clipboard"))
+	viper.BindPFlag("primary-only", rootCmd.Flags().Lookup("primary-only"))
+	viper.BindPFlag("anonymize", rootCmd.Flags().Lookup("anonymize"))
+	viper.BindPFlag("format", rootCmd.Flags().Lookup("format"))
+	viper.BindPFlag("export-dir", rootCmd.Flags().Lookup("export-dir"))
+	viper.BindPFlag("per-dir-output", rootCmd.Flags().Lookup("per-dir-output"))
+	viper.BindPFlag("compress", rootCmd.Flags().Lookup("compress"))
+	// --run and --attach stay unbound (see commandLineOnly)
+	viper.BindPFlag("delta", rootCmd.Flags().Lookup("delta"))
+	viper.BindPFlag("low-memory", rootCmd.Flags().Lookup("low-memory"))
+	viper.BindPFlag("grep", rootCmd.Flags().Lookup("grep"))
+	viper.BindPFlag("around", rootCmd.Flags().Lookup("around"))
+	viper.BindPFlag("module", rootCmd.Flags().Lookup("module"))
+	viper.BindPFlag("package", rootCmd.Flags().Lookup("package"))
+	viper.BindPFlag("with-deps", rootCmd.Flags().Lookup("with-deps"))
+	viper.BindPFlag("entry", rootCmd.Flags().Lookup("entry"))
+	viper.BindPFlag("selection", rootCmd.Flags().Lookup("selection"))
+	viper.BindPFlag("survey", rootCmd.Flags().Lookup("survey"))
+	viper.BindPFlag("context-files", rootCmd.Flags().Lookup("context-files"))
+	viper.BindPFlag("changed-within", rootCmd.Flags().Lookup("changed-within"))
+	viper.BindPFlag("changed-after", rootCmd.Flags().Lookup("changed-after"))
+	viper.BindPFlag("git-dates", rootCmd.Flags().Lookup("git-dates"))
+	viper.BindPFlag("owner", rootCmd.Flags().Lookup("owner"))
 }
 
 func initConfig() {
@@ -75,10 +377,42 @@ func initConfig() {
 		viper.SetConfigName(".bcopy")
 	}
 
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	configErr = viper.ReadInConfig()
+}
+
+// initUI selects the language and decoration of status messages from
+// --lang/--plain-messages/--accessible or their config equivalents. The
+// ACCESSIBLE environment variable other terminal tools honor also turns on
+// accessible mode.
+func initUI() {
+	accessibleMode := viper.GetBool("accessible") || os.Getenv("ACCESSIBLE") != ""
+	if err := ui.Setup(viper.GetString("lang"), viper.GetBool("plain-messages"), accessibleMode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// initLogging installs the slog logger from --log-level/--log-json/--log-file
+// (or their config and environment equivalents) and reports how the config
+// was resolved
+func initLogging() {
+	if _, err := logging.Setup(viper.GetString("log-level"), viper.GetBool("log-json"), viper.GetString("log-file")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var notFound viper.ConfigFileNotFoundError
+	switch {
+	case configErr == nil:
+		slog.Info("using config file", "path", viper.ConfigFileUsed())
+	case errors.As(configErr, &notFound):
+		slog.Debug("no config file found")
+	default:
+		slog.Warn("failed to read config file", "error", configErr)
 	}
 }
 
@@ -98,31 +432,151 @@ func runBcopy(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if !cmd.Flags().Changed("yes") {
+		assumeYes = viper.GetBool("yes")
+	}
+	if !cmd.Flags().Changed("no") {
+		assumeNo = viper.GetBool("no")
+	}
+	if !cmd.Flags().Changed("dry-run") {
+		dryRun = viper.GetBool("dry-run")
+	}
+	if !cmd.Flags().Changed("diff-output") {
+		diffOutput = viper.GetBool("diff-output")
+	}
+	if !cmd.Flags().Changed("output") {
+		outputFile = viper.GetString("output")
+	}
+	if !cmd.Flags().Changed("stdout") {
+		toStdout = viper.GetBool("stdout")
+	}
+	if !cmd.Flags().Changed("slot") {
+		slotName = viper.GetString("slot")
+	}
+	if !cmd.Flags().Changed("clipboard") {
+		toClipboard = viper.GetBool("clipboard")
+	}
+	if !cmd.Flags().Changed("primary-only") {
+		primaryOnly = viper.GetBool("primary-only")
+	}
+	if !cmd.Flags().Changed("anonymize") {
+		anonymize = viper.GetBool("anonymize")
+	}
+	if !cmd.Flags().Changed("format") {
+		outputFormat = viper.GetString("format")
+	}
+	if !cmd.Flags().Changed("export-dir") {
+		exportDir = viper.GetString("export-dir")
+	}
+	if !cmd.Flags().Changed("per-dir-output") {
+		perDirOutput = viper.GetString("per-dir-output")
+	}
+	if !cmd.Flags().Changed("compress") {
+		compression = viper.GetString("compress")
+	}
+	if !cmd.Flags().Changed("delta") {
+		delta = viper.GetBool("delta")
+	}
+	if !cmd.Flags().Changed("low-memory") {
+		lowMemory = viper.GetBool("low-memory")
+	}
+	if !cmd.Flags().Changed("grep") {
+		grepPattern = viper.GetString("grep")
+	}
+	if !cmd.Flags().Changed("around") {
+		around = viper.GetString("around")
+	}
+	if !cmd.Flags().Changed("module") {
+		module = viper.GetString("module")
+	}
+	if !cmd.Flags().Changed("package") {
+		pkgName = viper.GetString("package")
+	}
+	if !cmd.Flags().Changed("with-deps") {
+		withDeps = viper.GetBool("with-deps")
+	}
+	if !cmd.Flags().Changed("entry") {
+		entryPoints = configStringSlice("entry")
+	}
+	if !cmd.Flags().Changed("selection") {
+		selectionFile = viper.GetString("selection")
+	}
+	if !cmd.Flags().Changed("survey") {
+		survey = viper.GetBool("survey")
+	}
+	if !cmd.Flags().Changed("context-files") {
+		contextFiles = viper.GetBool("context-files")
+	}
+	if !cmd.Flags().Changed("changed-within") {
+		changedWithin = viper.GetString("changed-within")
+	}
+	if !cmd.Flags().Changed("changed-after") {
+		changedAfter = viper.GetString("changed-after")
+	}
+	if !cmd.Flags().Changed("git-dates") {
+		gitDates = viper.GetBool("git-dates")
+	}
+	if !cmd.Flags().Changed("owner") {
+		owner = viper.GetString("owner")
+	}
+
+	if !cmd.Flags().Changed("repo-root") {
+		fromRepoRoot = viper.GetBool("repo-root")
+	}
+	if fromRepoRoot {
+		repoRoot, err := analyzer.GetRepoRoot(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --repo-root requires a git repository: %s is not in one\n", path)
+			os.Exit(1)
+		}
+		slog.Info("collecting from the repository root", "path", repoRoot)
+		path = repoRoot
+	}
+
+	if !cmd.Flags().Changed("prompt-timeout") {
+		promptTimeout = viper.GetDuration("prompt-timeout")
+	}
+	if assumeYes && assumeNo {
+		fmt.Fprintln(os.Stderr, "Error: --yes and --no cannot be combined")
+		os.Exit(1)
+	}
+
+	var selected []collector.Selected
+	if selectionFile != "" {
+		path, selected = loadSelection(selectionFile, path)
+	}
+
 	if err := analyzer.ValidatePath(path); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// The link itself passed the safety check; so must its target
+	if resolved, err := analyzer.ResolveRoot(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	} else if resolved != path {
+		if err := analyzer.ValidatePath(resolved); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		slog.Debug("resolved root", "path", path, "resolved", resolved)
+		path = resolved
+	}
+
 	// Check if it's a git repo and prompt if not
 	isGitRepo := analyzer.IsGitRepo(path)
 	if !isGitRepo {
-		fmt.Fprintf(os.Stderr, "\033[33m⚠️  Warning: %s is not in a git repository\033[0m\n", path)
+		ui.Status("warn.not-git", path)
 		fmt.Fprintln(os.Stderr, "bcopy works best in git repos but can run anywhere.")
-		fmt.Fprint(os.Stderr, "\033[33mPress Enter to continue or Ctrl+C to cancel...\033[0m ")
-		
-		reader := bufio.NewReader(os.Stdin)
-		_, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "\nCanceled by user\n")
+
+		if !waitForEnter("Press Enter to continue or Ctrl+C to cancel...") {
+			fmt.Fprintf(os.Stderr, "\n%s\n", ui.T("canceled"))
 			os.Exit(1)
 		}
 		fmt.Fprintln(os.Stderr, "")
 	}
 
-	if shouldWarn, warning := analyzer.ShouldWarnLargeDirectory(path); shouldWarn {
-		fmt.Fprintln(os.Stderr, warning)
-	}
-
 	if !cmd.Flags().Changed("no-gitignore") {
 		noGitignore = viper.GetBool("no-gitignore")
 	}
@@ -131,37 +585,505 @@ func runBcopy(cmd *cobra.Command, args []string) {
 		excludeTests = viper.GetBool("exclude-tests")
 	}
 
+	if !cmd.Flags().Changed("with-fixtures") {
+		withFixtures = viper.GetBool("with-fixtures")
+	}
+
+	if !cmd.Flags().Changed("ignore-case") {
+		ignoreCase = viper.GetBool("ignore-case")
+	}
+
+	if !cmd.Flags().Changed("no-gitattributes") {
+		noAttributes = viper.GetBool("no-gitattributes")
+	}
+	if !cmd.Flags().Changed("no-editorconfig") {
+		noEditorConfig = viper.GetBool("no-editorconfig")
+	}
+
 	if len(customExcludes) == 0 {
-		customExcludes = viper.GetStringSlice("exclude")
+		customExcludes = configStringSlice("exclude")
 	}
 
 	if len(allowedExts) == 0 {
-		allowedExts = viper.GetStringSlice("ext")
+		allowedExts = configStringSlice("ext")
+	}
+
+	if !cmd.Flags().Changed("ignore-file") {
+		ignoreFiles = configStringSlice("ignore-file")
+	}
+
+	if !cmd.Flags().Changed("no-default-excludes") {
+		noDefaultExcl = viper.GetBool("no-default-excludes")
 	}
 
 	if maxDepth == 0 {
 		maxDepth = viper.GetInt("max-depth")
 	}
 
+	if !cmd.Flags().Changed("max-depth-for") {
+		maxDepthFor = configStringSlice("max-depth-for")
+	}
+
+	if !cmd.Flags().Changed("one-file-system") {
+		oneFileSystem = viper.GetBool("one-file-system")
+	}
+
+	if !cmd.Flags().Changed("max-files") {
+		maxFiles = viper.GetInt("max-files")
+	}
+
+	if !cmd.Flags().Changed("toc") {
+		toc = viper.GetBool("toc")
+	}
+	if !cmd.Flags().Changed("fence-lang-all") {
+		fenceLangAll = viper.GetString("fence-lang-all")
+	}
+	if !cmd.Flags().Changed("no-fences") {
+		noFences = viper.GetBool("no-fences")
+	}
+	if !cmd.Flags().Changed("group-by-dir") {
+		groupByDir = viper.GetBool("group-by-dir")
+	}
+	if !cmd.Flags().Changed("alias-paths") {
+		aliasPaths = viper.GetBool("alias-paths")
+	}
+	if !cmd.Flags().Changed("metadata") {
+		fileMetadata = viper.GetBool("metadata")
+	}
+	if !cmd.Flags().Changed("strip-prefix") {
+		stripPrefix = viper.GetString("strip-prefix")
+	}
+	if !cmd.Flags().Changed("add-prefix") {
+		addPrefix = viper.GetString("add-prefix")
+	}
+	if noFences && fenceLangAll != "" {
+		fmt.Fprintln(os.Stderr, "Error: --fence-lang-all and --no-fences can't be combined")
+		os.Exit(1)
+	}
+
+	if !cmd.Flags().Changed("with-docs") {
+		withDocs = viper.GetBool("with-docs")
+	}
+	if !cmd.Flags().Changed("with-meta") {
+		withMeta = viper.GetBool("with-meta")
+	}
+
+	if !cmd.Flags().Changed("survey-tokens") {
+		surveyTokens = viper.GetInt("survey-tokens")
+	}
+
+	if !cmd.Flags().Changed("per-dir-tokens") {
+		perDirTokens = viper.GetInt("per-dir-tokens")
+	}
+
+	if !cmd.Flags().Changed("idl") {
+		idlMode = viper.GetBool("idl")
+	}
+	if !cmd.Flags().Changed("schema") {
+		schemaMode = viper.GetBool("schema")
+	}
+	if idlMode && schemaMode {
+		fmt.Fprintln(os.Stderr, "Error: --idl and --schema can't be combined")
+		os.Exit(1)
+	}
+	if survey && (idlMode || schemaMode) {
+		fmt.Fprintln(os.Stderr, "Error: --survey can't be combined with --idl or --schema")
+		os.Exit(1)
+	}
+
+	if !cmd.Flags().Changed("deps-summary") {
+		depsSummary = viper.GetBool("deps-summary")
+	}
+	if !cmd.Flags().Changed("locks") {
+		locks = viper.GetString("locks")
+	}
+	if locks != "exclude" && locks != "summary" {
+		fmt.Fprintf(os.Stderr, "Error: unsupported --locks %q (use exclude or summary)\n", locks)
+		os.Exit(1)
+	}
+	if !cmd.Flags().Changed("api-surface") {
+		apiSurface = viper.GetBool("api-surface")
+	}
+	if apiSurface {
+		if idlMode || schemaMode {
+			fmt.Fprintln(os.Stderr, "Error: --api-surface can't be combined with --idl or --schema")
+			os.Exit(1)
+		}
+		allowedExts = []string{".go"}
+		excludeTests = true
+	}
+
+	if !cmd.Flags().Changed("include-env") {
+		includeEnv = viper.GetBool("include-env")
+	}
+
+	if !cmd.Flags().Changed("reproducible") {
+		reproducible = viper.GetBool("reproducible")
+	}
+
+	if !cmd.Flags().Changed("normalize-eol") {
+		normalizeEOL = viper.GetString("normalize-eol")
+	}
+
+	if !cmd.Flags().Changed("retab") {
+		retabWidth = viper.GetInt("retab")
+	}
+
+	if !cmd.Flags().Changed("use-tabs") {
+		useTabs = viper.GetBool("use-tabs")
+	}
+
+	if !cmd.Flags().Changed("header") {
+		header = viper.GetBool("header")
+	}
+	if !cmd.Flags().Changed("with-todos") {
+		withTodos = viper.GetBool("with-todos")
+	}
+	if !cmd.Flags().Changed("env-info") {
+		envInfo = viper.GetBool("env-info")
+	}
+	if !cmd.Flags().Changed("with-history") {
+		withHistory = viper.GetInt("with-history")
+	}
+	if !cmd.Flags().Changed("history-paths") {
+		historyPaths = viper.GetBool("history-paths")
+	}
+	if withHistory < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --with-history can't be negative")
+		os.Exit(1)
+	}
+
+	if len(anonReplace) == 0 {
+		anonReplace = configStringSlice("anonymize-replace")
+	}
+
+	if !cmd.Flags().Changed("anonymize-paths") {
+		anonPaths = viper.GetBool("anonymize-paths")
+	}
+
+	if !cmd.Flags().Changed("strict") {
+		strict = viper.GetBool("strict")
+	}
+
+	if !cmd.Flags().Changed("on-error") {
+		onError = viper.GetString("on-error")
+	}
+	if strict {
+		onError = "fail"
+	}
+	switch onError {
+	case "skip", "warn", "fail":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported --on-error %q (use skip, warn, or fail)\n", onError)
+		os.Exit(1)
+	}
+
+	if !cmd.Flags().Changed("minified") {
+		minified = viper.GetString("minified")
+	}
+	switch collector.MinifiedMode(minified) {
+	case collector.MinifiedSkip, collector.MinifiedTruncate, collector.MinifiedKeep:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported --minified %q (use skip, truncate, or keep)\n", minified)
+		os.Exit(1)
+	}
+
+	if !cmd.Flags().Changed("no-history") {
+		noHistory = viper.GetBool("no-history")
+	}
+
+	if !cmd.Flags().Changed("pii-check") {
+		piiCheck = viper.GetBool("pii-check")
+	}
+	if !cmd.Flags().Changed("suggest-ignores") {
+		suggestIgnores = viper.GetBool("suggest-ignores")
+	}
+	if !cmd.Flags().Changed("clipboard-timeout") {
+		clipTimeout = viper.GetDuration("clipboard-timeout")
+	}
+	if !cmd.Flags().Changed("verify") {
+		verifyCopy = viper.GetBool("verify")
+	}
+	if !cmd.Flags().Changed("primary") {
+		primary = viper.GetBool("primary")
+	}
+	if primaryOnly && verifyCopy {
+		fmt.Fprintln(os.Stderr, "Error: --verify reads the clipboard back and can't be combined with --primary-only")
+		os.Exit(1)
+	}
+
+	if !cmd.Flags().Changed("fail-on-pii") {
+		failOnPII = viper.GetBool("fail-on-pii")
+	}
+	if !cmd.Flags().Changed("license-check") {
+		licenseCheck = viper.GetBool("license-check")
+	}
+	if !cmd.Flags().Changed("fail-on-license") {
+		failOnLicense = configStringSlice("fail-on-license")
+	}
+
 	if !cmd.Flags().Changed("threshold") {
 		if viper.IsSet("threshold") {
-			thresholdMB = viper.GetFloat64("threshold")
+			threshold = viper.GetString("threshold")
 		}
 	}
 
 	if !cmd.Flags().Changed("hard-max") {
 		if viper.IsSet("hard-max") {
-			hardMaxMB = viper.GetFloat64("hard-max")
+			hardMax = viper.GetString("hard-max")
 		}
 	}
 
+	if !cmd.Flags().Changed("model") {
+		model = viper.GetString("model")
+	}
+	var err error
+	if warnLimit, err = limit.Parse(threshold, model); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --threshold: %v\n", err)
+		os.Exit(1)
+	}
+	if abortLimit, err = limit.Parse(hardMax, model); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --hard-max: %v\n", err)
+		os.Exit(1)
+	}
+	rewritePath, err := prefixRewriter(stripPrefix, addPrefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	if !cmd.Flags().Changed("max-file-size") {
 		if viper.IsSet("max-file-size") {
 			maxFileSizeMB = viper.GetFloat64("max-file-size")
 		}
 	}
 
-	filter := analyzer.NewFilter(allowedExts, customExcludes, !noGitignore, excludeTests)
+	slog.Debug("resolved settings",
+		"config", viper.ConfigFileUsed(),
+		"ext", allowedExts,
+		"exclude", customExcludes,
+		"no-gitignore", noGitignore,
+		"exclude-tests", excludeTests,
+		"with-fixtures", withFixtures,
+		"ignore-case", ignoreCase,
+		"max-depth", maxDepth,
+		"max-depth-for", maxDepthFor,
+		"one-file-system", oneFileSystem,
+		"survey-tokens", surveyTokens,
+		"api-surface", apiSurface,
+		"max-files", maxFiles,
+		"max-file-size", maxFileSizeMB,
+		"threshold", warnLimit,
+		"hard-max", abortLimit)
+
+	if outputFormat == "" {
+		outputFormat = "markdown"
+		if strings.EqualFold(filepath.Ext(outputFile), ".zip") {
+			outputFormat = "zip"
+		}
+	}
+	switch outputFormat {
+	case "markdown", "bcopy", "json-string", "jsonl":
+	case "zip":
+		if outputFile == "" {
+			fmt.Fprintln(os.Stderr, "Error: --format zip requires --output")
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported --format %q (use markdown, bcopy, json-string, jsonl, or zip)\n", outputFormat)
+		os.Exit(1)
+	}
+
+	if compression != "" {
+		if outputFile == "" {
+			fmt.Fprintln(os.Stderr, "Error: --compress requires --output")
+			os.Exit(1)
+		}
+		if compress.Extension(compression) == "" {
+			fmt.Fprintf(os.Stderr, "Error: unsupported --compress %q (use gzip or zstd)\n", compression)
+			os.Exit(1)
+		}
+	}
+	if diffOutput {
+		switch {
+		case outputFile == "":
+			fmt.Fprintln(os.Stderr, "Error: --diff-output requires --output")
+			os.Exit(1)
+		case outputFormat == "zip" || compression != "":
+			fmt.Fprintln(os.Stderr, "Error: --diff-output only compares uncompressed text output")
+			os.Exit(1)
+		}
+	}
+	if perDirOutput != "" && outputFormat == "zip" {
+		fmt.Fprintln(os.Stderr, "Error: --per-dir-output can't write zip archives")
+		os.Exit(1)
+	}
+	if perDirTokens < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --per-dir-tokens can't be negative")
+		os.Exit(1)
+	}
+	if slotName != "" {
+		if err := slots.ValidateName(slotName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --slot: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	sinks := buildSinks()
+	checkSinkPolicy(sinks)
+
+	attachments, err := readAttachments(attachFiles, selectionFile == "-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --attach: %v\n", err)
+		os.Exit(1)
+	}
+	commandOutputs, err := runCommands(runCommand)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --run: %v\n", err)
+		os.Exit(1)
+	}
+
+	var depthRules []collector.DepthRule
+	for _, s := range maxDepthFor {
+		rule, err := collector.ParseDepthRule(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --max-depth-for: %v\n", err)
+			os.Exit(1)
+		}
+		depthRules = append(depthRules, rule)
+	}
+
+	quotas, quotaOrder, err := quotaConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	extLimits, err := extLimitsConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var grepRe *regexp.Regexp
+	if grepPattern != "" {
+		var err error
+		grepRe, err = regexp.Compile(grepPattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --grep pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if !cmd.Flags().Changed("around-lines") {
+		aroundLines = viper.GetInt("around-lines")
+	}
+	var aroundRe *regexp.Regexp
+	if around != "" {
+		if aroundLines < 0 {
+			fmt.Fprintln(os.Stderr, "Error: --around-lines can't be negative")
+			os.Exit(1)
+		}
+		aroundRe = collector.SymbolPattern(around)
+	}
+
+	cutoff, err := parseChangedCutoff(changedWithin, changedAfter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var changedPaths map[string]bool
+	if !cutoff.IsZero() && gitDates {
+		if !isGitRepo {
+			fmt.Fprintln(os.Stderr, "Error: --git-dates requires a git repository")
+			os.Exit(1)
+		}
+		repoRoot, err := analyzer.GetRepoRoot(path)
+		if err == nil {
+			changedPaths, err = analyzer.ChangedSince(repoRoot, cutoff)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read git history: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var codeOwners *analyzer.CodeOwners
+	if owner != "" {
+		if !isGitRepo {
+			fmt.Fprintln(os.Stderr, "Error: --owner requires a git repository")
+			os.Exit(1)
+		}
+		repoRoot, err := analyzer.GetRepoRoot(path)
+		if err == nil {
+			codeOwners, err = analyzer.LoadCodeOwners(repoRoot)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read CODEOWNERS: %v\n", err)
+			os.Exit(1)
+		}
+		if codeOwners == nil {
+			fmt.Fprintln(os.Stderr, "Error: --owner requires a CODEOWNERS file (.github/, repo root, or docs/)")
+			os.Exit(1)
+		}
+	}
+
+	var attributes *analyzer.GitAttributes
+	if !noAttributes && isGitRepo {
+		repoRoot, err := analyzer.GetRepoRoot(path)
+		if err == nil {
+			attributes, _ = analyzer.LoadGitAttributes(repoRoot)
+		}
+	}
+	var editorConfig *analyzer.EditorConfig
+	if !noEditorConfig {
+		editorConfig = analyzer.NewEditorConfig()
+	}
+
+	enforcePolicy()
+	var transforms []collector.Transform
+	if apiSurface {
+		transforms = append(transforms, transform.APISurface())
+	}
+	if depsSummary {
+		transforms = append(transforms, transform.DepsSummary())
+	}
+	if locks == "summary" {
+		transforms = append(transforms, transform.LockSummary(path))
+	}
+	if reproducible && !cmd.Flags().Changed("normalize-eol") && !viper.IsSet("normalize-eol") {
+		normalizeEOL = "lf"
+	}
+	eolTransform, err := transform.NormalizeEOL(normalizeEOL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --normalize-eol: %v\n", err)
+		os.Exit(1)
+	}
+	if eolTransform != nil {
+		transforms = append(transforms, eolTransform)
+	}
+	if retabWidth > 0 || viper.IsSet("retab-by-ext") {
+		transforms = append(transforms, transform.Retab(transform.RetabOptions{
+			Width:   retabWidth,
+			UseTabs: useTabs,
+			ByExt:   retabByExt(),
+		}))
+	}
+	if includeEnv {
+		transforms = append(transforms, transform.MaskEnv())
+	}
+	if anonymize {
+		transforms = append(transforms, transform.Anonymize(transform.AnonymizeOptions{
+			Replacements: transform.ParseReplacements(anonReplace),
+		}))
+	}
+
+	filter := analyzer.NewFilter(allowedExts, alwaysExcludes(), customExcludes, !noGitignore, excludeTests)
+	filter.SetIgnoreCase(ignoreCase)
+	filter.SetWithFixtures(withFixtures)
+	filter.Ban(orgPolicy.Banned())
+	if locks == "summary" {
+		filter.SetLockfiles(manifest.Lockfiles)
+	}
 
 	if !noGitignore && isGitRepo {
 		repoRoot, err := analyzer.GetRepoRoot(path)
@@ -170,6 +1092,62 @@ func runBcopy(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	for _, ignoreFile := range ignoreFiles {
+		// Relative paths are tried against the working directory, then the
+		// scanned directory, so --ignore-file .dockerignore works for either
+		if _, err := os.Stat(ignoreFile); err != nil && !filepath.IsAbs(ignoreFile) {
+			ignoreFile = filepath.Join(path, ignoreFile)
+		}
+		if err := filter.LoadIgnoreFile(ignoreFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load ignore file: %v\n", err)
+			os.Exit(1)
+		}
+		slog.Debug("loaded ignore file", "path", ignoreFile)
+	}
+	excludeArtifacts(filter, path, sinks)
+
+	if !cmd.Flags().Changed("warn-files") {
+		warnFiles = viper.GetInt("warn-files")
+	}
+	if selected == nil {
+		includeDir := filter.ShouldIncludeDir
+		if oneFileSystem {
+			onRoot := analyzer.OnFileSystem(path)
+			includeDir = func(relPath string) bool {
+				return filter.ShouldIncludeDir(relPath) && onRoot(filepath.Join(path, relPath))
+			}
+		}
+		if shouldWarn, name, homeTopLevel := analyzer.ShouldWarnLargeDirectory(path, warnFiles, includeDir); shouldWarn {
+			if homeTopLevel {
+				ui.Status("warn.large.home", name, warnFiles)
+			} else {
+				ui.Status("warn.large", name, warnFiles)
+			}
+			if !confirm(ui.T("prompt.continue")) {
+				fmt.Fprintln(os.Stderr, ui.T("aborted.large"))
+				os.Exit(0)
+			}
+		}
+	}
+
+	entries := resolveEntryPoints(path, entryPoints)
+
+	var within []string
+	if module != "" && pkgName != "" {
+		fmt.Fprintln(os.Stderr, "Error: --module and --package can't be combined")
+		os.Exit(1)
+	}
+	if withDeps && pkgName == "" {
+		fmt.Fprintln(os.Stderr, "Error: --with-deps requires --package")
+		os.Exit(1)
+	}
+	if module != "" {
+		within = resolveModule(path, module)
+	}
+	if pkgName != "" {
+		within = resolvePackage(path, pkgName, withDeps)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -181,83 +1159,657 @@ func runBcopy(cmd *cobra.Command, args []string) {
 		cancel()
 	}()
 
-	result, err := collector.Collect(ctx, path, filter, maxDepth, maxFileSizeMB)
+	var result *collector.CollectionResult
+	if selected != nil {
+		result, err = collector.CollectSelected(path, withoutBanned(selected), collector.Options{
+			MaxFileSizeMB: maxFileSizeMB,
+			Transforms:    transforms,
+			IncludeEnv:    includeEnv,
+			GroupByDir:    groupByDir,
+			EditorConfig:  editorConfig,
+		})
+	} else {
+		result, err = collector.Collect(ctx, path, filter, collector.Options{
+			MaxDepth:      maxDepth,
+			DepthRules:    depthRules,
+			OneFileSystem: oneFileSystem,
+			MaxFileSizeMB: maxFileSizeMB,
+			MaxFiles:      maxFiles,
+			Quotas:        quotas,
+			QuotaOrder:    quotaOrder,
+			ExtLimits:     extLimits,
+			Minified:      collector.MinifiedMode(minified),
+			GroupByDir:    groupByDir,
+			Grep:          grepRe,
+			Around:        aroundRe,
+			AroundLines:   aroundLines,
+			ContextFiles:  contextFiles,
+			ChangedAfter:  cutoff,
+			ChangedPaths:  changedPaths,
+			Owner:         owner,
+			CodeOwners:    codeOwners,
+			Attributes:    attributes,
+			EditorConfig:  editorConfig,
+			Transforms:    transforms,
+			Within:        within,
+			EntryPoints:   entries,
+			WithDocs:      withDocs,
+			WithMeta:      withMeta,
+			WithFixtures:  withFixtures,
+			Survey:        survey,
+			SurveyTokens:  surveyTokens,
+			IDL:           idlMode,
+			Schema:        schemaMode,
+			IncludeEnv:    includeEnv,
+			LowMemory:     lowMemory,
+			Preflight: func(files int, estimatedSize int64, byExt []collector.ExtSize) error {
+				checkSizeLimits(estimatedSize, ui.T("label.estimated"), byExt)
+				return nil
+			},
+		})
+	}
 	if err != nil {
-		if err == context.Canceled {
+		if errors.Is(err, collector.ErrCanceled) {
 			fmt.Fprintln(os.Stderr, "\nCollection canceled by user")
 			os.Exit(130)
 		}
+		if errors.Is(err, collector.ErrTooManyFiles) {
+			fmt.Fprintln(os.Stderr)
+			ui.Status("error", err)
+			fmt.Fprintln(os.Stderr, "This is a safety limit to catch runs on generated sites or data directories.")
+			fmt.Fprintln(os.Stderr, "Use --max-files to increase it or narrow the selection with --exclude/--ext.")
+			os.Exit(1)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	defer result.Close()
+
+	if result.Unreadable() > 0 {
+		reportReadErrors(result)
+	}
+
+	if mounts := result.OtherFileSystems; len(mounts) > 0 {
+		ui.Status("warn.mounts", len(mounts))
+		for _, mount := range mounts {
+			fmt.Fprintf(os.Stderr, "   ./%s\n", collector.DisplayPath(mount))
+		}
+	}
+
+	if len(result.OverQuota) > 0 {
+		var size int64
+		for _, file := range result.OverQuota {
+			size += file.Size
+		}
+		ui.Status("warn.quotas", len(result.OverQuota), collector.FormatSize(size))
+	}
+	if len(result.Minified) > 0 {
+		var size int64
+		for _, file := range result.Minified {
+			size += file.Size
+		}
+		ui.Status(ui.N("warn.minified", len(result.Minified)), len(result.Minified), collector.FormatSize(size))
+	}
+	if len(result.ExtLimited) > 0 {
+		var size int64
+		for _, file := range result.ExtLimited {
+			size += file.Size
+		}
+		ui.Status("warn.ext-limits", len(result.ExtLimited), collector.FormatSize(size))
+	}
+
+	if names := result.InvalidNames; len(names) > 0 {
+		ui.Status("warn.invalid-names", len(names))
+		for _, name := range names {
+			slog.Debug("file name is not valid UTF-8", "path", fmt.Sprintf("%q", name))
+		}
+	}
+
+	if len(entries) > 0 {
+		reportMissingEntryPoints(result, entries)
+	}
+
+	// full is the whole selection, recorded in history even when only the
+	// delta is emitted
+	full := result
+	var deltaHeader string
+	if delta {
+		result, deltaHeader = applyDelta(path, full)
+	}
+
 	if result.FileCount == 0 {
-		fmt.Fprintln(os.Stderr, "\n\033[31m❌ No files found matching the criteria\033[0m")
+		if delta && deltaHeader != "" {
+			fmt.Fprintln(os.Stderr)
+			ui.Status("none.changed")
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr)
+		ui.Status("none.found")
 		os.Exit(0)
 	}
 
+	if piiCheck || failOnPII {
+		reportPII(result)
+	}
+	if licenseCheck || len(failOnLicense) > 0 {
+		reportLicenses(result, path)
+	}
+
 	sizeMB := float64(result.TotalSize) / (1024 * 1024)
-	fmt.Fprintf(os.Stderr, "\n\033[35m✨ Found \033[1m%d files\033[0m\033[35m (\033[1m%.2f MB\033[0m\033[35m)\033[0m\n", result.FileCount, sizeMB)
+	fmt.Fprintln(os.Stderr)
+	if skipped := result.Unreadable(); skipped > 0 {
+		ui.Status("found.skipped", result.FileCount, sizeMB, skipped)
+	} else {
+		ui.Status("found", result.FileCount, sizeMB)
+	}
 
-	// Check hard maximum
-	if sizeMB > hardMaxMB {
-		fmt.Fprintf(os.Stderr, "\n\033[31m❌ Error: Total size (%.2f MB) exceeds hard maximum (%.2f MB)\033[0m\n", sizeMB, hardMaxMB)
-		fmt.Fprintln(os.Stderr, "This is a safety limit to prevent clipboard overflow.")
-		fmt.Fprintf(os.Stderr, "Use --hard-max to increase or --output to write to a file instead.\n")
-		os.Exit(1)
+	checkSizeLimits(result.TotalSize, ui.T("label.total"), result.SizeByExtension())
+
+	formatOpts := collector.FormatOptions{
+		TOC:           toc,
+		Preamble:      deltaHeader,
+		Reproducible:  reproducible,
+		Delimited:     outputFormat == "bcopy",
+		JSONString:    outputFormat == "json-string",
+		JSONLines:     outputFormat == "jsonl",
+		FenceLanguage: fenceLangAll,
+		NoFences:      noFences,
+		GroupByDir:    groupByDir,
+		AliasPaths:    aliasPaths,
+		Metadata:      fileMetadata,
+	}
+	if header {
+		formatOpts.Preamble = frontMatter(cmd, path, result) + formatOpts.Preamble
+	}
+	if len(attachments) > 0 {
+		formatOpts.Appendix = collector.AttachmentsMarkdown(attachments)
+	}
+	if len(commandOutputs) > 0 {
+		formatOpts.Appendix += collector.CommandOutputMarkdown(commandOutputs)
+	}
+	// --anonymize-paths hashes the emitted paths only, so history, deltas
+	// and blame still see the real ones
+	if anonymize && anonPaths {
+		prefixed := rewritePath
+		rewritePath = func(relPath string) string {
+			if prefixed != nil {
+				relPath = prefixed(relPath)
+			}
+			return transform.HashDirs(relPath)
+		}
+	}
+	if withTodos {
+		if absRoot, err := filepath.Abs(path); err == nil {
+			if items := collectTodos(absRoot, result, true); len(items) > 0 {
+				if rewritePath != nil {
+					for i := range items {
+						items[i].Path = rewritePath(items[i].Path)
+					}
+				}
+				formatOpts.Appendix += todos.Markdown(items)
+			}
+		}
+	}
+	if envInfo {
+		formatOpts.Appendix += environmentInfo(path)
+	}
+	if withHistory > 0 {
+		formatOpts.Appendix += commitHistory(path, result, withHistory, historyPaths)
 	}
 
-	if sizeMB > thresholdMB {
-		fmt.Fprintf(os.Stderr, "\n\033[33m⚠️  Warning: Total size (%.2f MB) exceeds threshold (%.2f MB)\033[0m\n", sizeMB, thresholdMB)
-		fmt.Fprint(os.Stderr, "\033[33mContinue copying to clipboard? (y/N): \033[0m")
+	// Paths are rewritten last, so everything above still sees the files
+	// on disk. The audit log hashes the real paths.
+	audited := result
+	if rewritePath != nil {
+		result = result.Renamed(rewritePath)
+	}
+	redactExtras(&formatOpts)
+	checkPolicyPayload(result, formatOpts)
 
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	if diffOutput {
+		written, err := showOutputDiff(result, formatOpts)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading response: %v\n", err)
+			fmt.Fprintln(os.Stderr)
+			ui.Status("error", err)
 			os.Exit(1)
 		}
+		auditResult(path, audited, written, "stdout")
+		return
+	}
+
+	record := false
+	var written int64
+	for _, out := range sinks {
+		n, err := out.write(result, formatOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			ui.Status("error", err)
+			os.Exit(1)
+		}
+		written = max(written, n)
+		record = record || out.recorded()
+	}
+	auditRun(path, audited, written, sinks)
+	recordOutputs(sinks)
+	if record {
+		recordRun(path, full, formatOpts)
+	}
+	if suggestIgnores {
+		reportIgnoreSuggestions(full)
+	}
+}
+
+// recordRun stores the payload and per-file hashes in the local history so
+// later runs can be compared against it. Failures are logged, not fatal.
+func recordRun(root string, result *collector.CollectionResult, formatOpts collector.FormatOptions) {
+	if noHistory {
+		return
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		slog.Warn("failed to record run", "error", err)
+		return
+	}
+
+	run := history.Run{
+		Time:      time.Now(),
+		Root:      absRoot,
+		FileCount: result.FileCount,
+		TotalSize: result.TotalSize,
+		Files:     make(map[string]string, len(result.Files)),
+	}
+	for _, file := range result.Files {
+		content, err := result.ReadContent(file)
+		if err != nil {
+			slog.Warn("failed to record run", "error", err)
+			return
+		}
+		run.Files[file.RelPath] = history.Hash(content)
+	}
+
+	// The recorded payload is always the full selection, and markdown so
+	// diff-runs can read it back
+	formatOpts.Preamble = ""
+	formatOpts.Appendix = ""
+	formatOpts.JSONString = false
+	formatOpts.JSONLines = false
+	err = history.Record(run, func(w io.Writer) error {
+		return collector.WriteMarkdown(w, result, formatOpts)
+	})
+	if err != nil {
+		slog.Warn("failed to record run", "error", err)
+		return
+	}
+	slog.Debug("recorded run in history", "root", absRoot, "files", result.FileCount)
+}
+
+// retabByExt reads the retab-by-ext config section, mapping extensions to
+// per-extension --retab widths
+func retabByExt() map[string]int {
+	byExt := make(map[string]int)
+	for ext, width := range viper.GetStringMap("retab-by-ext") {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		byExt[ext] = cast.ToInt(width)
+	}
+	return byExt
+}
+
+// quotaConfig reads the quotas config section, mapping globs to a size or
+// file count, and quota-order. A file counts against the most specific
+// matching glob, taken to be the longest.
+func quotaConfig() ([]collector.Quota, collector.QuotaOrder, error) {
+	order := collector.QuotaOrder(viper.GetString("quota-order"))
+	switch order {
+	case "":
+		order = collector.QuotaByPath
+	case collector.QuotaByPath, collector.QuotaLargestFirst:
+	default:
+		return nil, "", fmt.Errorf("unsupported quota-order %q (use path or largest)", order)
+	}
+
+	var quotas []collector.Quota
+	for pattern, limit := range viper.GetStringMap("quotas") {
+		quota, err := collector.ParseQuota(pattern, cast.ToString(limit))
+		if err != nil {
+			return nil, "", err
+		}
+		quotas = append(quotas, quota)
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		if len(quotas[i].Pattern) != len(quotas[j].Pattern) {
+			return len(quotas[i].Pattern) > len(quotas[j].Pattern)
+		}
+		return quotas[i].Pattern < quotas[j].Pattern
+	})
+	return quotas, order, nil
+}
+
+// extLimitsConfig reads the ext-limits config section, mapping extensions
+// to the most bytes their files may add up to
+func extLimitsConfig() ([]collector.ExtLimit, error) {
+	var limits []collector.ExtLimit
+	for ext, size := range viper.GetStringMap("ext-limits") {
+		limit, err := collector.ParseExtLimit(ext, cast.ToString(size))
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// alwaysExcludes resolves the always-exclude list from the built-in
+// defaults and the always-exclude config section (replace, then remove,
+// then add). --no-default-excludes keeps only the added patterns.
+func alwaysExcludes() []string {
+	patterns := analyzer.DefaultExcludes()
+	if viper.IsSet("always-exclude.replace") {
+		patterns = configStringSlice("always-exclude.replace")
+	}
+	if noDefaultExcl {
+		patterns = nil
+	}
 
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
-			fmt.Fprintln(os.Stderr, "Canceled by user")
+	patterns = analyzer.RemoveExcludes(patterns, configStringSlice("always-exclude.remove"))
+	patterns = append(patterns, configStringSlice("always-exclude.add")...)
+	slog.Debug("resolved always-exclude patterns", "patterns", patterns)
+	return patterns
+}
+
+// checkSizeLimits aborts when size bytes exceed --hard-max and prompts when
+// they exceed --threshold, showing which extensions the size is made of.
+// label distinguishes the pre-read estimate from the final size. Once the
+// user has confirmed, later checks don't prompt again.
+func checkSizeLimits(size int64, label string, byExt []collector.ExtSize) {
+	checkPolicySize(float64(size)/(1024*1024), label)
+
+	// Check hard maximum
+	if abortLimit.Exceeds(size) {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("error.hard-max", label, abortLimit.Measure(size), abortLimit)
+		printSizeByExtension(byExt, abortLimit)
+		fmt.Fprintln(os.Stderr, ui.T("hint.hard-max"))
+		os.Exit(1)
+	}
+
+	if warnLimit.Exceeds(size) && !sizeConfirmed {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("warn.threshold", label, warnLimit.Measure(size), warnLimit)
+		printSizeByExtension(byExt, warnLimit)
+		if !confirm(ui.T("prompt.continue-copy")) {
+			fmt.Fprintln(os.Stderr, ui.T("canceled"))
 			os.Exit(0)
 		}
+		sizeConfirmed = true
+	}
+}
+
+// printSizeByExtension lists the extensions contributing most to a size,
+// in tokens when lim is a token limit
+func printSizeByExtension(byExt []collector.ExtSize, lim limit.Limit) {
+	const maxShown = 5
+
+	shown := byExt[:min(len(byExt), maxShown)]
+	width := 0
+	for _, entry := range shown {
+		width = max(width, len(extLabel(entry.Ext)))
+	}
+	for _, entry := range shown {
+		size := collector.FormatSize(entry.Size)
+		if lim.Tokens > 0 {
+			size = lim.Measure(entry.Size)
+		}
+		files := "files"
+		if entry.Files == 1 {
+			files = "file"
+		}
+		fmt.Fprintf(os.Stderr, "   %-*s  %s across %d %s\n", width, extLabel(entry.Ext), size, entry.Files, files)
+	}
+	if len(byExt) > maxShown {
+		fmt.Fprintf(os.Stderr, "   ...and %d more\n", len(byExt)-maxShown)
 	}
+}
+
+// extLabel names an extension in the size breakdown
+func extLabel(ext string) string {
+	if ext == "" {
+		return "(none)"
+	}
+	return ext
+}
 
-	markdown := collector.FormatAsMarkdown(result)
+// resolveModule finds the workspace members of root named name and returns
+// their directories, exiting if root is not a workspace or nothing matches
+func resolveModule(root, name string) []string {
+	ws, err := workspace.Detect(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: reading workspace: %v\n", err)
+		os.Exit(1)
+	}
+	if ws == nil {
+		fmt.Fprintf(os.Stderr, "Error: --module needs a workspace root (go.work, pnpm-workspace.yaml, package.json workspaces, or a Cargo [workspace])\n")
+		os.Exit(1)
+	}
+
+	members := ws.Find(name)
+	if len(members) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no workspace member named %q (members: %s)\n", name, strings.Join(ws.Names(), ", "))
+		os.Exit(1)
+	}
+
+	dirs := make([]string, len(members))
+	for i, m := range members {
+		dirs[i] = m.Dir
+		ui.Status("module", m.Name, m.Kind, m.Dir)
+	}
+	return dirs
+}
 
-	// Handle different output modes
-	if dryRun {
-		fmt.Println(markdown)
+// resolveEntryPoints converts --entry paths to slash-separated paths relative
+// to root. A path is taken relative to the working directory if it exists
+// there, and relative to root otherwise.
+func resolveEntryPoints(root string, paths []string) []string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+
+	var entries []string
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			if abs, err := filepath.Abs(p); err == nil {
+				if rel, err := filepath.Rel(absRoot, abs); err == nil && !strings.HasPrefix(rel, "..") {
+					p = rel
+				}
+			}
+		}
+		entries = append(entries, filepath.ToSlash(filepath.Clean(p)))
+	}
+	return entries
+}
+
+// reportIgnoreSuggestions lists the paths collector.SuggestIgnores finds
+// worth excluding
+func reportIgnoreSuggestions(result *collector.CollectionResult) {
+	suggestions := collector.SuggestIgnores(result)
+	fmt.Fprintln(os.Stderr)
+	if len(suggestions) == 0 {
+		ui.Status("suggest.none")
 		return
 	}
 
-	if outputFile != "" {
-		fmt.Fprintf(os.Stderr, "\033[36m📝 Writing to file...\033[0m ")
-		if err := os.WriteFile(outputFile, []byte(markdown), 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "\n\033[31m❌ Error writing to file: %v\033[0m\n", err)
-			os.Exit(1)
+	ui.Status("suggest.header")
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, s := range suggestions {
+		size := collector.FormatSize(s.Size)
+		if s.Files > 1 {
+			size = ui.T("suggest.files", size, s.Files)
+		}
+		note := ui.T("suggest.included")
+		if s.Skipped {
+			note = ui.T("suggest.skipped")
+		}
+		fmt.Fprintf(w, "   %s\t%s (%s)\n", s.Pattern, size, note)
+	}
+	w.Flush()
+}
+
+// reportMissingEntryPoints warns about --entry paths that were not collected
+func reportMissingEntryPoints(result *collector.CollectionResult, entries []string) {
+	found := make(map[string]bool, len(result.Files))
+	for _, file := range result.Files {
+		found[filepath.ToSlash(file.RelPath)] = true
+	}
+	for _, entry := range entries {
+		if !found[entry] {
+			ui.Status("warn.entry", entry)
+		}
+	}
+}
+
+// reportReadErrors applies the --on-error policy to paths that could not be
+// read: skip logs them at debug level, warn lists them, and fail lists them
+// and exits
+func reportReadErrors(result *collector.CollectionResult) {
+	const maxShown = 10
+
+	if onError == "skip" {
+		for _, skip := range result.Skipped {
+			if skip.Err != nil {
+				slog.Debug("skipped path", "path", skip.Path, "reason", skip.Reason, "error", skip.Err)
+			}
 		}
-		fmt.Fprintln(os.Stderr, "\033[32m✓\033[0m")
-		fmt.Fprintf(os.Stderr, "\033[1m\033[32m✅ Successfully written to %s!\033[0m\n", outputFile)
 		return
 	}
 
-	fmt.Fprint(os.Stderr, "\033[36m📋 Copying to clipboard...\033[0m ")
+	if paths := result.PermissionDenied; len(paths) > 0 {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("warn.permission", len(paths))
+		for i, p := range paths {
+			if i == maxShown {
+				fmt.Fprintf(os.Stderr, "   ...and %d more\n", len(paths)-maxShown)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "   ./%s\n", collector.DisplayPath(p))
+		}
+	}
 
-	if err := clipboard.Copy(markdown); err != nil {
-		fmt.Fprintf(os.Stderr, "\n\033[31m❌ Error copying to clipboard: %v\033[0m\n", err)
+	if errs := result.ReadErrors; len(errs) > 0 {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("warn.read-errors", len(errs))
+		for i, e := range errs {
+			if i == maxShown {
+				fmt.Fprintf(os.Stderr, "   ...and %d more\n", len(errs)-maxShown)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "   ./%s: %v\n", collector.DisplayPath(e.RelPath), e.Err)
+		}
+	}
+
+	if onError == "fail" {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("abort.on-error")
 		os.Exit(1)
 	}
+}
 
-	fmt.Fprintln(os.Stderr, "\033[32m✓\033[0m")
-	fmt.Fprintln(os.Stderr, "\033[1m\033[32m✅ Successfully copied to clipboard!\033[0m")
+// reportPII prints likely personal data found in the collected files and
+// exits when --fail-on-pii is set
+func reportPII(result *collector.CollectionResult) {
+	const maxShown = 20
+
+	var findings []pii.Finding
+	for _, file := range result.Files {
+		content, err := result.ReadContent(file)
+		if err != nil {
+			continue
+		}
+		findings = append(findings, pii.Scan(file.RelPath, content)...)
+	}
+	if len(findings) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr)
+	ui.Status("warn.pii", len(findings))
+	for i, f := range findings {
+		if i == maxShown {
+			fmt.Fprintf(os.Stderr, "   ...and %d more\n", len(findings)-maxShown)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "   ./%s:%d  %s  %s\n", f.Path, f.Line, f.Kind, f.Match)
+	}
+
+	if failOnPII {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("abort.pii")
+		fmt.Fprintln(os.Stderr, "Use --anonymize to rewrite emails and hostnames, or exclude the files listed above.")
+		os.Exit(1)
+	}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// parseChangedCutoff turns --changed-within / --changed-after into a single
+// cutoff time. A zero time means no mtime filtering.
+func parseChangedCutoff(within, after string) (time.Time, error) {
+	if within != "" && after != "" {
+		return time.Time{}, fmt.Errorf("--changed-within and --changed-after cannot be combined")
+	}
+
+	if after != "" {
+		t, err := time.ParseInLocation("2006-01-02", after, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --changed-after date %q (expected YYYY-MM-DD)", after)
+		}
+		return t, nil
+	}
+
+	if within != "" {
+		window, err := parseWindow(within)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --changed-within window %q: %w", within, err)
+		}
+		return time.Now().Add(-window), nil
+	}
+
+	return time.Time{}, nil
+}
+
+// parseWindow extends time.ParseDuration with day (d) and week (w) units
+func parseWindow(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(s, suffix), 64)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
 }
 
 func main() {
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/clipboard"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/compress"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pasteDir    string
+	pasteForce  bool
+	pasteDryRun bool
+)
+
+var pasteCmd = &cobra.Command{
+	Use:   "paste [payload | -]",
+	Short: "Write the files of a payload back to disk",
+	Long: `Write the files of a bcopy payload back to disk, relative to --dir. The payload
+is read from the clipboard, the given file, or stdin with -.
+
+Payloads written with --format bcopy round-trip byte for byte: every file
+carries its size and SHA-256, and a payload whose content does not match is
+rejected. Markdown payloads are read on a best-effort basis.
+
+Existing files with different content are only overwritten with --force.
+Files holding only some line ranges (from an editor selection) are skipped.`,
+	Example: `  bcopy --format bcopy ./src && bcopy paste --dir /tmp/src-copy
+  bcopy paste payload.txt --dry-run
+  pbpaste | bcopy paste - --force`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runPaste,
+}
+
+func init() {
+	pasteCmd.Flags().StringVar(&pasteDir, "dir", ".", "Directory to write the files into")
+	pasteCmd.Flags().BoolVar(&pasteForce, "force", false, "Overwrite existing files whose content differs")
+	pasteCmd.Flags().BoolVar(&pasteDryRun, "dry-run", false, "List what would be written without writing")
+	rootCmd.AddCommand(pasteCmd)
+}
+
+func runPaste(cmd *cobra.Command, args []string) error {
+	payload, source, err := readPastePayload(args)
+	if err != nil {
+		return err
+	}
+
+	files, err := collector.ParsePayload(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("%s: no files found; is this a bcopy payload?", source)
+	}
+
+	var write []collector.FileData
+	var conflicts []string
+	for _, file := range files {
+		if file.Partial() {
+			fmt.Fprintf(os.Stderr, "   ./%s (skipped: only some lines)\n", collector.DisplayPath(file.RelPath))
+			continue
+		}
+
+		target := filepath.Join(pasteDir, filepath.FromSlash(file.RelPath))
+		existing, err := os.ReadFile(target)
+		switch {
+		case err == nil && string(existing) == file.Content:
+			fmt.Fprintf(os.Stderr, "   ./%s (unchanged)\n", collector.DisplayPath(file.RelPath))
+			continue
+		case err == nil && !pasteForce:
+			conflicts = append(conflicts, file.RelPath)
+			continue
+		case err == nil:
+			fmt.Fprintf(os.Stderr, "   ./%s (overwritten)\n", collector.DisplayPath(file.RelPath))
+		default:
+			fmt.Fprintf(os.Stderr, "   ./%s (new)\n", collector.DisplayPath(file.RelPath))
+		}
+		write = append(write, file)
+	}
+
+	if len(conflicts) > 0 {
+		fmt.Fprintln(os.Stderr)
+		ui.Status("paste.conflicts", len(conflicts))
+		for _, p := range conflicts {
+			fmt.Fprintf(os.Stderr, "   ./%s\n", collector.DisplayPath(p))
+		}
+		return fmt.Errorf("refusing to overwrite; pass --force to replace them")
+	}
+
+	if len(write) == 0 {
+		fmt.Fprintln(os.Stderr, "\nNothing to write")
+		return nil
+	}
+	if pasteDryRun {
+		fmt.Fprintf(os.Stderr, "\nWould write %d files to %s\n", len(write), pasteDir)
+		return nil
+	}
+
+	result := &collector.CollectionResult{Files: write, FileCount: len(write)}
+	if _, err := collector.ExportFiles(result, pasteDir); err != nil {
+		return err
+	}
+	ui.Status("paste.wrote", len(write), source, pasteDir)
+	return nil
+}
+
+// readPastePayload reads the file named in args, stdin for "-", or the
+// clipboard. Compressed --output payloads are decompressed.
+func readPastePayload(args []string) ([]byte, string, error) {
+	if len(args) == 0 {
+		text, err := clipboard.Paste()
+		if err != nil {
+			return nil, "", fmt.Errorf("reading clipboard: %w", err)
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, "", fmt.Errorf("the clipboard is empty")
+		}
+		return []byte(text), "clipboard", nil
+	}
+
+	if args[0] == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return data, "stdin", err
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if dr, err := compress.NewReader(f); err == nil {
+		defer dr.Close()
+		r = dr
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	data, err := io.ReadAll(r)
+	return data, filepath.Base(args[0]), err
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/policy"
+	"github.com/nodelike/bcopy/internal/transform"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+// orgPolicy is the organization policy, or nil when none is installed
+var orgPolicy *policy.Policy
+
+// initPolicy loads the organization policy. A policy that exists but can't
+// be read or parsed stops bcopy rather than being skipped.
+func initPolicy() {
+	p, err := policy.Load()
+	if err == nil && p != nil {
+		err = analyzer.NewFilter(nil, nil, nil, false, false).Ban(p.BannedPaths)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if p != nil {
+		slog.Info("using policy", "path", p.Path)
+	}
+	orgPolicy = p
+}
+
+// enforcePolicy overrides the flags and config the policy constrains. Call
+// it once the flags are resolved.
+func enforcePolicy() {
+	if orgPolicy == nil {
+		return
+	}
+	if orgPolicy.Redact {
+		anonymize = true
+		anonReplace = append(anonReplace, orgPolicy.RedactReplace...)
+	}
+}
+
+// checkSinkPolicy exits when the policy disallows one of the run's outputs
+func checkSinkPolicy(sinks []sink) {
+	if err := sinkPolicyError(sinks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// sinkPolicyError returns the policy's error for the first disallowed sink
+func sinkPolicyError(sinks []sink) error {
+	for _, s := range sinks {
+		target, _ := sinkTarget(s)
+		if err := orgPolicy.Check(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPolicySize exits when sizeMB exceeds the policy's payload cap
+func checkPolicySize(sizeMB float64, label string) {
+	if err := policySizeError(sizeMB, label); err != nil {
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// policySizeError returns an error when sizeMB exceeds the policy's
+// payload cap
+func policySizeError(sizeMB float64, label string) error {
+	if orgPolicy == nil || orgPolicy.MaxPayloadMB == 0 || sizeMB <= orgPolicy.MaxPayloadMB {
+		return nil
+	}
+	return fmt.Errorf("%s (%.2f MB) exceeds the %.2f MB allowed by the policy in %s", label, sizeMB, orgPolicy.MaxPayloadMB, orgPolicy.Path)
+}
+
+// checkPolicyPayload exits when the rendered payload, with the preamble and
+// appendix (attachments, command output, commit history) that the file
+// sizes don't count, exceeds the policy's payload cap
+func checkPolicyPayload(result *collector.CollectionResult, formatOpts collector.FormatOptions) {
+	if err := payloadPolicyError(result, formatOpts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// payloadPolicyError renders the payload and returns an error when it
+// exceeds the policy's payload cap
+func payloadPolicyError(result *collector.CollectionResult, formatOpts collector.FormatOptions) error {
+	if orgPolicy == nil || orgPolicy.MaxPayloadMB == 0 {
+		return nil
+	}
+	counter := &countingWriter{}
+	if err := collector.WriteMarkdown(counter, result, formatOpts); err != nil {
+		return fmt.Errorf("formatting output: %w", err)
+	}
+	return policySizeError(float64(counter.n)/(1024*1024), ui.T("label.payload"))
+}
+
+// policyTransforms are the transforms the policy requires of collections
+// that don't go through the root command's flags, such as gRPC requests
+func policyTransforms() []collector.Transform {
+	if orgPolicy == nil || !orgPolicy.Redact {
+		return nil
+	}
+	return []collector.Transform{transform.Anonymize(transform.AnonymizeOptions{
+		Replacements: transform.ParseReplacements(orgPolicy.RedactReplace),
+	})}
+}
+
+// redactExtras anonymizes the preamble and appendix when --anonymize is on,
+// as it always is under a redacting policy. Attachments, command output,
+// commit messages, and environment info aren't collected files, so the
+// transforms never see them.
+func redactExtras(formatOpts *collector.FormatOptions) {
+	if !anonymize {
+		return
+	}
+	redact := transform.Anonymize(transform.AnonymizeOptions{
+		Replacements: transform.ParseReplacements(anonReplace),
+	})
+	for _, text := range []*string{&formatOpts.Preamble, &formatOpts.Appendix} {
+		file := collector.FileData{Content: *text}
+		redact(&file)
+		*text = file.Content
+	}
+}
+
+// bannedByPolicy reports whether the policy bans a path given relative to
+// the scanned directory or the working directory
+func bannedByPolicy(path string) bool {
+	banned := orgPolicy.Banned()
+	if len(banned) == 0 {
+		return false
+	}
+	filter := analyzer.NewFilter(nil, nil, nil, false, false)
+	filter.Ban(banned)
+	return filter.IsBanned(filepath.ToSlash(filepath.Clean(path)))
+}
+
+// withoutBanned drops the editor or --selection entries the policy bans
+func withoutBanned(selected []collector.Selected) []collector.Selected {
+	var kept []collector.Selected
+	for _, sel := range selected {
+		if bannedByPolicy(sel.RelPath) {
+			slog.Info("selected file excluded: banned by policy", "path", sel.RelPath)
+			continue
+		}
+		kept = append(kept, sel)
+	}
+	return kept
+}
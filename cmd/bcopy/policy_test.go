@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/policy"
+)
+
+// setPolicy installs p as the organization policy for one test, restoring
+// the policy and the anonymize flags it can force when the test ends
+func setPolicy(t *testing.T, p *policy.Policy) {
+	t.Helper()
+	saved, anon, replace := orgPolicy, anonymize, anonReplace
+	t.Cleanup(func() {
+		orgPolicy, anonymize, anonReplace = saved, anon, replace
+	})
+	orgPolicy = p
+	anonymize, anonReplace = false, nil
+}
+
+func TestSinkPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		disallowed []string
+		sinks      []sink
+		wantErr    string
+	}{
+		{"no policy", nil, []sink{clipboardSink{}, fileSink{"out.md"}}, ""},
+		{"allowed", []string{"share"}, []sink{stdoutSink{}, fileSink{"out.md"}, slotSink{"a"}}, ""},
+		{"clipboard banned", []string{"clipboard"}, []sink{fileSink{"out.md"}, clipboardSink{}}, "clipboard is disallowed"},
+		{"file banned", []string{"file"}, []sink{exportSink{"export"}}, "file is disallowed"},
+		{"slot banned", []string{"slot"}, []sink{slotSink{"a"}}, "slot is disallowed"},
+		{"stdout banned", []string{"stdout"}, []sink{stdoutSink{}}, "stdout is disallowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p *policy.Policy
+			if tt.disallowed != nil {
+				p = &policy.Policy{Path: "policy.yaml", DisallowedTargets: tt.disallowed}
+			}
+			setPolicy(t, p)
+			err := sinkPolicyError(tt.sinks)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("sinkPolicyError() = %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("sinkPolicyError() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPayloadPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		capMB    float64
+		appendix int // bytes of appendix
+		exceeds  bool
+	}{
+		{"no cap", 0, 2 << 20, false},
+		{"under cap", 1, 1 << 10, false},
+		// The files alone are tiny: only the appendix pushes it over
+		{"appendix over cap", 1, 2 << 20, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPolicy(t, &policy.Policy{Path: "policy.yaml", MaxPayloadMB: tt.capMB})
+			formatOpts := collector.FormatOptions{Appendix: strings.Repeat("x", tt.appendix)}
+			err := payloadPolicyError(testResult(), formatOpts)
+			if (err != nil) != tt.exceeds {
+				t.Errorf("payloadPolicyError() = %v, want exceeded %v", err, tt.exceeds)
+			}
+		})
+	}
+}
+
+func TestPolicyForcesRedaction(t *testing.T) {
+	setPolicy(t, &policy.Policy{Path: "policy.yaml", Redact: true, RedactReplace: []string{"Acme=Example"}})
+	enforcePolicy()
+	if !anonymize {
+		t.Fatal("a redacting policy didn't turn on --anonymize")
+	}
+
+	formatOpts := collector.FormatOptions{
+		Preamble: "remote: git@build.acme.internal:acme/app.git\n",
+		Appendix: "## Attachment: notes.txt\n\nAsk jane@acme.com at Acme\n\n## Command: hostname\n\nci-01.corp\n",
+	}
+	redactExtras(&formatOpts)
+	for _, leaked := range []string{"jane@acme.com", "Acme", "build.acme.internal", "ci-01.corp"} {
+		if strings.Contains(formatOpts.Preamble+formatOpts.Appendix, leaked) {
+			t.Errorf("%q survived redaction:\n%s%s", leaked, formatOpts.Preamble, formatOpts.Appendix)
+		}
+	}
+	if !strings.Contains(formatOpts.Appendix, "at Example") {
+		t.Errorf("policy replacement not applied:\n%s", formatOpts.Appendix)
+	}
+}
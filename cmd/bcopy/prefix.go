@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// prefixRewriter returns a function applying --strip-prefix and
+// --add-prefix to a relative path: strip is removed from the paths under
+// that directory, then add is prepended to every path, so
+// "services/payments/api.go" becomes "pay/api.go" with strip
+// "services/payments" and add "pay/". It returns nil when neither is set.
+func prefixRewriter(strip, add string) (func(string) string, error) {
+	strip, err := cleanPrefix(strip)
+	if err != nil {
+		return nil, fmt.Errorf("--strip-prefix: %w", err)
+	}
+	add, err = cleanPrefix(add)
+	if err != nil {
+		return nil, fmt.Errorf("--add-prefix: %w", err)
+	}
+	if strip == "" && add == "" {
+		return nil, nil
+	}
+
+	return func(relPath string) string {
+		relPath = filepath.ToSlash(relPath)
+		if rest, ok := strings.CutPrefix(relPath, strip); ok && strip != "" {
+			relPath = rest
+		}
+		return filepath.FromSlash(add + relPath)
+	}, nil
+}
+
+// cleanPrefix normalizes a directory prefix to slash form with a trailing
+// slash, or "" for none
+func cleanPrefix(prefix string) (string, error) {
+	if prefix == "" {
+		return "", nil
+	}
+	cleaned := filepath.ToSlash(filepath.Clean(prefix))
+	switch {
+	case strings.HasPrefix(cleaned, "/") || filepath.IsAbs(prefix):
+		return "", fmt.Errorf("%q must be a relative path", prefix)
+	case cleaned == ".." || strings.HasPrefix(cleaned, "../"):
+		return "", fmt.Errorf("%q can't leave the scanned directory", prefix)
+	case cleaned == ".":
+		return "", nil
+	}
+	return cleaned + "/", nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanPrefix(t *testing.T) {
+	tests := []struct {
+		prefix, want string
+		wantErr      string
+	}{
+		{"", "", ""},
+		{".", "", ""},
+		{"./", "", ""},
+		{"pay", "pay/", ""},
+		{"services/payments/", "services/payments/", ""},
+		{"a/./b//c", "a/b/c/", ""},
+		{"a/../b", "b/", ""},
+		{"a/..", "", ""},
+		{"..", "", "can't leave"},
+		{"../sibling", "", "can't leave"},
+		{"a/../../b", "", "can't leave"},
+		{"/etc", "", "must be a relative path"},
+	}
+	for _, tt := range tests {
+		got, err := cleanPrefix(tt.prefix)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("cleanPrefix(%q) = %q, %v; want an error containing %q", tt.prefix, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("cleanPrefix(%q) = %q, %v; want %q", tt.prefix, got, err, tt.want)
+		}
+	}
+}
+
+func TestPrefixRewriter(t *testing.T) {
+	tests := []struct {
+		strip, add string
+		paths      map[string]string // relative path to rewritten path
+		wantErr    string
+	}{
+		{strip: "", add: "", paths: nil},
+		{
+			strip: "services/payments", add: "pay/",
+			paths: map[string]string{
+				"services/payments/api.go": "pay/api.go",
+				"services/payments2/x.go":  "pay/services/payments2/x.go",
+				"cmd/main.go":              "pay/cmd/main.go",
+			},
+		},
+		{strip: "src", paths: map[string]string{"src/a/b.go": "a/b.go", "lib/c.go": "lib/c.go"}},
+		{add: "vendor/app", paths: map[string]string{"main.go": "vendor/app/main.go"}},
+		{strip: "../x", wantErr: "--strip-prefix"},
+		{add: "a/../../x", wantErr: "--add-prefix"},
+		{add: "/abs", wantErr: "--add-prefix"},
+	}
+	for _, tt := range tests {
+		rewrite, err := prefixRewriter(tt.strip, tt.add)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("prefixRewriter(%q, %q) error = %v, want one naming %s", tt.strip, tt.add, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("prefixRewriter(%q, %q): %v", tt.strip, tt.add, err)
+		}
+		if tt.paths == nil {
+			if rewrite != nil {
+				t.Errorf("prefixRewriter(%q, %q) returned a rewriter, want nil", tt.strip, tt.add)
+			}
+			continue
+		}
+		for relPath, want := range tt.paths {
+			if got := filepath.ToSlash(rewrite(filepath.FromSlash(relPath))); got != want {
+				t.Errorf("prefixRewriter(%q, %q)(%q) = %q, want %q", tt.strip, tt.add, relPath, got, want)
+			}
+		}
+	}
+}
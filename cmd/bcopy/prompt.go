@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/ui"
+	"golang.org/x/term"
+)
+
+// errPromptTimeout is returned by readLine when --prompt-timeout expires
+var errPromptTimeout = errors.New("prompt timed out")
+
+// stdinReader is shared by all prompts so buffered input isn't lost
+// between them
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// isInteractive reports whether stdin is a terminal a user can answer from
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// headlessAnswer returns the --yes/--no policy for runs without a
+// terminal, falling back to def when neither flag is set
+func headlessAnswer(def bool) bool {
+	switch {
+	case assumeYes:
+		return true
+	case assumeNo:
+		return false
+	}
+	return def
+}
+
+// confirm asks a y/N question on stderr. The question is colored as a
+// whole, never inside, so screen readers in --accessible mode (which drops
+// the color) read it as plain text. Without a terminal it never
+// blocks and answers per --yes/--no, defaulting to no. With a terminal the
+// answer is no once --prompt-timeout expires.
+func confirm(question string) bool {
+	if assumeYes || assumeNo || !isInteractive() {
+		answer := headlessAnswer(false)
+		fmt.Fprintf(os.Stderr, "%s %s\n", ui.Paint(ui.Warning, question+" (y/N):"), yesNo(answer))
+		return answer
+	}
+
+	fmt.Fprintf(os.Stderr, "%s ", ui.Paint(ui.Warning, question+" (y/N):"))
+	response, err := readLine()
+	if err != nil {
+		if err == errPromptTimeout {
+			fmt.Fprintf(os.Stderr, "\nNo answer within %s\n", promptTimeout)
+		}
+		return false
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+// waitForEnter shows message and waits for Enter. Without a terminal it
+// continues unless --no is set.
+func waitForEnter(message string) bool {
+	if assumeYes || assumeNo || !isInteractive() {
+		return headlessAnswer(true)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s ", ui.Paint(ui.Warning, message))
+	_, err := readLine()
+	if err == errPromptTimeout {
+		fmt.Fprintf(os.Stderr, "\nNo answer within %s\n", promptTimeout)
+	}
+	return err == nil
+}
+
+// readLine reads one line from stdin, giving up after --prompt-timeout
+// when one is set
+func readLine() (string, error) {
+	if promptTimeout <= 0 {
+		return stdinReader.ReadString('\n')
+	}
+
+	type line struct {
+		text string
+		err  error
+	}
+	lines := make(chan line, 1)
+	go func() {
+		text, err := stdinReader.ReadString('\n')
+		lines <- line{text, err}
+	}()
+
+	select {
+	case l := <-lines:
+		return l.text, l.err
+	case <-time.After(promptTimeout):
+		return "", errPromptTimeout
+	}
+}
+
+func yesNo(answer bool) string {
+	if answer {
+		return "y"
+	}
+	return "n"
+}
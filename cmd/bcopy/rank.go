@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/rank"
+	"github.com/spf13/cobra"
+)
+
+// rankCommitLimit bounds how much history is read for commit frequency
+const rankCommitLimit = 500
+
+var rankTop int
+
+var rankCmd = &cobra.Command{
+	Use:   "rank [path]",
+	Short: "List the most central files of a project",
+	Long: `Score every selected file by import fan-in (how many files import it or its
+package), how often it changed in the last 500 commits, and path heuristics
+(main, server, router, ... score up; tests and fixtures score down), then
+print the top files.`,
+	Example: `  bcopy rank
+  bcopy rank ./backend -n 50`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runRank,
+}
+
+func init() {
+	rankCmd.Flags().IntVarP(&rankTop, "top", "n", 20, "Number of files to list (0 = all)")
+	rootCmd.AddCommand(rankCmd)
+}
+
+func runRank(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	if err := analyzer.ValidatePath(root); err != nil {
+		return err
+	}
+	absRoot, err := analyzer.ResolveRoot(root)
+	if err != nil {
+		return err
+	}
+	if err := analyzer.ValidatePath(absRoot); err != nil {
+		return err
+	}
+
+	filter := analyzer.NewFilter(nil, alwaysExcludes(), nil, true, false)
+	filter.Ban(orgPolicy.Banned())
+	repoRoot, gitErr := analyzer.GetRepoRoot(absRoot)
+	if gitErr == nil {
+		filter.LoadGitignore(repoRoot)
+	}
+
+	result, err := collector.Collect(context.Background(), absRoot, filter, collector.Options{
+		MaxFileSizeMB: 10,
+		LowMemory:     true,
+	})
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	files := make([]rank.File, 0, len(result.Files))
+	for _, file := range result.Files {
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return err
+		}
+		files = append(files, rank.File{Path: filepath.ToSlash(file.RelPath), Content: content})
+	}
+
+	var commits map[string]int
+	if gitErr == nil {
+		counts, err := analyzer.CommitCounts(repoRoot, rankCommitLimit)
+		if err != nil {
+			return err
+		}
+		commits = make(map[string]int, len(counts))
+		for absPath, n := range counts {
+			if rel, err := filepath.Rel(absRoot, absPath); err == nil {
+				commits[filepath.ToSlash(rel)] = n
+			}
+		}
+	}
+
+	results := rank.Score(files, commits)
+	if rankTop > 0 && len(results) > rankTop {
+		results = results[:rankTop]
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "%4s  %6s  %6s  %7s  %s\n", "#", "SCORE", "FAN-IN", "COMMITS", "PATH")
+	for i, r := range results {
+		fmt.Fprintf(out, "%4d  %6.2f  %6d  %7d  %s\n", i+1, r.Score, r.FanIn, r.Commits, r.Path)
+	}
+	return nil
+}
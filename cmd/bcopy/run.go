@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+// runCommands runs each --run command through the shell in the current
+// directory and captures its combined output. A command that exits
+// non-zero is expected (failing tests are the point), so only a command
+// that can't be started is an error.
+func runCommands(commands []string) ([]collector.CommandOutput, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var outputs []collector.CommandOutput
+	for _, command := range commands {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+
+		ui.Begin("run.start", command)
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			fmt.Fprintln(os.Stderr)
+			return nil, errors.New(ui.T("canceled"))
+		case errors.As(err, &exitErr):
+		case err != nil:
+			fmt.Fprintln(os.Stderr)
+			return nil, fmt.Errorf("%s: %w", command, err)
+		}
+
+		exitCode := cmd.ProcessState.ExitCode()
+		if exitCode != 0 {
+			ui.Complete("run.done", exitCode)
+		} else {
+			ui.Complete("")
+		}
+		outputs = append(outputs, collector.CommandOutput{Command: command, Output: out.String(), ExitCode: exitCode})
+	}
+	return outputs, nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/clipboard"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/selection"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxSelectionBytes bounds the size of a request body
+const maxSelectionBytes = 4 << 20
+
+var (
+	serveEditor bool
+	serveGRPC   bool
+	serveAddr   string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local server for editor integrations and agent frameworks",
+	Long: `With --editor, listen on localhost for selections sent by editor plugins and
+answer with the formatted payload, optionally copying it to the clipboard.
+
+The server writes its URL and a random token to editor.json in the bcopy cache
+directory; plugins read it and send the token as "Authorization: Bearer <token>".
+
+  GET  /v1/health    {"status": "ok", "version": "..."}
+  POST /v1/payload   a selection plus options, e.g.
+                     {"version": 1, "root": "/home/me/project",
+                      "files": [{"uri": "file:///home/me/project/main.go",
+                                 "ranges": [{"start": 10, "end": 42}]}],
+                      "copy": true, "toc": false}
+                     answered with {"payload", "files", "size", "tokens", "copied"}
+
+Plugins without a running server can write the same selection to a file
+and run bcopy --selection <file>.
+
+With --grpc, serve the Collector gRPC service instead (default address
+127.0.0.1:7798): Collect returns a payload, Stats the files it would hold,
+and Stream sends it in chunks with the stats last. Connection details go to
+grpc.json in the cache directory; send the token as "authorization: Bearer
+<token>" metadata. The service definition is internal/rpc/bcopy.proto in
+the bcopy repository.`,
+	Example: `  bcopy serve --editor
+  bcopy serve --editor --addr 127.0.0.1:9000
+  bcopy serve --grpc`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runServe,
+}
+
+func init() {
+	serveCmd.Flags().BoolVar(&serveEditor, "editor", false, "Serve the editor integration endpoint")
+	serveCmd.Flags().BoolVar(&serveGRPC, "grpc", false, "Serve the Collector gRPC service")
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:7797", "Loopback address to listen on (127.0.0.1:7798 with --grpc)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// editorInfo is written to editor.json for plugins to discover the server
+type editorInfo struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+	PID   int    `json:"pid"`
+}
+
+// payloadRequest is a selection plus output options
+type payloadRequest struct {
+	selection.Selection
+	Copy bool `json:"copy"`
+	TOC  bool `json:"toc"`
+}
+
+type payloadResponse struct {
+	Payload string `json:"payload"`
+	Files   int    `json:"files"`
+	Size    int64  `json:"size"`
+	Tokens  int64  `json:"tokens"`
+	Copied  bool   `json:"copied"`
+	// Skipped lists selected files that could not be read or were left
+	// out for their content, with the reason for each in SkipReasons
+	Skipped     []string          `json:"skipped,omitempty"`
+	SkipReasons map[string]string `json:"skip_reasons,omitempty"`
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if err := orgPolicy.Check("serve"); err != nil {
+		return err
+	}
+	switch {
+	case serveEditor && serveGRPC:
+		return fmt.Errorf("--editor and --grpc are separate servers; run one per process")
+	case serveGRPC:
+		if !cmd.Flags().Changed("addr") {
+			serveAddr = "127.0.0.1:7798"
+		}
+	case !serveEditor:
+		return fmt.Errorf("choose a mode: --editor or --grpc")
+	}
+
+	host, _, err := net.SplitHostPort(serveAddr)
+	if err != nil {
+		return fmt.Errorf("invalid --addr: %w", err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("--addr must be a loopback address, got %s", host)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return err
+	}
+	if serveGRPC {
+		return runGRPCServer(listener, token)
+	}
+	url := "http://" + listener.Addr().String()
+
+	infoPath, err := writeEditorInfo(editorInfo{URL: url, Token: token, PID: os.Getpid()})
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to write editor.json: %w", err)
+	}
+	defer os.Remove(infoPath)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": version})
+	})
+	mux.HandleFunc("POST /v1/payload", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or wrong token"})
+			return
+		}
+		resp, status, err := handlePayload(r)
+		if err != nil {
+			slog.Warn("editor request failed", "error", err)
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	ui.Status("serve.listening", url)
+	fmt.Fprintf(os.Stderr, "   Connection details for plugins: %s\n", infoPath)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Editor server stopped")
+	return nil
+}
+
+func handlePayload(r *http.Request) (*payloadResponse, int, error) {
+	var req payloadRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxSelectionBytes)).Decode(&req); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	// The server has no meaningful working directory to resolve against
+	if req.Root == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("selection needs a root")
+	}
+
+	root, selected, err := req.Resolve(req.Root)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	slog.Info("editor selection", "root", root, "files", len(selected))
+
+	result, err := collector.CollectSelected(root, withoutBanned(selected), collector.Options{MaxFileSizeMB: 10, Transforms: policyTransforms()})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	payload, err := collector.FormatAsMarkdown(result, collector.FormatOptions{TOC: req.TOC})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	resp := &payloadResponse{
+		Payload: payload,
+		Files:   result.FileCount,
+		Size:    result.TotalSize,
+		Tokens:  collector.EstimateTokens(result.TotalSize),
+	}
+	for _, skip := range result.Skipped {
+		if resp.SkipReasons == nil {
+			resp.SkipReasons = make(map[string]string)
+		}
+		resp.Skipped = append(resp.Skipped, skip.Path)
+		resp.SkipReasons[skip.Path] = string(skip.Reason)
+	}
+
+	if req.Copy {
+		if _, err := clipboard.Copy(r.Context(), payload, clipboard.Clipboard); err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("copying to clipboard: %w", err)
+		}
+		resp.Copied = true
+	}
+	destinations := []string{"serve:" + r.RemoteAddr}
+	if resp.Copied {
+		destinations = append(destinations, "clipboard")
+	}
+	auditResult(root, result, int64(len(payload)), destinations...)
+	return resp, http.StatusOK, nil
+}
+
+// authorized checks the bearer token. Requiring a custom header also keeps
+// web pages from posting to the server, since browsers preflight such
+// requests and the server never allows them.
+func authorized(r *http.Request, token string) bool {
+	got := r.Header.Get("Authorization")
+	want := "Bearer " + token
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeEditorInfo writes editor.json, readable only by the current user
+func writeEditorInfo(info editorInfo) (string, error) {
+	dir, err := history.Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "editor.json")
+	return path, os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// loadSelection reads a --selection file (or stdin for "-") and returns the
+// root to collect from and the selected files, exiting on error
+func loadSelection(file, defaultRoot string) (string, []collector.Selected) {
+	var sel *selection.Selection
+	var err error
+	if file == "-" {
+		sel, err = selection.Parse(os.Stdin)
+	} else {
+		sel, err = selection.Load(file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --selection: %v\n", err)
+		os.Exit(1)
+	}
+
+	root, selected, err := sel.Resolve(defaultRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --selection: %v\n", err)
+		os.Exit(1)
+	}
+	return root, selected
+}
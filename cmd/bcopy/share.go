@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/audit"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/compress"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/nodelike/bcopy/internal/share"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareAddr    string
+	shareTimeout time.Duration
+	shareNoQR    bool
+)
+
+var shareCmd = &cobra.Command{
+	Use:   "share [payload.md | -]",
+	Short: "Serve a payload once on the local network, with a QR code",
+	Long: `Serve a payload over HTTP on the local network at a URL with a random token,
+and print the URL and a QR code for it, so the content can be opened on
+another device (e.g. a tablet with an LLM app) without clipboard sync.
+
+The payload is the given file, stdin with -, or by default the last run
+recorded for the current directory. The server stops after the payload has
+been fetched once, or when --timeout passes.`,
+	Example: `  bcopy && bcopy share
+  bcopy share context.md
+  bcopy --stdout | bcopy share -`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runShare,
+}
+
+func init() {
+	shareCmd.Flags().StringVar(&shareAddr, "addr", ":0", "Address to listen on (default: this machine's LAN IP, random port)")
+	shareCmd.Flags().DurationVar(&shareTimeout, "timeout", 10*time.Minute, "Stop sharing after this long")
+	shareCmd.Flags().BoolVar(&shareNoQR, "no-qr", false, "Only print the URL")
+	rootCmd.AddCommand(shareCmd)
+}
+
+func runShare(cmd *cobra.Command, args []string) error {
+	if err := orgPolicy.Check("share"); err != nil {
+		return err
+	}
+	payload, source, err := readSharePayload(args)
+	if err != nil {
+		return err
+	}
+
+	server, err := share.Listen(shareAddr, payload)
+	if err != nil {
+		return err
+	}
+
+	url := server.URL()
+	ui.Status("share.sharing", source, collector.FormatSize(int64(len(payload))))
+	fmt.Fprintln(os.Stderr)
+	fmt.Println(url)
+	if !shareNoQR {
+		qr, err := share.QR(url)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprint(os.Stderr, qr)
+	}
+	fmt.Fprintf(os.Stderr, "\nWaiting for a device on this network to open it (expires in %s, Ctrl+C to stop)...\n", shareTimeout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch err := server.Serve(ctx, shareTimeout); {
+	case err == nil:
+		auditPayload(audit.Record{
+			Root:         source,
+			Bytes:        int64(len(payload)),
+			Tokens:       collector.EstimateTokens(int64(len(payload))),
+			Destinations: []string{"share:" + url},
+		})
+		ui.Status("share.fetched")
+		return nil
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(os.Stderr, "Share stopped")
+		return nil
+	default:
+		return err
+	}
+}
+
+// readSharePayload reads the file named in args, stdin for "-", or the last
+// recorded run of the working directory
+func readSharePayload(args []string) ([]byte, string, error) {
+	if len(args) > 0 && args[0] == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return data, "stdin", err
+	}
+
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, "", err
+		}
+		run, payloadPath, err := history.Last(cwd)
+		if err != nil {
+			return nil, "", err
+		}
+		if run == nil {
+			return nil, "", fmt.Errorf("no recorded run for %s; run bcopy first or pass a payload file", cwd)
+		}
+		path = payloadPath
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	// Compressed --output payloads are shared decompressed
+	var r io.Reader = f
+	if dr, err := compress.NewReader(f); err == nil {
+		defer dr.Close()
+		r = dr
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	data, err := io.ReadAll(r)
+	return data, filepath.Base(path), err
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/clipboard"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/compress"
+	"github.com/nodelike/bcopy/internal/slots"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+// sink is one destination of a run's output. A run collects once and writes
+// to every sink in turn.
+type sink interface {
+	// write sends the payload and returns the number of bytes it wrote
+	write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error)
+	// recorded reports whether the sink's payload is recorded in history
+	recorded() bool
+}
+
+// buildSinks turns the output flags into sinks: files first, then stdout,
+// then the clipboard. The clipboard is the default when nothing else is
+// chosen. --dry-run only prints, whatever else is set.
+func buildSinks() []sink {
+	if dryRun {
+		return []sink{stdoutSink{}}
+	}
+
+	var sinks []sink
+	if exportDir != "" {
+		sinks = append(sinks, exportSink{dir: exportDir})
+	}
+	if perDirOutput != "" {
+		sinks = append(sinks, perDirSink{dir: perDirOutput, maxTokens: int64(perDirTokens), ext: payloadExtension()})
+	}
+	if outputFile != "" {
+		switch {
+		case outputFormat == "zip":
+			sinks = append(sinks, zipSink{path: outputFile})
+		case compression != "":
+			sinks = append(sinks, compressedSink{path: outputFile, algorithm: compression})
+		default:
+			sinks = append(sinks, fileSink{path: outputFile})
+		}
+	}
+	if slotName != "" {
+		sinks = append(sinks, slotSink{name: slotName})
+	}
+	if toStdout {
+		sinks = append(sinks, stdoutSink{})
+	}
+	if toClipboard || len(sinks) == 0 {
+		sinks = append(sinks, clipboardSink{targets: clipboardTargets(), timeout: clipTimeout, verify: verifyCopy})
+	}
+	return sinks
+}
+
+// sinkTarget names where a sink sends the payload, as policy targets do,
+// and the file or directory it writes to, if any
+func sinkTarget(s sink) (target, path string) {
+	switch s := s.(type) {
+	case exportSink:
+		return "file", s.dir
+	case perDirSink:
+		return "file", s.dir
+	case zipSink:
+		return "file", s.path
+	case fileSink:
+		return "file", s.path
+	case compressedSink:
+		return "file", s.filename()
+	case slotSink:
+		return "slot", s.name
+	case stdoutSink:
+		return "stdout", ""
+	default:
+		return "clipboard", ""
+	}
+}
+
+type exportSink struct{ dir string }
+
+func (s exportSink) write(result *collector.CollectionResult, _ collector.FormatOptions) (int64, error) {
+	ui.Begin("export.start")
+	n, err := collector.ExportFiles(result, s.dir)
+	if err != nil {
+		return n, fmt.Errorf("exporting files: %w", err)
+	}
+	ui.Complete("")
+	ui.Status("export.done", result.FileCount, s.dir)
+	return n, nil
+}
+
+func (exportSink) recorded() bool { return false }
+
+// perDirSink writes one payload per top-level directory into dir, each
+// within maxTokens estimated tokens
+type perDirSink struct {
+	dir       string
+	maxTokens int64
+	ext       string
+}
+
+func (s perDirSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	ui.Begin("perdir.start")
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return 0, fmt.Errorf("writing per-directory payloads: %w", err)
+	}
+
+	// The appendix covers the whole run; repeating it would push every
+	// part toward its budget
+	formatOpts.Appendix = ""
+	counter := &countingWriter{}
+	parts := collector.PartitionByDir(result, s.maxTokens)
+	for _, part := range parts {
+		if part.Oversized {
+			slog.Warn("file exceeds the per-directory token budget on its own",
+				"path", part.Result.Files[0].RelPath,
+				"tokens", collector.EstimateTokens(part.Result.TotalSize),
+				"budget", s.maxTokens)
+		}
+		target := filepath.Join(s.dir, part.Name+s.ext)
+		f, err := os.Create(target)
+		if err == nil {
+			err = collector.WriteMarkdown(io.MultiWriter(f, counter), part.Result, formatOpts)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			return counter.n, fmt.Errorf("writing per-directory payloads: %w", err)
+		}
+		slog.Debug("wrote per-directory payload", "path", target, "files", part.Result.FileCount)
+	}
+	ui.Complete("")
+	ui.Status("perdir.done", len(parts), s.dir)
+	return counter.n, nil
+}
+
+func (perDirSink) recorded() bool { return false }
+
+// payloadExtension is the file extension matching --format
+func payloadExtension() string {
+	switch outputFormat {
+	case "bcopy":
+		return ".bcopy"
+	case "json-string":
+		return ".json"
+	case "jsonl":
+		return ".jsonl"
+	}
+	return ".md"
+}
+
+type zipSink struct{ path string }
+
+func (s zipSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	ui.Begin("zip.start")
+	counter := &countingWriter{}
+	f, err := os.Create(s.path)
+	if err == nil {
+		err = collector.WriteZip(result, io.MultiWriter(f, counter), formatOpts)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return counter.n, fmt.Errorf("writing zip archive: %w", err)
+	}
+	ui.Complete("")
+	ui.Status("written", s.path)
+	return counter.n, nil
+}
+
+func (zipSink) recorded() bool { return false }
+
+// fileSink streams the markdown payload, so --low-memory never
+// materializes it whole
+type fileSink struct{ path string }
+
+func (s fileSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	ui.Begin("file.start")
+	counter := &countingWriter{}
+	f, err := os.Create(s.path)
+	if err == nil {
+		err = collector.WriteMarkdown(io.MultiWriter(f, counter), result, formatOpts)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return counter.n, fmt.Errorf("writing to file: %w", err)
+	}
+	ui.Complete("")
+	ui.Status("written", s.path)
+	return counter.n, nil
+}
+
+func (fileSink) recorded() bool { return true }
+
+// compressedSink streams the markdown payload compressed with algorithm,
+// adding the conventional extension if missing
+type compressedSink struct {
+	path      string
+	algorithm string
+}
+
+// filename is the path with the extension added
+func (s compressedSink) filename() string {
+	if ext := compress.Extension(s.algorithm); !strings.HasSuffix(s.path, ext) {
+		return s.path + ext
+	}
+	return s.path
+}
+
+func (s compressedSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	target := s.filename()
+
+	ui.Begin("compressed.start", s.algorithm)
+	f, err := os.Create(target)
+	if err != nil {
+		return 0, fmt.Errorf("writing to file: %w", err)
+	}
+
+	// counter sees the payload, written the compressed bytes
+	counter, written := &countingWriter{}, &countingWriter{}
+	w, err := compress.NewWriter(io.MultiWriter(f, written), s.algorithm)
+	if err == nil {
+		err = collector.WriteMarkdown(io.MultiWriter(w, counter), result, formatOpts)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written.n, fmt.Errorf("writing to file: %w", err)
+	}
+
+	ui.Complete("")
+	ui.Status("compressed.ratio", float64(counter.n)/(1024*1024), float64(written.n)/(1024*1024))
+	ui.Status("written", target)
+	return written.n, nil
+}
+
+func (compressedSink) recorded() bool { return true }
+
+// slotSink stores the payload in a named slot for bcopy load
+type slotSink struct{ name string }
+
+func (s slotSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	ui.Begin("slot.start", s.name)
+	counter := &countingWriter{}
+	_, err := slots.Save(s.name, func(w io.Writer) error {
+		return collector.WriteMarkdown(io.MultiWriter(w, counter), result, formatOpts)
+	})
+	if err != nil {
+		return counter.n, fmt.Errorf("saving slot: %w", err)
+	}
+	ui.Complete("")
+	ui.Status("slot.saved", s.name, s.name)
+	return counter.n, nil
+}
+
+func (slotSink) recorded() bool { return true }
+
+type stdoutSink struct{}
+
+func (stdoutSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	counter := &countingWriter{}
+	if err := collector.WriteMarkdown(io.MultiWriter(os.Stdout, counter), result, formatOpts); err != nil {
+		return counter.n, fmt.Errorf("writing output: %w", err)
+	}
+	fmt.Println()
+	return counter.n, nil
+}
+
+func (stdoutSink) recorded() bool { return false }
+
+// clipboardTargets turns --primary and --primary-only into the selections
+// the clipboard sink writes
+func clipboardTargets() clipboard.Target {
+	switch {
+	case primaryOnly:
+		return clipboard.Primary
+	case primary:
+		return clipboard.Clipboard | clipboard.Primary
+	}
+	return clipboard.Clipboard
+}
+
+type clipboardSink struct {
+	targets clipboard.Target
+	timeout time.Duration
+	verify  bool
+}
+
+func (s clipboardSink) write(result *collector.CollectionResult, formatOpts collector.FormatOptions) (int64, error) {
+	markdown, err := collector.FormatAsMarkdown(result, formatOpts)
+	if err != nil {
+		return 0, fmt.Errorf("formatting output: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	ui.Begin("clipboard.start", collector.FormatSize(int64(len(markdown))))
+	slog.Debug("copying to clipboard", "bytes", len(markdown), "timeout", s.timeout)
+	stopSpinner := ui.Spin()
+	backend, err := clipboard.Copy(ctx, markdown, s.targets)
+	stopSpinner()
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return 0, fmt.Errorf("copying to clipboard: %s", ui.T("copy.timeout", s.timeout))
+		case errors.Is(err, context.Canceled):
+			return 0, errors.New(ui.T("canceled"))
+		}
+		return 0, fmt.Errorf("copying to clipboard: %w", err)
+	}
+	ui.Complete("")
+	ui.Status("copied.via", backend)
+
+	if s.verify {
+		ok, size, err := clipboard.Verify(markdown)
+		switch {
+		case err != nil:
+			ui.Status("warn.verify-read", err)
+		case !ok:
+			ui.Status("warn.verify", collector.FormatSize(int64(size)), collector.FormatSize(int64(len(markdown))))
+		default:
+			ui.Status("verified")
+		}
+	}
+	return int64(len(markdown)), nil
+}
+
+func (clipboardSink) recorded() bool { return true }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+func TestMain(m *testing.M) {
+	// Keep status lines out of the test output
+	ui.Output = io.Discard
+	os.Exit(m.Run())
+}
+
+// setOutputFlags sets the output flags for one test and restores them
+// when it ends
+func setOutputFlags(t *testing.T, set func()) {
+	t.Helper()
+	dry, stdout, clip := dryRun, toStdout, toClipboard
+	out, format, algorithm := outputFile, outputFormat, compression
+	export, perDir, slot := exportDir, perDirOutput, slotName
+	t.Cleanup(func() {
+		dryRun, toStdout, toClipboard = dry, stdout, clip
+		outputFile, outputFormat, compression = out, format, algorithm
+		exportDir, perDirOutput, slotName = export, perDir, slot
+	})
+	set()
+}
+
+// discardStdout sends what sinks print to stdout nowhere for the rest of
+// the test
+func discardStdout(t *testing.T) {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		devNull.Close()
+	})
+}
+
+func testResult() *collector.CollectionResult {
+	content := "package main\n"
+	return &collector.CollectionResult{
+		Files:     []collector.FileData{{RelPath: "main.go", Content: content, Size: int64(len(content)), Language: "go"}},
+		FileCount: 1,
+		TotalSize: int64(len(content)),
+	}
+}
+
+func TestBuildSinks(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func()
+		want []string
+	}{
+		{"default", func() {}, []string{"main.clipboardSink"}},
+		{"stdout only", func() { toStdout = true }, []string{"main.stdoutSink"}},
+		{"stdout and clipboard", func() { toStdout, toClipboard = true, true }, []string{"main.stdoutSink", "main.clipboardSink"}},
+		{"file", func() { outputFile = "out.md" }, []string{"main.fileSink"}},
+		{"zip", func() { outputFile, outputFormat = "out.zip", "zip" }, []string{"main.zipSink"}},
+		{"compressed", func() { outputFile, compression = "out.md", "gzip" }, []string{"main.compressedSink"}},
+		{"slot", func() { slotName = "a" }, []string{"main.slotSink"}},
+		{
+			"everything, files first",
+			func() {
+				toClipboard, toStdout, slotName = true, true, "a"
+				outputFile, exportDir, perDirOutput = "out.md", "export", "parts"
+			},
+			[]string{"main.exportSink", "main.perDirSink", "main.fileSink", "main.slotSink", "main.stdoutSink", "main.clipboardSink"},
+		},
+		{
+			"dry run overrides all",
+			func() {
+				dryRun, toClipboard, slotName = true, true, "a"
+				outputFile, exportDir, perDirOutput = "out.md", "export", "parts"
+			},
+			[]string{"main.stdoutSink"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOutputFlags(t, func() {
+				dryRun, toStdout, toClipboard = false, false, false
+				outputFile, outputFormat, compression = "", "", ""
+				exportDir, perDirOutput, slotName = "", "", ""
+				tt.set()
+			})
+			var got []string
+			for _, s := range buildSinks() {
+				got = append(got, fmt.Sprintf("%T", s))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("buildSinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunWritesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.md")
+	exported := filepath.Join(dir, "export")
+	setOutputFlags(t, func() {
+		dryRun = true
+		outputFile = out
+		exportDir = exported
+		toClipboard = true
+	})
+	discardStdout(t)
+
+	sinks := buildSinks()
+	if len(sinks) != 1 {
+		t.Fatalf("buildSinks() = %#v, want only stdout", sinks)
+	}
+	if _, ok := sinks[0].(stdoutSink); !ok {
+		t.Fatalf("buildSinks() = %#v, want only stdout", sinks)
+	}
+	for _, s := range sinks {
+		if _, err := s.write(testResult(), collector.FormatOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if s.recorded() {
+			t.Errorf("%T is recorded in history", s)
+		}
+	}
+	for _, path := range []string{out, exported} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a dry run (stat error %v)", path, err)
+		}
+	}
+}
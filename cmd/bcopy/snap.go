@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/audit"
+	"github.com/nodelike/bcopy/internal/clipboard"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/snap"
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapTree        bool
+	snapOutput      string
+	snapStyle       string
+	snapLineNumbers bool
+	snapMaxLines    int
+)
+
+var snapCmd = &cobra.Command{
+	Use:   "snap <file> | --tree [path]",
+	Short: "Copy a syntax-highlighted image of a file or the project tree",
+	Long: `Render a single file, or with --tree the tree of files bcopy would select,
+as a syntax-highlighted PNG and copy the image to the clipboard. Useful for
+chat tools that mangle long text.
+
+Copying images needs osascript (macOS), PowerShell (Windows), or wl-copy or
+xclip (Linux). Use --output to write the PNG to a file instead.`,
+	Example: `  bcopy snap main.go
+  bcopy snap --tree
+  bcopy snap internal/server.go --line-numbers -o server.png`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runSnap,
+}
+
+func init() {
+	snapCmd.Flags().BoolVar(&snapTree, "tree", false, "Render the tree of selected files instead of one file")
+	snapCmd.Flags().StringVarP(&snapOutput, "output", "o", "", "Write the PNG to this file instead of the clipboard")
+	snapCmd.Flags().StringVar(&snapStyle, "style", "monokai", "Color style (any chroma style, e.g. dracula, github-dark, solarized-light)")
+	snapCmd.Flags().BoolVar(&snapLineNumbers, "line-numbers", false, "Draw line numbers")
+	snapCmd.Flags().IntVar(&snapMaxLines, "max-lines", 200, "Truncate after this many lines")
+	rootCmd.AddCommand(snapCmd)
+}
+
+func runSnap(cmd *cobra.Command, args []string) error {
+	target := "clipboard"
+	if snapOutput != "" {
+		target = "file"
+	}
+	if err := orgPolicy.Check(target); err != nil {
+		return err
+	}
+
+	var title, content, lexer string
+	if snapTree {
+		lexer = "plaintext"
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		var err error
+		if title, content, err = snapTreeContent(root); err != nil {
+			return err
+		}
+	} else {
+		if len(args) == 0 {
+			return fmt.Errorf("give a file to render, or --tree")
+		}
+		if bannedByPolicy(args[0]) {
+			return fmt.Errorf("%s is banned by the policy in %s", args[0], orgPolicy.Path)
+		}
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		title, content = filepath.Base(args[0]), string(data)
+	}
+
+	image, err := snap.Render(content, snap.Options{
+		Title:       title,
+		Lexer:       lexer,
+		Style:       snapStyle,
+		MaxLines:    snapMaxLines,
+		LineNumbers: snapLineNumbers,
+	})
+	if err != nil {
+		return err
+	}
+
+	source := "."
+	if len(args) > 0 {
+		source = args[0]
+	}
+	if abs, err := filepath.Abs(source); err == nil {
+		source = abs
+	}
+	rec := audit.Record{
+		Root:         source,
+		Bytes:        int64(len(content)),
+		Tokens:       collector.EstimateTokens(int64(len(content))),
+		Destinations: []string{"clipboard"},
+	}
+	if !snapTree {
+		rec.FileCount = 1
+		rec.PathsHash = audit.HashPaths([]string{filepath.Base(source)})
+	}
+
+	if snapOutput != "" {
+		if err := os.WriteFile(snapOutput, image, 0o644); err != nil {
+			return err
+		}
+		rec.Destinations = []string{"file:" + snapOutput}
+		if abs, err := filepath.Abs(snapOutput); err == nil {
+			rec.Destinations = []string{"file:" + abs}
+		}
+		auditPayload(rec)
+		ui.Status("snap.written", snapOutput, collector.FormatSize(int64(len(image))))
+		return nil
+	}
+
+	if err := clipboard.CopyImage(image); err != nil {
+		return fmt.Errorf("copying image: %w (use --output to save it instead)", err)
+	}
+	auditPayload(rec)
+	ui.Status("snap.copied", collector.FormatSize(int64(len(image))))
+	return nil
+}
+
+// snapTreeContent collects root with the default filters and returns its
+// directory name and file tree
+func snapTreeContent(root string) (string, string, error) {
+	if err := analyzer.ValidatePath(root); err != nil {
+		return "", "", err
+	}
+	absRoot, err := analyzer.ResolveRoot(root)
+	if err != nil {
+		return "", "", err
+	}
+	if err := analyzer.ValidatePath(absRoot); err != nil {
+		return "", "", err
+	}
+
+	filter := analyzer.NewFilter(nil, alwaysExcludes(), nil, true, false)
+	filter.Ban(orgPolicy.Banned())
+	if repoRoot, err := analyzer.GetRepoRoot(absRoot); err == nil {
+		filter.LoadGitignore(repoRoot)
+	}
+
+	result, err := collector.Collect(context.Background(), absRoot, filter, collector.Options{
+		MaxFileSizeMB: 10,
+		LowMemory:     true,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	defer result.Close()
+
+	name := filepath.Base(absRoot)
+	return name, collector.Tree(name, result), nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/todos"
+	"github.com/spf13/cobra"
+)
+
+var (
+	todosJSON    bool
+	todosNoBlame bool
+)
+
+var todosCmd = &cobra.Command{
+	Use:   "todos [path]",
+	Short: "List TODO, FIXME, HACK, and XXX comments",
+	Long: `Scan every selected file for TODO, FIXME, HACK, and XXX comments and list
+them with their location and, inside a git repository, the last author of
+the line according to git blame. Lines with uncommitted changes have no
+author.
+
+To append the same list to a payload, use bcopy --with-todos.`,
+	Example: `  bcopy todos
+  bcopy todos ./backend --json
+  bcopy todos --no-blame`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runTodos,
+}
+
+func init() {
+	todosCmd.Flags().BoolVar(&todosJSON, "json", false, "Print the list as JSON")
+	todosCmd.Flags().BoolVar(&todosNoBlame, "no-blame", false, "Skip git blame (faster on large histories)")
+	rootCmd.AddCommand(todosCmd)
+}
+
+func runTodos(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	if err := orgPolicy.Check("stdout"); err != nil {
+		return err
+	}
+	if err := analyzer.ValidatePath(root); err != nil {
+		return err
+	}
+	absRoot, err := analyzer.ResolveRoot(root)
+	if err != nil {
+		return err
+	}
+	if err := analyzer.ValidatePath(absRoot); err != nil {
+		return err
+	}
+
+	filter := analyzer.NewFilter(nil, alwaysExcludes(), nil, true, false)
+	filter.Ban(orgPolicy.Banned())
+	if repoRoot, err := analyzer.GetRepoRoot(absRoot); err == nil {
+		filter.LoadGitignore(repoRoot)
+	}
+
+	result, err := collector.Collect(context.Background(), absRoot, filter, collector.Options{
+		MaxFileSizeMB: 10,
+		LowMemory:     true,
+	})
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	items := collectTodos(absRoot, result, !todosNoBlame)
+	for _, redact := range policyTransforms() {
+		for i := range items {
+			file := collector.FileData{Content: items[i].Text}
+			redact(&file)
+			items[i].Text = file.Content
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if todosJSON {
+		if items == nil {
+			items = []todos.Item{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LOCATION\tTAG\tAUTHOR\tTEXT")
+	for _, item := range items {
+		author := item.Author
+		if author == "" {
+			author = "-"
+		}
+		fmt.Fprintf(w, "%s:%d\t%s\t%s\t%s\n", item.Path, item.Line, item.Tag, author, item.Text)
+	}
+	return w.Flush()
+}
+
+// collectTodos scans the files of result for marked comments and, with
+// blame inside a git repository, attributes each to the last author of its
+// line. Files are read from disk so line numbers refer to the files as they
+// are, not to transformed or partial payload content. Files that can't be
+// blamed (untracked, for instance) keep no author.
+func collectTodos(absRoot string, result *collector.CollectionResult, blame bool) []todos.Item {
+	repoRoot := ""
+	if blame {
+		repoRoot, _ = analyzer.GetRepoRoot(absRoot)
+	}
+
+	var items []todos.Item
+	for _, file := range result.Files {
+		data, err := os.ReadFile(filepath.Join(absRoot, file.RelPath))
+		if err != nil {
+			slog.Debug("todo scan skipped", "path", file.RelPath, "error", err)
+			continue
+		}
+		content := string(data)
+		found := todos.Scan(filepath.ToSlash(file.RelPath), content)
+		if len(found) == 0 || repoRoot == "" {
+			items = append(items, found...)
+			continue
+		}
+
+		rel, err := filepath.Rel(repoRoot, filepath.Join(absRoot, file.RelPath))
+		if err != nil {
+			items = append(items, found...)
+			continue
+		}
+		blamed, err := analyzer.Blame(repoRoot, filepath.ToSlash(rel))
+		if err != nil {
+			slog.Debug("blame failed", "path", file.RelPath, "error", err)
+			items = append(items, found...)
+			continue
+		}
+
+		lines := strings.Split(content, "\n")
+		for i, item := range found {
+			n := item.Line - 1
+			if n < len(blamed) && strings.TrimSuffix(blamed[n].Text, "\r") == strings.TrimSuffix(lines[n], "\r") {
+				found[i].Author = blamed[n].Author
+			}
+		}
+		items = append(items, found...)
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/ui"
+	"github.com/nodelike/bcopy/internal/upgrade"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCheckOnly bool
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade bcopy to the latest release",
+	Long: `Check GitHub for the latest bcopy release and replace the running binary
+with it. The downloaded archive is verified against the release's
+checksums.txt before anything is replaced.
+
+If bcopy was installed with Homebrew, prefer "brew upgrade bcopy".`,
+	Example: `  bcopy upgrade --check   # Only report whether a newer version exists
+  bcopy upgrade           # Download, verify, and install the latest release`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runUpgrade,
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeCheckOnly, "check", false, "Only check for a newer version, don't install it")
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	release, err := upgrade.Latest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	latest := release.Version()
+	if !upgrade.IsNewer(latest, version) {
+		ui.Status("upgrade.current", version)
+		return nil
+	}
+
+	ui.Status("upgrade.available", latest, version)
+	if upgradeCheckOnly {
+		return nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	ui.Begin("upgrade.downloading", upgrade.ArchiveName())
+	if err := upgrade.Apply(ctx, release, exePath); err != nil {
+		fmt.Fprintln(os.Stderr)
+		return err
+	}
+
+	ui.Complete("")
+	ui.Status("upgrade.done", exePath, latest)
+	return nil
+}
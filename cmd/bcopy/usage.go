@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageWeeks int
+	usageTop   int
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Summarize your own bcopy runs from the local history",
+	Long: `Summarize the runs recorded in the local history: runs per week, the
+average and largest payload, and the directories copied most often. It reads
+only the history in the bcopy cache directory and makes no network calls;
+runs with --no-history are not counted.`,
+	Example: `  bcopy usage
+  bcopy usage --weeks 12 --top 10`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runUsage,
+}
+
+func init() {
+	usageCmd.Flags().IntVar(&usageWeeks, "weeks", 8, "Number of recent weeks to count runs for")
+	usageCmd.Flags().IntVar(&usageTop, "top", 5, "Number of most-copied directories to list")
+	rootCmd.AddCommand(usageCmd)
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	if usageWeeks < 1 || usageTop < 1 {
+		return fmt.Errorf("--weeks and --top must be at least 1")
+	}
+	runs, err := history.Log()
+	if err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded yet.")
+		return nil
+	}
+
+	fmt.Printf("%d runs recorded since %s\n", len(runs), runs[0].Time.Local().Format("2006-01-02"))
+
+	fmt.Printf("\nRuns per week:\n")
+	thisWeek := weekStart(time.Now())
+	perWeek := make(map[time.Time]int)
+	for _, run := range runs {
+		perWeek[weekStart(run.Time)]++
+	}
+	for i := usageWeeks - 1; i >= 0; i-- {
+		week := thisWeek.AddDate(0, 0, -7*i)
+		fmt.Printf("  %s  %d\n", week.Format("2006-01-02"), perWeek[week])
+	}
+
+	var totalSize int64
+	var totalFiles int
+	largest := runs[0]
+	for _, run := range runs {
+		totalSize += run.TotalSize
+		totalFiles += run.FileCount
+		if run.TotalSize > largest.TotalSize {
+			largest = run
+		}
+	}
+	average := totalSize / int64(len(runs))
+	fmt.Printf("\nAverage payload: %s, %d files, ~%d tokens\n",
+		collector.FormatSize(average), totalFiles/len(runs), collector.EstimateTokens(average))
+	fmt.Printf("Largest payload: %s, %s on %s\n",
+		collector.FormatSize(largest.TotalSize), largest.Root, largest.Time.Local().Format("2006-01-02"))
+
+	perRoot := make(map[string]int)
+	for _, run := range runs {
+		perRoot[run.Root]++
+	}
+	roots := make([]string, 0, len(perRoot))
+	for root := range perRoot {
+		roots = append(roots, root)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if perRoot[roots[i]] != perRoot[roots[j]] {
+			return perRoot[roots[i]] > perRoot[roots[j]]
+		}
+		return roots[i] < roots[j]
+	})
+	fmt.Printf("\nMost-copied directories:\n")
+	for i, root := range roots {
+		if i == usageTop {
+			break
+		}
+		fmt.Printf("  %4d  %s\n", perRoot[root], root)
+	}
+	return nil
+}
+
+// weekStart returns midnight on the Monday starting t's week, local time
+func weekStart(t time.Time) time.Time {
+	t = t.Local()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.Local)
+}
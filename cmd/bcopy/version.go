@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at build time via
+// -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+var (
+	version = "1.0.2"
+	commit  = "none"
+	date    = "unknown"
+)
+
+var (
+	versionVerbose bool
+	versionJSON    bool
+)
+
+// buildInfo describes the exact build of the running binary
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Example: `  bcopy version          # Version, commit, build date, and Go version
+  bcopy version --json   # The same as JSON, for bug reports and packaging checks`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := currentBuildInfo()
+		if versionJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(info)
+		}
+		fmt.Printf("bcopy version %s\n%s", info.Version, formatBuildInfo(info))
+		return nil
+	},
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print build information as JSON")
+	rootCmd.AddCommand(versionCmd)
+
+	rootCmd.Flags().BoolVar(&versionVerbose, "verbose", false, "With --version, also print commit, build date, and Go version")
+	cobra.AddTemplateFunc("buildDetails", func() string {
+		if !versionVerbose {
+			return ""
+		}
+		return formatBuildInfo(currentBuildInfo())
+	})
+	rootCmd.SetVersionTemplate(`{{with .Name}}{{printf "%s " .}}{{end}}{{printf "version %s" .Version}}
+{{buildDetails}}`)
+}
+
+// currentBuildInfo combines the ldflags metadata with what the Go toolchain
+// embeds, so `go install` builds still report their commit and date
+func currentBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "none":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.Date == "unknown":
+				info.Date = setting.Value
+			}
+		}
+	}
+
+	return info
+}
+
+func formatBuildInfo(info buildInfo) string {
+	return fmt.Sprintf("  commit:   %s\n  built:    %s\n  go:       %s\n  platform: %s\n",
+		info.Commit, info.Date, info.GoVersion, info.Platform)
+}
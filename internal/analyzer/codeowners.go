@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+type ownerRule struct {
+	pattern glob.Glob
+	owners  []string
+}
+
+// CodeOwners holds the parsed rules of a CODEOWNERS file
+type CodeOwners struct {
+	repoRoot string
+	rules    []ownerRule
+}
+
+// LoadCodeOwners reads CODEOWNERS from the locations GitHub looks at
+// (.github/, repo root, docs/). Returns nil if no file exists.
+func LoadCodeOwners(repoRoot string) (*CodeOwners, error) {
+	candidates := []string{
+		filepath.Join(repoRoot, ".github", "CODEOWNERS"),
+		filepath.Join(repoRoot, "CODEOWNERS"),
+		filepath.Join(repoRoot, "docs", "CODEOWNERS"),
+	}
+
+	for _, candidate := range candidates {
+		file, err := os.Open(candidate)
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		co := &CodeOwners{repoRoot: repoRoot}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			g, err := compilePathPattern(fields[0])
+			if err != nil {
+				continue
+			}
+			co.rules = append(co.rules, ownerRule{pattern: g, owners: fields[1:]})
+		}
+		return co, scanner.Err()
+	}
+
+	return nil, nil
+}
+
+// Owners returns the owners of absPath. As on GitHub, the last matching
+// rule wins.
+func (c *CodeOwners) Owners(absPath string) []string {
+	relPath, err := filepath.Rel(c.repoRoot, absPath)
+	if err != nil {
+		return nil
+	}
+	relPath = normalizePath(relPath, false)
+
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.Match(relPath) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// Owns reports whether owner (with or without the leading @) is listed for
+// absPath. Team names match with or without their org prefix.
+func (c *CodeOwners) Owns(owner, absPath string) bool {
+	owner = strings.ToLower(strings.TrimPrefix(owner, "@"))
+
+	for _, o := range c.Owners(absPath) {
+		o = strings.ToLower(strings.TrimPrefix(o, "@"))
+		if o == owner {
+			return true
+		}
+		if _, team, ok := strings.Cut(o, "/"); ok && team == owner {
+			return true
+		}
+	}
+	return false
+}
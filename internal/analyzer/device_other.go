@@ -0,0 +1,11 @@
+//go:build !unix
+
+package analyzer
+
+import "io/fs"
+
+// DeviceID is not available on this platform, so filesystem boundaries are
+// never detected
+func DeviceID(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package analyzer
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// DeviceID returns the device a file lives on, so a walk can tell when it
+// crosses into another filesystem
+func DeviceID(info fs.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+)
+
+// editorConfigValues are the values bcopy understands for the properties
+// it reads; anything else is ignored, as the spec asks
+var editorConfigValues = map[string]map[string]bool{
+	"charset":     {"latin1": true, "utf-8": true, "utf-8-bom": true, "utf-16be": true, "utf-16le": true},
+	"end_of_line": {"lf": true, "cr": true, "crlf": true},
+}
+
+// EditorConfigSettings are the properties of a file that bcopy reads from
+// .editorconfig. Unset properties are "".
+type EditorConfigSettings struct {
+	Charset   string
+	EndOfLine string
+}
+
+type editorConfigSection struct {
+	pattern glob.Glob
+	props   map[string]string
+}
+
+// editorConfigFile is one parsed .editorconfig
+type editorConfigFile struct {
+	root     bool
+	sections []editorConfigSection
+}
+
+// EditorConfig resolves the charset and end_of_line properties that
+// .editorconfig files set for paths. Each directory's file is read once,
+// on first use, so a single EditorConfig can be shared by concurrent
+// readers.
+type EditorConfig struct {
+	mu    sync.Mutex
+	files map[string]*editorConfigFile // by directory, nil where there is none
+}
+
+// NewEditorConfig returns an EditorConfig that has read nothing yet
+func NewEditorConfig() *EditorConfig {
+	return &EditorConfig{files: make(map[string]*editorConfigFile)}
+}
+
+// Settings returns the settings for the file at absPath. The .editorconfig
+// files from its directory up to the first one marked root = true are
+// consulted; nearer files override farther ones and later sections
+// earlier ones, as in editors.
+func (e *EditorConfig) Settings(absPath string) EditorConfigSettings {
+	type level struct {
+		dir  string
+		file *editorConfigFile
+	}
+	var levels []level
+	for dir := filepath.Dir(absPath); ; {
+		if file := e.load(dir); file != nil {
+			levels = append(levels, level{dir: dir, file: file})
+			if file.root {
+				break
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	props := make(map[string]string)
+	for i := len(levels) - 1; i >= 0; i-- {
+		relPath, err := filepath.Rel(levels[i].dir, absPath)
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+		for _, section := range levels[i].file.sections {
+			if !section.pattern.Match(relPath) {
+				continue
+			}
+			for key, value := range section.props {
+				props[key] = value
+			}
+		}
+	}
+
+	var settings EditorConfigSettings
+	if value := props["charset"]; editorConfigValues["charset"][value] {
+		settings.Charset = value
+	}
+	if value := props["end_of_line"]; editorConfigValues["end_of_line"][value] {
+		settings.EndOfLine = value
+	}
+	return settings
+}
+
+// load returns the parsed .editorconfig in dir, reading it on first use
+func (e *EditorConfig) load(dir string) *editorConfigFile {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if file, ok := e.files[dir]; ok {
+		return file
+	}
+	file := parseEditorConfig(filepath.Join(dir, ".editorconfig"))
+	e.files[dir] = file
+	return file
+}
+
+// parseEditorConfig reads the root flag and the sections of the file at
+// path, keeping only the properties in editorConfigValues. Returns nil if
+// the file can't be read. Sections with a glob that doesn't compile are
+// skipped.
+func parseEditorConfig(path string) *editorConfigFile {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	file := &editorConfigFile{}
+	var section *editorConfigSection
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.LastIndex(line, "]")
+			if end < 0 {
+				section = nil
+				continue
+			}
+			g, err := compileEditorConfigGlob(line[1:end])
+			if err != nil {
+				section = nil
+				continue
+			}
+			file.sections = append(file.sections, editorConfigSection{pattern: g, props: make(map[string]string)})
+			section = &file.sections[len(file.sections)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch {
+		case section == nil:
+			if key == "root" {
+				file.root = value == "true"
+			}
+		case key == "charset", key == "end_of_line":
+			// "unset" is kept so it overrides an earlier value, and then
+			// fails editorConfigValues like any unknown value
+			section.props[key] = value
+		}
+	}
+	return file
+}
+
+// compileEditorConfigGlob compiles an EditorConfig section name. A name
+// without a slash matches file names at any depth; one with a slash is
+// relative to the directory of the .editorconfig. Numeric ranges such as
+// {1..3} are expanded into alternatives.
+func compileEditorConfigGlob(pattern string) (glob.Glob, error) {
+	pattern = expandNumericRanges(pattern)
+	if !strings.Contains(pattern, "/") {
+		return glob.Compile("{"+pattern+",**/"+pattern+"}", '/')
+	}
+	return glob.Compile(strings.TrimPrefix(pattern, "/"), '/')
+}
+
+// maxNumericRange bounds the alternatives a numeric range expands into
+const maxNumericRange = 1000
+
+// expandNumericRanges rewrites {n1..n2} as {n1,n1+1,...,n2}. Ranges that
+// are malformed or span more than maxNumericRange numbers are left alone.
+func expandNumericRanges(pattern string) string {
+	var sb strings.Builder
+	for {
+		closing := strings.IndexByte(pattern, '}')
+		if closing < 0 {
+			break
+		}
+		open := strings.LastIndexByte(pattern[:closing], '{')
+		if open < 0 {
+			sb.WriteString(pattern[:closing+1])
+			pattern = pattern[closing+1:]
+			continue
+		}
+
+		from, to, ok := strings.Cut(pattern[open+1:closing], "..")
+		lo, errLo := strconv.Atoi(from)
+		hi, errHi := strconv.Atoi(to)
+		sb.WriteString(pattern[:open])
+		if !ok || errLo != nil || errHi != nil || lo > hi || hi-lo >= maxNumericRange {
+			sb.WriteString(pattern[open : closing+1])
+		} else {
+			numbers := make([]string, 0, hi-lo+1)
+			for n := lo; n <= hi; n++ {
+				numbers = append(numbers, strconv.Itoa(n))
+			}
+			sb.WriteString("{" + strings.Join(numbers, ",") + "}")
+		}
+		pattern = pattern[closing+1:]
+	}
+	sb.WriteString(pattern)
+	return sb.String()
+}
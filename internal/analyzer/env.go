@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// envTemplateSuffixes mark .env files meant to be committed, which hold
+// placeholders rather than secrets
+var envTemplateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// IsEnvFile reports whether path is a dotenv file (.env or .env.*)
+func IsEnvFile(path string) bool {
+	base := filepath.Base(path)
+	return base == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+// IsEnvTemplate reports whether path is a committed dotenv template such as
+// .env.example
+func IsEnvTemplate(path string) bool {
+	if !IsEnvFile(path) {
+		return false
+	}
+	base := filepath.Base(path)
+	for _, suffix := range envTemplateSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	return false
+}
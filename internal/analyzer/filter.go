@@ -3,24 +3,118 @@ package analyzer
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/gobwas/glob"
+	"github.com/nodelike/bcopy/internal/detect"
+	"github.com/nodelike/bcopy/internal/language"
+	"golang.org/x/text/unicode/norm"
 )
 
+// gitDirExclude is applied even when the default excludes are disabled
+const gitDirExclude = `(^|/)\.git($|/)`
+
+// defaultExcludes are the built-in always-exclude patterns
+var defaultExcludes = []string{
+	`(^|/)node_modules($|/)`,
+	`(^|/)venv($|/)`,
+	`(^|/)\.venv($|/)`,
+	`(^|/)__pycache__($|/)`,
+	`(^|/)dist($|/)`,
+	`(^|/)build($|/)`,
+	`(^|/)\.egg-info($|/)`,
+	`(^|/)\.tox($|/)`,
+	`(^|/)coverage($|/)`,
+	`(^|/)\.next($|/)`,
+	`(^|/)vendor($|/)`,
+	`(^|/)bin($|/)`,
+	`(^|/)tmp($|/)`,
+	`\.lock$`,
+	`-lock\.json$`,
+	`-lock\.yaml$`,
+	`Pipfile\.lock$`,
+	`\.gitignore$`,
+	`\.exe$`,
+	`\.so$`,
+	`\.dylib$`,
+	`\.dll$`,
+	`_templ\.go$`,
+	`\.(jpg|jpeg|png|gif|bmp|svg|ico|webp|tiff|tif|psd|raw|heic|avif)$`,
+	`\.pyc$`,
+	`\.pyo$`,
+	`\.pyd$`,
+	`\.egg$`,
+	`(^|/)\.eggs($|/)`,
+	`(^|/)\.pytest_cache($|/)`,
+	`(^|/)\.mypy_cache($|/)`,
+	`\.pb\.go$`,
+	`_gen\.go$`,
+	`\.min\.js$`,
+	`\.bundle\.js$`,
+	`\.eslintcache`,
+	`(^|/)\.nyc_output($|/)`,
+	`(^|/)\.yarn($|/)`,
+	`(^|/)\.npm($|/)`,
+	`(^|/)cypress($|/)`,
+	`(^|/)jest-cache($|/)`,
+	`(^|/)\.terraform($|/)`,
+	`\.tfstate$`,
+	`\.tfstate\.backup$`,
+	`\.terraform\.lock\.hcl$`,
+}
+
+// DefaultExcludes returns a copy of the built-in always-exclude patterns
+func DefaultExcludes() []string {
+	return append([]string(nil), defaultExcludes...)
+}
+
+// RemoveExcludes returns patterns without the entries listed in remove.
+// An entry matches either the exact regex or a bare directory name, so
+// "bin" removes `(^|/)bin($|/)`.
+func RemoveExcludes(patterns []string, remove []string) []string {
+	drop := make(map[string]bool)
+	for _, r := range remove {
+		drop[r] = true
+		drop[`(^|/)`+regexp.QuoteMeta(r)+`($|/)`] = true
+	}
+
+	kept := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if !drop[p] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
 type Filter struct {
 	allowedExts      map[string]bool
-	excludePatterns  []*regexp.Regexp
+	dirMatcher       *matcher // patterns mentioning a path separator; can prune whole directories
+	fileMatcher      *matcher // patterns that only ever match file paths
+	testMatcher      *matcher // testPatterns with excludeTests; empty otherwise
+	dirPatterns      []string
+	filePatterns     []string
+	customPatterns   []string
 	gitignoreGlobs   []glob.Glob
+	ignoreFileGlobs  []glob.Glob // from LoadIgnoreFile; applied even with respectGitignore off
+	bannedGlobs      []glob.Glob // from Ban; applied to entry points too
+	customMatcher    *matcher    // customPatterns alone, for lock files admitted by SetLockfiles
+	lockfiles        map[string]bool
 	respectGitignore bool
 	excludeTests     bool
+	withFixtures     bool
+	ignoreCase       bool
 }
 
-func NewFilter(allowedExts []string, customExcludes []string, respectGitignore bool, excludeTests bool) *Filter {
+// NewFilter builds a filter. alwaysExclude is usually DefaultExcludes(),
+// possibly adjusted by config; .git is excluded regardless.
+func NewFilter(allowedExts []string, alwaysExclude []string, customExcludes []string, respectGitignore bool, excludeTests bool) *Filter {
 	f := &Filter{
 		allowedExts:      make(map[string]bool),
 		respectGitignore: respectGitignore,
@@ -28,13 +122,7 @@ func NewFilter(allowedExts []string, customExcludes []string, respectGitignore b
 	}
 
 	if len(allowedExts) == 0 {
-		defaultExts := []string{
-			".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".vue", ".svelte", ".mjs", ".cjs",
-			".yaml", ".yml", ".json", ".toml", ".md", ".txt", ".sh", ".bash",
-			".c", ".cpp", ".h", ".hpp", ".rs", ".java", ".rb", ".php", ".swift", ".kt",
-			".tf", ".tfvars", ".hcl",
-		}
-		for _, ext := range defaultExts {
+		for _, ext := range language.DefaultExtensions() {
 			f.allowedExts[ext] = true
 		}
 	} else {
@@ -46,78 +134,58 @@ func NewFilter(allowedExts []string, customExcludes []string, respectGitignore b
 		}
 	}
 
-	alwaysExclude := []string{
-		`(^|/)node_modules($|/)`,
-		`(^|/)venv($|/)`,
-		`(^|/)\.venv($|/)`,
-		`(^|/)__pycache__($|/)`,
-		`(^|/)\.git($|/)`,
-		`(^|/)dist($|/)`,
-		`(^|/)build($|/)`,
-		`(^|/)\.egg-info($|/)`,
-		`(^|/)\.tox($|/)`,
-		`(^|/)coverage($|/)`,
-		`(^|/)\.next($|/)`,
-		`(^|/)vendor($|/)`,
-		`(^|/)bin($|/)`,
-		`(^|/)tmp($|/)`,
-		`\.lock$`,
-		`-lock\.json$`,
-		`-lock\.yaml$`,
-		`Pipfile\.lock$`,
-		`\.gitignore$`,
-		`\.exe$`,
-		`\.so$`,
-		`\.dylib$`,
-		`\.dll$`,
-		`_templ\.go$`,
-		`\.(jpg|jpeg|png|gif|bmp|svg|ico|webp|tiff|tif|psd|raw|heic|avif)$`,
-		`\.pyc$`,
-		`\.pyo$`,
-		`\.pyd$`,
-		`\.egg$`,
-		`(^|/)\.eggs($|/)`,
-		`(^|/)\.pytest_cache($|/)`,
-		`(^|/)\.mypy_cache($|/)`,
-		`\.pb\.go$`,
-		`_gen\.go$`,
-		`\.min\.js$`,
-		`\.bundle\.js$`,
-		`\.eslintcache`,
-		`(^|/)\.nyc_output($|/)`,
-		`(^|/)\.yarn($|/)`,
-		`(^|/)\.npm($|/)`,
-		`(^|/)cypress($|/)`,
-		`(^|/)jest-cache($|/)`,
-		`(^|/)\.terraform($|/)`,
-		`\.tfstate$`,
-		`\.tfstate\.backup$`,
-		`\.terraform\.lock\.hcl$`,
-	}
-
-	testPatterns := []string{
-		`_test\.go$`,
-		`(^|/)tests?($|/)`,
-		`\.test\.(js|ts|jsx|tsx)$`,
-		`\.spec\.(js|ts|jsx|tsx)$`,
-	}
-
-	allPatterns := alwaysExclude
-	if f.excludeTests {
-		allPatterns = append(allPatterns, testPatterns...)
-	}
+	allPatterns := append([]string{gitDirExclude}, alwaysExclude...)
 	allPatterns = append(allPatterns, customExcludes...)
 
-	f.excludePatterns = make([]*regexp.Regexp, 0, len(allPatterns))
 	for _, pattern := range allPatterns {
-		if re, err := regexp.Compile(pattern); err == nil {
-			f.excludePatterns = append(f.excludePatterns, re)
+		if strings.Contains(pattern, "/") {
+			f.dirPatterns = append(f.dirPatterns, pattern)
+		} else {
+			f.filePatterns = append(f.filePatterns, pattern)
 		}
 	}
+	f.dirMatcher = newMatcher(f.dirPatterns, false)
+	f.fileMatcher = newMatcher(f.filePatterns, false)
+	f.customPatterns = customExcludes
+	f.customMatcher = newMatcher(f.customPatterns, false)
+	f.testMatcher = f.newTestMatcher()
 
 	return f
 }
 
+// newTestMatcher matches test code when tests are excluded, and nothing
+// otherwise
+func (f *Filter) newTestMatcher() *matcher {
+	if !f.excludeTests {
+		return newMatcher(nil, false)
+	}
+	return newMatcher(testPatterns, f.ignoreCase)
+}
+
+// SetWithFixtures keeps test data (see IsFixture) when tests are excluded:
+// test directories are still entered, and only the test code inside them
+// is excluded
+func (f *Filter) SetWithFixtures(withFixtures bool) {
+	f.withFixtures = withFixtures
+}
+
+// SetIgnoreCase makes exclusion patterns, ignore files and the allowed
+// extensions match regardless of case. Call it before LoadGitignore and
+// LoadIgnoreFile; patterns loaded earlier keep their case.
+func (f *Filter) SetIgnoreCase(ignoreCase bool) {
+	f.ignoreCase = ignoreCase
+	f.dirMatcher = newMatcher(f.dirPatterns, ignoreCase)
+	f.fileMatcher = newMatcher(f.filePatterns, ignoreCase)
+	f.customMatcher = newMatcher(f.customPatterns, ignoreCase)
+	f.testMatcher = f.newTestMatcher()
+
+	exts := make(map[string]bool, len(f.allowedExts))
+	for ext := range f.allowedExts {
+		exts[foldCase(ext, ignoreCase)] = true
+	}
+	f.allowedExts = exts
+}
+
 func (f *Filter) LoadGitignore(repoRoot string) error {
 	if !f.respectGitignore {
 		return nil
@@ -130,64 +198,187 @@ func (f *Filter) LoadGitignore(repoRoot string) error {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	globs, err := readIgnorePatterns(file, f.ignoreCase, gitignoreGlob)
+	f.gitignoreGlobs = append(f.gitignoreGlobs, globs...)
+	return err
+}
+
+// LoadIgnoreFile adds the patterns of an extra ignore file such as
+// .dockerignore or .npmignore. Files named *.dockerignore use Docker's
+// syntax (patterns anchored at the root); anything else is read as
+// gitignore syntax. Patterns are matched against paths relative to the
+// scanned directory, and negations are not supported.
+func (f *Filter) LoadIgnoreFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	compile := gitignoreGlob
+	if strings.HasSuffix(filepath.Base(path), ".dockerignore") {
+		compile = dockerignoreGlob
+	}
+
+	globs, err := readIgnorePatterns(file, f.ignoreCase, compile)
+	f.ignoreFileGlobs = append(f.ignoreFileGlobs, globs...)
+	return err
+}
+
+// IgnorePatterns adds gitignore-style patterns as if they were read from
+// an ignore file passed to LoadIgnoreFile. Invalid patterns are skipped.
+func (f *Filter) IgnorePatterns(patterns []string) error {
+	globs, err := readIgnorePatterns(strings.NewReader(strings.Join(patterns, "\n")), f.ignoreCase, gitignoreGlob)
+	f.ignoreFileGlobs = append(f.ignoreFileGlobs, globs...)
+	return err
+}
+
+// Ban excludes paths matching glob patterns that nothing may select, not
+// even an entry point. Unlike gitignore patterns, these match at any depth
+// whether or not they contain a slash, so secrets/** bans every secrets
+// directory however deep the scanned directory starts. A directory
+// pattern bans everything beneath it.
+func (f *Filter) Ban(patterns []string) error {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(foldCase(norm.NFC.String(pattern), f.ignoreCase), "/")
+		g, err := glob.Compile("{"+pattern+","+pattern+"/**,**/"+pattern+",**/"+pattern+"/**}", '/')
+		if err != nil {
+			return fmt.Errorf("invalid banned path %q: %w", pattern, err)
+		}
+		f.bannedGlobs = append(f.bannedGlobs, g)
+	}
+	return nil
+}
+
+// IsBanned reports whether path matches a pattern passed to Ban
+func (f *Filter) IsBanned(path string) bool {
+	path = normalizePath(path, f.ignoreCase)
+	for _, g := range f.bannedGlobs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// readIgnorePatterns compiles every pattern line of an ignore file,
+// skipping blank lines, comments, and negations
+func readIgnorePatterns(r io.Reader, fold bool, compile func(pattern string) (glob.Glob, error)) ([]glob.Glob, error) {
+	var globs []glob.Glob
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
 			continue
 		}
-
-		pattern := line
-		if strings.HasPrefix(pattern, "!") {
-			continue
+		if g, err := compile(foldCase(norm.NFC.String(line), fold)); err == nil {
+			globs = append(globs, g)
 		}
+	}
+	return globs, scanner.Err()
+}
 
-		if strings.HasSuffix(pattern, "/") {
-			pattern = pattern + "**"
-		}
+// gitignoreGlob compiles a gitignore pattern: unanchored patterns match at
+// any depth and a trailing slash matches everything beneath the directory
+func gitignoreGlob(pattern string) (glob.Glob, error) {
+	if strings.HasSuffix(pattern, "/") {
+		pattern = pattern + "**"
+	}
 
-		if strings.HasPrefix(pattern, "/") {
-			pattern = strings.TrimPrefix(pattern, "/")
-		} else {
-			pattern = "**/" + pattern
-		}
+	if strings.HasPrefix(pattern, "/") {
+		return glob.Compile(strings.TrimPrefix(pattern, "/"), '/')
+	}
+	// **/ needs at least one directory before it, so the root is a case
+	// of its own
+	return glob.Compile("{"+pattern+",**/"+pattern+"}", '/')
+}
 
-		if g, err := glob.Compile(pattern, '/'); err == nil {
-			f.gitignoreGlobs = append(f.gitignoreGlobs, g)
-		}
+// dockerignoreGlob compiles a .dockerignore pattern: patterns are always
+// anchored at the root and match the path itself and everything beneath it
+func dockerignoreGlob(pattern string) (glob.Glob, error) {
+	pattern = strings.Trim(path.Clean("/"+pattern), "/")
+	return glob.Compile("{"+pattern+","+pattern+"/**}", '/')
+}
+
+// compilePathPattern converts a gitignore-style pattern (as used by
+// CODEOWNERS and .gitattributes) into a glob matching the path itself and
+// everything beneath it
+func compilePathPattern(pattern string) (glob.Glob, error) {
+	pattern = norm.NFC.String(pattern)
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	alternatives := []string{pattern, pattern + "/**"}
+	if !anchored {
+		alternatives = append(alternatives, "**/"+pattern, "**/"+pattern+"/**")
 	}
 
-	return scanner.Err()
+	return glob.Compile("{"+strings.Join(alternatives, ",")+"}", '/')
 }
 
-func (f *Filter) ShouldInclude(path string) bool {
-	path = filepath.ToSlash(path)
+// ShouldIncludeDir reports whether the walk should descend into the
+// directory at path. Only directory-level rules apply, so a file pattern
+// like `\.min\.js$` never prunes a directory.
+func (f *Filter) ShouldIncludeDir(path string) bool {
+	path = normalizePath(path, f.ignoreCase)
+	dirPath := path + "/"
 
-	for _, re := range f.excludePatterns {
-		if re.MatchString(path) {
+	for _, g := range f.bannedGlobs {
+		if g.Match(path) || g.Match(dirPath) {
 			return false
 		}
 	}
+	if f.dirMatcher.match(dirPath) {
+		return false
+	}
+	if f.testMatcher.match(dirPath) && !f.withFixtures {
+		return false
+	}
 
 	if f.respectGitignore {
 		for _, g := range f.gitignoreGlobs {
-			if g.Match(path) {
+			if g.Match(path) || g.Match(dirPath) {
 				return false
 			}
 		}
 	}
 
-	ext := filepath.Ext(path)
-	filename := filepath.Base(path)
+	for _, g := range f.ignoreFileGlobs {
+		if g.Match(path) || g.Match(dirPath) {
+			return false
+		}
+	}
+
+	return true
+}
 
-	// Allow common files without extensions
-	commonNoExtFiles := map[string]bool{
-		"Makefile": true, "Dockerfile": true, "Rakefile": true,
-		"Gemfile": true, "Procfile": true, "Vagrantfile": true,
+// SetLockfiles admits files with the given base names, such as go.sum,
+// although the default excludes or the allowed extensions would drop
+// them. Bans, ignore files and custom excludes still apply.
+func (f *Filter) SetLockfiles(names []string) {
+	f.lockfiles = make(map[string]bool, len(names))
+	for _, name := range names {
+		f.lockfiles[name] = true
 	}
+}
+
+func (f *Filter) ShouldInclude(path string) bool {
+	if f.lockfiles[filepath.Base(path)] {
+		if f.IsBanned(path) {
+			return false
+		}
+		path = normalizePath(path, f.ignoreCase)
+		return !f.customMatcher.match(path) && !f.ignored(path)
+	}
+	if f.IsExcluded(path) {
+		return false
+	}
+
+	ext := foldCase(filepath.Ext(path), f.ignoreCase)
 
+	// Allow well-known files without extensions (Makefile, Justfile, ...)
 	if ext == "" {
-		return commonNoExtFiles[filename]
+		return language.IsSpecialFile(path)
 	}
 
 	if !f.allowedExts[ext] {
@@ -197,6 +388,45 @@ func (f *Filter) ShouldInclude(path string) bool {
 	return true
 }
 
+// IsExcluded reports whether path matches an exclusion pattern or
+// .gitignore rule, without regard to the allowed extensions
+func (f *Filter) IsExcluded(path string) bool {
+	if f.IsBanned(path) {
+		return true
+	}
+	path = normalizePath(path, f.ignoreCase)
+
+	if f.dirMatcher.match(path) || f.fileMatcher.match(path) {
+		return true
+	}
+	if f.testMatcher.match(path) && !(f.withFixtures && IsFixture(path)) {
+		return true
+	}
+	return f.ignored(path)
+}
+
+// ignored reports whether a normalized path matches a .gitignore rule or
+// an ignore file pattern
+func (f *Filter) ignored(path string) bool {
+	if f.respectGitignore {
+		for _, g := range f.gitignoreGlobs {
+			if g.Match(path) {
+				return true
+			}
+		}
+	}
+
+	for _, g := range f.ignoreFileGlobs {
+		if g.Match(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CountLines counts the lines of a text file, decoding UTF-16, and returns
+// 0 for binary files
 func CountLines(filePath string) (int, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -213,8 +443,11 @@ func CountLines(filePath string) (int, error) {
 		n, err := file.Read(buf)
 		if n > 0 {
 			if firstChunk {
-				if bytes.IndexByte(buf[:n], 0) != -1 {
+				switch kind := detect.Sniff(filePath, buf[:min(n, detect.SniffSize)]); kind {
+				case detect.Binary:
 					return 0, nil
+				case detect.UTF16LE, detect.UTF16BE:
+					return countUTF16Lines(filePath, kind)
 				}
 				firstChunk = false
 			}
@@ -231,3 +464,16 @@ func CountLines(filePath string) (int, error) {
 
 	return count, nil
 }
+
+// countUTF16Lines counts the lines of a UTF-16 file by decoding it whole
+func countUTF16Lines(filePath string, kind detect.Kind) (int, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	text, err := detect.Decode(kind, data)
+	if err != nil {
+		return 0, err
+	}
+	return strings.Count(text, "\n"), nil
+}
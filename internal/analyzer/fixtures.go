@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"path"
+	"strings"
+)
+
+// testPatterns are the exclusions added by --exclude-tests
+var testPatterns = []string{
+	`_test\.go$`,
+	`(^|/)tests?($|/)`,
+	`\.test\.(js|ts|jsx|tsx)$`,
+	`\.spec\.(js|ts|jsx|tsx)$`,
+}
+
+var testFileMatcher = newMatcher(testPatterns, false)
+
+// fixtureDirs hold data read by tests rather than test code
+var fixtureDirs = map[string]bool{
+	"testdata":     true,
+	"fixtures":     true,
+	"__fixtures__": true,
+	"golden":       true,
+	"goldens":      true,
+}
+
+// IsTestPath reports whether path is test code or lies in a test directory,
+// as matched by --exclude-tests
+func IsTestPath(relPath string) bool {
+	return testFileMatcher.match(normalizePath(relPath, false))
+}
+
+// IsFixture reports whether relPath is test data: anything under testdata/,
+// fixtures/, __fixtures__/ or golden/, or a *.golden file
+func IsFixture(relPath string) bool {
+	_, ok := fixtureRoot(relPath)
+	return ok || IsGolden(relPath)
+}
+
+// IsGolden reports whether relPath is a golden file, the expected output a
+// test compares against: *.golden or anything under golden/ or goldens/
+func IsGolden(relPath string) bool {
+	p := normalizePath(relPath, false)
+	if strings.HasSuffix(p, ".golden") {
+		return true
+	}
+	for _, part := range strings.Split(path.Dir(p), "/") {
+		if part == "golden" || part == "goldens" {
+			return true
+		}
+	}
+	return false
+}
+
+// FixtureOwner returns the slash-separated directory whose tests use the
+// fixture at relPath: the parent of its outermost fixture directory, or its
+// own directory for a *.golden file outside one. "." is the root.
+func FixtureOwner(relPath string) string {
+	if owner, ok := fixtureRoot(relPath); ok {
+		return owner
+	}
+	return path.Dir(normalizePath(relPath, false))
+}
+
+// fixtureRoot returns the directory containing the outermost fixture
+// directory of relPath
+func fixtureRoot(relPath string) (string, bool) {
+	parts := strings.Split(path.Dir(normalizePath(relPath, false)), "/")
+	for i, part := range parts {
+		if fixtureDirs[part] {
+			if i == 0 {
+				return ".", true
+			}
+			return strings.Join(parts[:i], "/"), true
+		}
+	}
+	return "", false
+}
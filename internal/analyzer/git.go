@@ -3,8 +3,11 @@ package analyzer
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 )
 
 func IsGitRepo(path string) bool {
@@ -47,3 +50,247 @@ func findGitRoot(startPath string) (string, error) {
 		currentPath = parentPath
 	}
 }
+
+// ChangedSince returns the absolute paths of files touched by commits made
+// after since, walking history from HEAD
+func ChangedSince(repoRoot string, since time.Time) (map[string]bool, error) {
+	repo, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash(), Since: &since})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+
+	changed := make(map[string]bool)
+	err = commits.ForEach(func(c *object.Commit) error {
+		changes, err := commitChanges(c)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if change.To.Name != "" {
+				changed[filepath.Join(repoRoot, filepath.FromSlash(change.To.Name))] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// Head returns the current branch name (empty when HEAD is detached) and
+// commit hash of the repository at repoRoot
+func Head(repoRoot string) (branch, commit string, err error) {
+	repo, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return "", "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", "", err
+	}
+
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	return branch, head.Hash().String(), nil
+}
+
+// CommitCounts returns how many of the last limit commits reachable from
+// HEAD touched each file, keyed by absolute path
+func CommitCounts(repoRoot string, limit int) (map[string]int, error) {
+	repo, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+
+	counts := make(map[string]int)
+	seen := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if seen >= limit {
+			return storer.ErrStop
+		}
+		seen++
+
+		changes, err := commitChanges(c)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if change.To.Name != "" {
+				counts[filepath.Join(repoRoot, filepath.FromSlash(change.To.Name))]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// BlameLine is the last author of one line of a committed file
+type BlameLine struct {
+	Author string
+	Text   string
+}
+
+// Blame returns the last author of every line of relPath (slash-separated,
+// relative to repoRoot) as of HEAD. Uncommitted changes are not reflected.
+func Blame(repoRoot, relPath string) ([]BlameLine, error) {
+	repo, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	blame, err := git.Blame(commit, relPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]BlameLine, len(blame.Lines))
+	for i, line := range blame.Lines {
+		author := line.AuthorName
+		if author == "" {
+			author = line.Author
+		}
+		lines[i] = BlameLine{Author: author, Text: line.Text}
+	}
+	return lines, nil
+}
+
+// historyScanLimit bounds how many commits RecentCommits reads looking for
+// ones that touch the requested paths
+const historyScanLimit = 5000
+
+// Commit is one entry of the history returned by RecentCommits
+type Commit struct {
+	Hash    string
+	Author  string
+	When    time.Time
+	Message string
+}
+
+// RecentCommits returns up to limit commits reachable from HEAD, newest
+// first. With paths (absolute), only commits touching one of them count.
+func RecentCommits(repoRoot string, limit int, paths map[string]bool) ([]Commit, error) {
+	repo, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+
+	var recent []Commit
+	seen := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if len(recent) >= limit || seen >= historyScanLimit {
+			return storer.ErrStop
+		}
+		seen++
+
+		if paths != nil {
+			touched, err := touchesAny(repoRoot, c, paths)
+			if err != nil {
+				return err
+			}
+			if !touched {
+				return nil
+			}
+		}
+
+		recent = append(recent, Commit{
+			Hash:    c.Hash.String(),
+			Author:  c.Author.Name,
+			When:    c.Author.When,
+			Message: c.Message,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recent, nil
+}
+
+// touchesAny reports whether commit c changed one of paths
+func touchesAny(repoRoot string, c *object.Commit, paths map[string]bool) (bool, error) {
+	changes, err := commitChanges(c)
+	if err != nil {
+		return false, err
+	}
+
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && paths[filepath.Join(repoRoot, filepath.FromSlash(name))] {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// commitChanges returns the changes commit c made: its diff against its
+// first parent, or against the empty tree for a root commit
+func commitChanges(c *object.Commit) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if parent, err := c.Parent(0); err == nil {
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	return object.DiffTree(parentTree, tree)
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFiles writes files (relative path to content) into the worktree
+// and commits them
+func commitFiles(t *testing.T, repo *git.Repository, root string, files map[string]string) {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	if _, err := wt.Commit("commit", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommitChanges(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The root commit diffs against the empty tree, the next against it
+	commitFiles(t, repo, root, map[string]string{"a.go": "package a\n", "b.go": "package b\n"})
+	commitFiles(t, repo, root, map[string]string{"a.go": "package a // changed\n"})
+
+	counts, err := CommitCounts(root, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := filepath.Join(root, "a.go"), filepath.Join(root, "b.go")
+	if counts[a] != 2 || counts[b] != 1 || len(counts) != 2 {
+		t.Errorf("CommitCounts = %v, want a.go 2 and b.go 1", counts)
+	}
+
+	changed, err := ChangedSince(root, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed[a] || !changed[b] || len(changed) != 2 {
+		t.Errorf("ChangedSince = %v, want a.go and b.go", changed)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]bool{a: true, b: false} {
+		got, err := touchesAny(root, last, map[string]bool{path: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("touchesAny(%s) = %v, want %v", filepath.Base(path), got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+type attributeRule struct {
+	pattern glob.Glob
+	attrs   map[string]string
+}
+
+// GitAttributes holds the linguist overrides from a repository's
+// .gitattributes file
+type GitAttributes struct {
+	repoRoot string
+	rules    []attributeRule
+}
+
+// linguistFences maps GitHub linguist language names to fence languages
+// where lowercasing the name isn't enough
+var linguistFences = map[string]string{
+	"c++":             "cpp",
+	"c#":              "csharp",
+	"f#":              "fsharp",
+	"objective-c":     "objective-c",
+	"shell":           "bash",
+	"emacs lisp":      "elisp",
+	"vim script":      "vim",
+	"protocol buffer": "protobuf",
+}
+
+// LoadGitAttributes reads the linguist-* attributes from .gitattributes in
+// repoRoot. Returns nil if the file doesn't exist.
+func LoadGitAttributes(repoRoot string) (*GitAttributes, error) {
+	file, err := os.Open(filepath.Join(repoRoot, ".gitattributes"))
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	ga := &GitAttributes{repoRoot: repoRoot}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		attrs := make(map[string]string)
+		for _, field := range fields[1:] {
+			switch {
+			case strings.HasPrefix(field, "-"):
+				attrs[field[1:]] = "false"
+			case strings.HasPrefix(field, "!"):
+				attrs[field[1:]] = ""
+			case strings.Contains(field, "="):
+				key, value, _ := strings.Cut(field, "=")
+				attrs[key] = value
+			default:
+				attrs[field] = "true"
+			}
+		}
+
+		for key := range attrs {
+			if !strings.HasPrefix(key, "linguist-") {
+				delete(attrs, key)
+			}
+		}
+		if len(attrs) == 0 {
+			continue
+		}
+
+		g, err := compilePathPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		ga.rules = append(ga.rules, attributeRule{pattern: g, attrs: attrs})
+	}
+
+	return ga, scanner.Err()
+}
+
+// attr returns the value of name for absPath, with later rules overriding
+// earlier ones as in git
+func (g *GitAttributes) attr(absPath, name string) string {
+	relPath, err := filepath.Rel(g.repoRoot, absPath)
+	if err != nil {
+		return ""
+	}
+	relPath = normalizePath(relPath, false)
+
+	for i := len(g.rules) - 1; i >= 0; i-- {
+		value, ok := g.rules[i].attrs[name]
+		if ok && g.rules[i].pattern.Match(relPath) {
+			return value
+		}
+	}
+	return ""
+}
+
+// IsExcluded reports whether absPath is marked linguist-generated or
+// linguist-vendored
+func (g *GitAttributes) IsExcluded(absPath string) bool {
+	return isTrue(g.attr(absPath, "linguist-generated")) || isTrue(g.attr(absPath, "linguist-vendored"))
+}
+
+// Language returns the fence language for absPath from linguist-language,
+// or "" when no override is set
+func (g *GitAttributes) Language(absPath string) string {
+	lang := strings.ToLower(g.attr(absPath, "linguist-language"))
+	if lang == "" {
+		return ""
+	}
+	if fence, ok := linguistFences[lang]; ok {
+		return fence
+	}
+	return strings.ReplaceAll(lang, " ", "-")
+}
+
+func isTrue(value string) bool {
+	return value == "true" || value == "set"
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	componentPattern = regexp.MustCompile(`^\(\^\|/\)((?:[^\\()|^$]|\\[^A-Za-z0-9])+)\(\$\|/\)$`)
+	suffixPattern    = regexp.MustCompile(`^((?:[^\\()|^$.*+?\[\]{}]|\\[^A-Za-z0-9])+)\$$`)
+	metaChars        = regexp.MustCompile(`[.*+?()|\[\]{}^$]`)
+)
+
+// matcher evaluates a set of exclusion regexes in a single pass. The common
+// shapes of the built-in list are answered without running a regex:
+// `(^|/)name($|/)` becomes a path-component lookup and `\.ext$` a suffix
+// check. Everything else is folded into one alternation.
+//
+// Patterns are normalized to NFC, so callers must pass paths through
+// normalizePath as well. With fold set, literals are lowercased and the
+// alternation is case-insensitive; paths must then be lowercased too.
+type matcher struct {
+	components map[string]bool
+	suffixes   []string
+	re         *regexp.Regexp
+}
+
+// newMatcher compiles patterns, silently dropping any that are invalid
+func newMatcher(patterns []string, fold bool) *matcher {
+	m := &matcher{components: make(map[string]bool)}
+
+	var rest []string
+	for _, pattern := range patterns {
+		pattern = norm.NFC.String(pattern)
+		if _, err := regexp.Compile(pattern); err != nil {
+			continue
+		}
+
+		if sub := componentPattern.FindStringSubmatch(pattern); sub != nil {
+			if name, ok := unescapeLiteral(sub[1]); ok {
+				m.components[foldCase(name, fold)] = true
+				continue
+			}
+		}
+		if sub := suffixPattern.FindStringSubmatch(pattern); sub != nil {
+			if suffix, ok := unescapeLiteral(sub[1]); ok {
+				m.suffixes = append(m.suffixes, foldCase(suffix, fold))
+				continue
+			}
+		}
+		rest = append(rest, "(?:"+pattern+")")
+	}
+
+	if len(rest) > 0 {
+		expr := strings.Join(rest, "|")
+		if fold {
+			expr = "(?i)" + expr
+		}
+		m.re = regexp.MustCompile(expr)
+	}
+	return m
+}
+
+// normalizePath converts path to slash form in NFC. macOS filesystems
+// hand out decomposed (NFD) names, which would otherwise never match a
+// pattern typed as precomposed text.
+func normalizePath(path string, fold bool) string {
+	return foldCase(norm.NFC.String(filepath.ToSlash(path)), fold)
+}
+
+func foldCase(s string, fold bool) string {
+	if fold {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// unescapeLiteral turns an escaped regex literal back into plain text,
+// reporting false if it contains unescaped metacharacters
+func unescapeLiteral(s string) (string, bool) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			sb.WriteByte(s[i])
+			continue
+		}
+		if metaChars.MatchString(s[i : i+1]) {
+			return "", false
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String(), true
+}
+
+func (m *matcher) match(path string) bool {
+	for _, suffix := range m.suffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+
+	if len(m.components) > 0 {
+		for _, part := range strings.Split(path, "/") {
+			if m.components[part] {
+				return true
+			}
+		}
+	}
+
+	return m.re != nil && m.re.MatchString(path)
+}
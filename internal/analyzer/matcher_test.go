@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// regexLoop is the per-pattern loop the matcher replaced
+type regexLoop []*regexp.Regexp
+
+func newRegexLoop(patterns []string, fold bool) regexLoop {
+	var loop regexLoop
+	for _, pattern := range patterns {
+		if fold {
+			pattern = "(?i)" + pattern
+		}
+		if re, err := regexp.Compile(pattern); err == nil {
+			loop = append(loop, re)
+		}
+	}
+	return loop
+}
+
+func (l regexLoop) match(path string) bool {
+	for _, re := range l {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+var matcherPaths = []string{
+	"main.go",
+	"node_modules/react/index.js",
+	"web/node_modules/",
+	"web/node_modules_cache/index.js",
+	"src/build/output.txt",
+	"src/build",
+	"src/builder.go",
+	"rebuild/main.go",
+	"cmd/bin/",
+	"cmd/binary.go",
+	"pkg/tmp",
+	".venv/lib/site.py",
+	"venv.py",
+	"pkg/foo.egg-info/PKG-INFO",
+	"pkg/fooegg-info/PKG-INFO",
+	"yarn.lock",
+	"package-lock.json",
+	"pnpm-lock.yaml",
+	"lock.json",
+	"Pipfile.lock",
+	".gitignore",
+	"docs/gitignore.md",
+	"app.exe",
+	"lib/libfoo.so",
+	"lib/libfoo.so.1",
+	"view_templ.go",
+	"assets/logo.png",
+	"assets/logo.PNG",
+	"assets/logo.png.txt",
+	"api/service.pb.go",
+	"api/servicepb.go",
+	"static/app.min.js",
+	"static/app.bundle.js",
+	"static/appmin.js",
+	".eslintcache",
+	".eslintcache-backup",
+	"infra/.terraform/modules/x.tf",
+	"infra/.terraform.lock.hcl",
+	"infra/prod.tfstate",
+	"infra/prod.tfstate.backup",
+	"Node_Modules/x.js",
+	"Build/out.txt",
+}
+
+// customPatterns mix the shapes the matcher shortcuts with ones it folds
+// into the alternation
+var customPatterns = []string{
+	`^docs/.*\.md$`,
+	`(^|/)generated($|/)`,
+	`\.snap$`,
+	`(^|/)fix.ures($|/)`,
+	`[invalid`,
+	`_test\.go$`,
+}
+
+func TestMatcherAgreesWithRegexLoop(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		fold     bool
+	}{
+		{"defaults", defaultExcludes, false},
+		{"defaults folded", defaultExcludes, true},
+		{"custom", customPatterns, false},
+		{"defaults and custom folded", append(DefaultExcludes(), customPatterns...), true},
+	}
+	paths := append(matcherPaths,
+		"docs/guide.md",
+		"docs/api/ref.md",
+		"src/docs/guide.md",
+		"generated/x.go",
+		"ui/__snapshots__/a.snap",
+		"fixtures/a.json",
+		"fixxures/a.json",
+		"foo_test.go",
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMatcher(tt.patterns, tt.fold)
+			loop := newRegexLoop(tt.patterns, tt.fold)
+			for _, path := range paths {
+				got := m.match(normalizePath(path, tt.fold))
+				want := loop.match(path)
+				if got != want {
+					t.Errorf("match(%q) = %v, regex loop says %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMatcherShortcuts(t *testing.T) {
+	m := newMatcher(defaultExcludes, false)
+	if m.re == nil {
+		t.Fatal("expected the character-class pattern to stay a regex")
+	}
+	if !m.components["node_modules"] || !m.components[".venv"] {
+		t.Errorf("components = %v, want node_modules and .venv", m.components)
+	}
+	for _, suffix := range []string{".lock", "-lock.json", ".pb.go"} {
+		found := false
+		for _, s := range m.suffixes {
+			found = found || s == suffix
+		}
+		if !found {
+			t.Errorf("suffixes = %v, want %q", m.suffixes, suffix)
+		}
+	}
+}
+
+// benchmarkPaths builds a repository-shaped path list, mostly files that
+// no default pattern excludes
+func benchmarkPaths(n int) []string {
+	exts := []string{".go", ".ts", ".py", ".md", ".json", ".min.js", ".png"}
+	dirs := []string{"src", "internal/collector", "web/components", "node_modules/pkg", "docs"}
+	paths := make([]string, n)
+	for i := range paths {
+		dir := dirs[i%len(dirs)]
+		paths[i] = fmt.Sprintf("%s/sub%d/file%d%s", dir, i%37, i, exts[i%len(exts)])
+	}
+	return paths
+}
+
+func BenchmarkExcludes(b *testing.B) {
+	paths := benchmarkPaths(10000)
+
+	b.Run("matcher", func(b *testing.B) {
+		m := newMatcher(defaultExcludes, false)
+		b.ResetTimer()
+		for range b.N {
+			for _, path := range paths {
+				m.match(path)
+			}
+		}
+	})
+	b.Run("regex loop", func(b *testing.B) {
+		loop := newRegexLoop(defaultExcludes, false)
+		b.ResetTimer()
+		for range b.N {
+			for _, path := range paths {
+				loop.match(path)
+			}
+		}
+	})
+	b.Run("matcher folded", func(b *testing.B) {
+		m := newMatcher(defaultExcludes, true)
+		b.ResetTimer()
+		for range b.N {
+			for _, path := range paths {
+				m.match(strings.ToLower(path))
+			}
+		}
+	})
+}
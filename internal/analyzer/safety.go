@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -68,23 +69,106 @@ func ValidatePath(path string) error {
 	return nil
 }
 
-func ShouldWarnLargeDirectory(path string) (bool, string) {
-	absPath, err := filepath.Abs(path)
+// ResolveRoot returns the absolute path of root with every symlink in it
+// resolved. Relative paths, .gitignore lookup, and CODEOWNERS matching all
+// compare paths against the root, so it must be resolved once up front:
+// with ~/code linked to a network mount, walking up from the link never
+// finds the repository the files actually live in.
+func ResolveRoot(root string) (string, error) {
+	absPath, err := filepath.Abs(root)
 	if err != nil {
-		return false, ""
+		return "", fmt.Errorf("failed to resolve path: %w", err)
 	}
 
-	homeDir, err := os.UserHomeDir()
+	resolved, err := filepath.EvalSymlinks(absPath)
 	if err != nil {
-		return false, ""
+		return "", fmt.Errorf("failed to resolve path: %w", err)
 	}
+	return resolved, nil
+}
 
-	if strings.HasPrefix(absPath, homeDir) {
+// ShouldWarnLargeDirectory pre-scans path and reports whether it holds more
+// than maxFiles files, with the directory's name and whether it is a
+// top-level directory in the user's home folder. Only directories accepted
+// by includeDir are entered, so pruned trees like node_modules don't count.
+// The scan stops as soon as the limit is passed. maxFiles <= 0 disables the
+// check.
+func ShouldWarnLargeDirectory(path string, maxFiles int, includeDir func(relPath string) bool) (bool, string, bool) {
+	if maxFiles <= 0 {
+		return false, "", false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, "", false
+	}
+
+	if count := CountFiles(absPath, maxFiles, includeDir); count <= maxFiles {
+		return false, "", false
+	}
+
+	homeTopLevel := false
+	if homeDir, err := os.UserHomeDir(); err == nil && strings.HasPrefix(absPath, homeDir) {
 		relPath, err := filepath.Rel(homeDir, absPath)
-		if err == nil && !strings.Contains(relPath, string(os.PathSeparator)) {
-			return true, fmt.Sprintf("Warning: Analyzing a top-level directory in your home folder (%s). This may take a while.", filepath.Base(absPath))
+		homeTopLevel = err == nil && !strings.Contains(relPath, string(os.PathSeparator))
+	}
+
+	return true, filepath.Base(absPath), homeTopLevel
+}
+
+// OnFileSystem returns a check reporting whether a path is on the same
+// filesystem as root. Paths whose device cannot be determined pass.
+func OnFileSystem(root string) func(path string) bool {
+	info, err := os.Stat(root)
+	if err != nil {
+		return func(string) bool { return true }
+	}
+	rootDev, ok := DeviceID(info)
+	if !ok {
+		return func(string) bool { return true }
+	}
+
+	return func(path string) bool {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return true
 		}
+		dev, ok := DeviceID(info)
+		return !ok || dev == rootDev
 	}
+}
+
+// CountFiles counts the regular files under root, entering only directories
+// accepted by includeDir (nil accepts all). It stops counting once the count
+// exceeds limit.
+func CountFiles(root string, limit int, includeDir func(relPath string) bool) int {
+	count := 0
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
 
-	return false, ""
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			relPath, err := filepath.Rel(root, path)
+			if err != nil || (includeDir != nil && !includeDir(relPath)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			count++
+			if count > limit {
+				return fs.SkipAll
+			}
+		}
+		return nil
+	})
+	return count
 }
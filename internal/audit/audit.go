@@ -0,0 +1,84 @@
+// Package audit appends a record of every run to an audit log, so security
+// teams can review what code left the machine. Records hold a hash of the
+// path list rather than the paths or contents.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Syslog is the audit log destination that sends records to the system
+// log instead of a file
+const Syslog = "syslog"
+
+// Record describes one run
+type Record struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user"`
+	Host string    `json:"host"`
+	// Root is the collected directory, or where a payload that wasn't
+	// collected by this command came from, such as a slot or payload file
+	Root string `json:"root"`
+	// PathsHash is the SHA-256 of the sorted, newline-separated relative
+	// paths of the selected files
+	PathsHash    string   `json:"paths_sha256"`
+	FileCount    int      `json:"files"`
+	Bytes        int64    `json:"bytes"`
+	Tokens       int64    `json:"tokens"`
+	Destinations []string `json:"destinations"`
+}
+
+// HashPaths returns the PathsHash of a path list
+func HashPaths(paths []string) string {
+	sorted := make([]string, len(paths))
+	for i, p := range paths {
+		sorted[i] = filepath.ToSlash(p)
+	}
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// CurrentUser names the user running bcopy
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// Write appends rec as one JSON line to the file at dest, or sends it to
+// the system log when dest is Syslog
+func Write(dest string, rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if dest == Syslog {
+		return writeSyslog(string(line))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dest, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package audit
+
+import "errors"
+
+// writeSyslog fails: there is no system log to send records to
+func writeSyslog(string) error {
+	return errors.New("syslog is not available on this platform; set audit-log to a file")
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !plan9
+
+package audit
+
+import "log/syslog"
+
+// writeSyslog sends a record to the local system log as user-level info
+func writeSyslog(line string) error {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "bcopy")
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return w.Info(line)
+}
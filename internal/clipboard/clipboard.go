@@ -1,9 +1,150 @@
 package clipboard
 
 import (
-	"github.com/atotto/clipboard"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 )
 
-func Copy(content string) error {
-	return clipboard.WriteAll(content)
+// systemBackend names the platform clipboard API used when no command-line
+// tool is involved
+const systemBackend = "system"
+
+// Target selects what Copy writes to
+type Target int
+
+const (
+	// Clipboard is the regular clipboard (Ctrl-V)
+	Clipboard Target = 1 << iota
+	// Primary is the X11 primary selection (middle-click paste), also
+	// offered by Wayland compositors
+	Primary
+)
+
+// ErrNoPrimary is returned when only the primary selection is requested
+// and no tool that writes it is found
+var ErrNoPrimary = errors.New("the primary selection needs xclip, xsel, or wl-clipboard on X11 or Wayland")
+
+// Copy puts content on the targets and returns the name of the backend
+// that did it. Primary is skipped where it doesn't exist, unless it is the
+// only target. Copy gives up when ctx is done: command-line tools are
+// killed, while the platform API, which can't be interrupted, is abandoned.
+func Copy(ctx context.Context, content string, targets Target) (backend string, err error) {
+	if targets&Clipboard != 0 {
+		if backend, err = copyTo(ctx, content, false); err != nil {
+			return backend, err
+		}
+	}
+	if targets&Primary != 0 {
+		primary, err := copyTo(ctx, content, true)
+		switch {
+		case errors.Is(err, ErrNoPrimary) && targets&Clipboard != 0:
+			slog.Debug("primary selection skipped", "error", err)
+		case err != nil:
+			return primary, err
+		case backend == "":
+			backend = primary
+		}
+	}
+	return backend, nil
+}
+
+// copyTo writes content to the clipboard, or to the primary selection
+func copyTo(ctx context.Context, content string, primary bool) (string, error) {
+	cmd := copyCommand(ctx, primary)
+	if cmd == nil {
+		if primary {
+			return "", ErrNoPrimary
+		}
+		done := make(chan error, 1)
+		go func() { done <- writeSystem(content) }()
+		select {
+		case err := <-done:
+			return systemBackend, err
+		case <-ctx.Done():
+			return systemBackend, ctx.Err()
+		}
+	}
+
+	backend := cmd.Args[0]
+	// Output is not captured: xclip and xsel fork to keep serving the
+	// selection, and a captured pipe would keep Run waiting on them
+	if backend == "clip.exe" {
+		cmd.Stdin = bytes.NewReader(wslInput(content))
+	} else {
+		cmd.Stdin = strings.NewReader(content)
+	}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return backend, ctx.Err()
+		}
+		return backend, fmt.Errorf("%s: %w", backend, err)
+	}
+	return backend, nil
+}
+
+// copyCommand returns the clipboard tool for this platform, or nil to use
+// the platform API. Under WSL the Windows clipboard is written with
+// clip.exe. Only X11 and Wayland tools have a primary selection.
+func copyCommand(ctx context.Context, primary bool) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		if primary {
+			return nil
+		}
+		return exec.CommandContext(ctx, "pbcopy")
+	case "windows", "plan9":
+		return nil
+	}
+
+	selection := "clipboard"
+	if primary {
+		selection = "primary"
+	}
+	switch {
+	case isWSL() && !primary && hasCommand("clip.exe"):
+		return exec.CommandContext(ctx, "clip.exe")
+	case os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy"):
+		if primary {
+			return exec.CommandContext(ctx, "wl-copy", "--primary")
+		}
+		return exec.CommandContext(ctx, "wl-copy")
+	case hasCommand("xclip"):
+		return exec.CommandContext(ctx, "xclip", "-in", "-selection", selection)
+	case hasCommand("xsel"):
+		return exec.CommandContext(ctx, "xsel", "--input", "--"+selection)
+	case hasCommand("termux-clipboard-set") && !primary:
+		return exec.CommandContext(ctx, "termux-clipboard-set")
+	}
+	return nil
+}
+
+// Verify reads the clipboard back and reports whether it still holds
+// content, comparing SHA-256 hashes. Line endings the platform converted to
+// CRLF don't count as a change. size is the length of what was read.
+func Verify(content string) (ok bool, size int, err error) {
+	got, err := Paste()
+	if err != nil {
+		return false, 0, err
+	}
+	if sha256.Sum256([]byte(got)) == sha256.Sum256([]byte(content)) {
+		return true, len(got), nil
+	}
+	normalized := strings.ReplaceAll(got, "\r\n", "\n")
+	return sha256.Sum256([]byte(normalized)) == sha256.Sum256([]byte(content)), len(got), nil
+}
+
+// Paste returns the text currently on the clipboard
+func Paste() (string, error) {
+	if isWSL() && hasCommand("powershell.exe") {
+		return wslPaste()
+	}
+	return readSystem()
 }
@@ -0,0 +1,73 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrNoImageSupport is returned when no tool for copying images is found
+var ErrNoImageSupport = errors.New("no clipboard tool for images found (install wl-clipboard or xclip)")
+
+// CopyImage puts a PNG image on the clipboard. It uses osascript on macOS,
+// PowerShell on Windows, and wl-copy or xclip elsewhere.
+func CopyImage(png []byte) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return withTempFile(png, func(path string) *exec.Cmd {
+			return exec.Command("osascript", "-e",
+				fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class PNGf»)`, path))
+		})
+	case "windows":
+		return withTempFile(png, func(path string) *exec.Cmd {
+			return exec.Command("powershell", "-NoProfile", "-Command",
+				fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; Add-Type -AssemblyName System.Drawing; [Windows.Forms.Clipboard]::SetImage([Drawing.Image]::FromFile('%s'))`, path))
+		})
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy"):
+		cmd = exec.Command("wl-copy", "--type", "image/png")
+	case hasCommand("xclip"):
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-i")
+	default:
+		return ErrNoImageSupport
+	}
+	cmd.Stdin = bytes.NewReader(png)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Path, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// withTempFile writes png to a temporary file for tools that only read
+// images from disk
+func withTempFile(png []byte, command func(path string) *exec.Cmd) error {
+	f, err := os.CreateTemp("", "bcopy-snap-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(png); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	cmd := command(f.Name())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Path, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
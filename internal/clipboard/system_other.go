@@ -0,0 +1,16 @@
+//go:build !windows
+
+package clipboard
+
+import "github.com/atotto/clipboard"
+
+// writeSystem puts text on the clipboard through whatever the platform
+// offers when no tool was picked by copyCommand
+func writeSystem(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// readSystem returns the text on the clipboard
+func readSystem() (string, error) {
+	return clipboard.ReadAll()
+}
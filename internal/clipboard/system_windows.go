@@ -0,0 +1,132 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+
+	// openTimeout is how long to wait for another program to release the
+	// clipboard
+	openTimeout = time.Second
+)
+
+var (
+	user32           = syscall.NewLazyDLL("user32.dll")
+	openClipboard    = user32.NewProc("OpenClipboard")
+	closeClipboard   = user32.NewProc("CloseClipboard")
+	emptyClipboard   = user32.NewProc("EmptyClipboard")
+	getClipboardData = user32.NewProc("GetClipboardData")
+	setClipboardData = user32.NewProc("SetClipboardData")
+
+	kernel32     = syscall.NewLazyDLL("kernel32.dll")
+	globalAlloc  = kernel32.NewProc("GlobalAlloc")
+	globalFree   = kernel32.NewProc("GlobalFree")
+	globalLock   = kernel32.NewProc("GlobalLock")
+	globalUnlock = kernel32.NewProc("GlobalUnlock")
+	globalSize   = kernel32.NewProc("GlobalSize")
+)
+
+// writeSystem puts text on the clipboard as CF_UNICODETEXT through the
+// Win32 API. The text is converted to NUL-terminated UTF-16 (a NUL inside
+// it ends the text for readers) and copied into global memory allocated
+// for exactly its size, so payloads are limited by memory alone.
+func writeSystem(text string) error {
+	data := append(utf16.Encode([]rune(text)), 0)
+	size := uintptr(len(data)) * 2
+
+	// The clipboard belongs to the thread that opened it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := openWithRetry(); err != nil {
+		return err
+	}
+	defer closeClipboard.Call()
+
+	if r, _, err := emptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("emptying the clipboard: %w", err)
+	}
+
+	h, _, err := globalAlloc.Call(gmemMoveable, size)
+	if h == 0 {
+		return fmt.Errorf("allocating %d bytes: %w", size, err)
+	}
+	p, _, err := globalLock.Call(h)
+	if p == 0 {
+		globalFree.Call(h)
+		return fmt.Errorf("locking clipboard memory: %w", err)
+	}
+	copy(lockedUTF16(p, len(data)), data)
+	globalUnlock.Call(h)
+
+	if r, _, err := setClipboardData.Call(cfUnicodeText, h); r == 0 {
+		globalFree.Call(h)
+		return fmt.Errorf("setting clipboard data: %w", err)
+	}
+	// The system owns the memory from here on
+	return nil
+}
+
+// readSystem returns the CF_UNICODETEXT content of the clipboard, reading
+// no further than the size of its memory block
+func readSystem() (string, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := openWithRetry(); err != nil {
+		return "", err
+	}
+	defer closeClipboard.Call()
+
+	h, _, err := getClipboardData.Call(cfUnicodeText)
+	if h == 0 {
+		return "", fmt.Errorf("no text on the clipboard: %w", err)
+	}
+	p, _, err := globalLock.Call(h)
+	if p == 0 {
+		return "", fmt.Errorf("locking clipboard memory: %w", err)
+	}
+	defer globalUnlock.Call(h)
+
+	size, _, _ := globalSize.Call(h)
+	data := lockedUTF16(p, int(size/2))
+	if i := slices.Index(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return string(utf16.Decode(data)), nil
+}
+
+// openWithRetry opens the clipboard, retrying while another program holds
+// it
+func openWithRetry() error {
+	deadline := time.Now().Add(openTimeout)
+	for {
+		r, _, err := openClipboard.Call(0)
+		if r != 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("opening the clipboard: %w", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// lockedUTF16 returns the n UTF-16 units at p, an address GlobalLock
+// returned as a uintptr. Going through &p keeps the conversion to a pointer
+// out of the uintptr arithmetic vet warns about; the memory is not managed
+// by Go and stays put while locked.
+func lockedUTF16(p uintptr, n int) []uint16 {
+	return unsafe.Slice(*(**uint16)(unsafe.Pointer(&p)), n)
+}
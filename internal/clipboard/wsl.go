@@ -0,0 +1,49 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// isWSL reports whether bcopy runs under the Windows Subsystem for Linux,
+// where the Windows clipboard is reached through clip.exe and PowerShell
+var isWSL = sync.OnceValue(func() bool {
+	data, err := os.ReadFile("/proc/version")
+	return err == nil && bytes.Contains(bytes.ToLower(data), []byte("microsoft"))
+})
+
+// wslInput encodes text for clip.exe: CRLF line endings, as Windows
+// programs expect, in UTF-16LE with a byte order mark, which clip.exe
+// reads correctly whatever the console code page
+func wslInput(text string) []byte {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	units := utf16.Encode([]rune(text))
+
+	buf := make([]byte, 2, 2+2*len(units))
+	binary.LittleEndian.PutUint16(buf, 0xFEFF)
+	for _, u := range units {
+		buf = binary.LittleEndian.AppendUint16(buf, u)
+	}
+	return buf
+}
+
+// wslPaste reads the Windows clipboard through PowerShell, converting its
+// CRLF line endings back to LF
+func wslPaste() (string, error) {
+	cmd := exec.CommandContext(context.Background(), "powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+		"[Console]::OutputEncoding = [Text.Encoding]::UTF8; Get-Clipboard -Raw")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("powershell.exe: %w", err)
+	}
+	// PowerShell ends its output with a newline of its own
+	text := strings.TrimSuffix(string(out), "\r\n")
+	return strings.ReplaceAll(text, "\r\n", "\n"), nil
+}
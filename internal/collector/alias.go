@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxAliases bounds the path alias legend
+const maxAliases = 50
+
+// aliasLegendTitle opens the legend written by FormatOptions.AliasPaths
+const aliasLegendTitle = "Path aliases (a header path starting with an alias is inside the directory it stands for):"
+
+// aliasLegendLine matches an entry of the alias legend
+var aliasLegendLine = regexp.MustCompile(`^- (@[0-9]+) = (.+)/$`)
+
+// PathAlias is a short name that stands for a directory in file headers
+type PathAlias struct {
+	Alias string
+	Dir   string // slash-separated, without a trailing slash
+}
+
+// pathAliases picks the directories whose aliases save the most bytes
+// across the file headers, net of their legend entries. Each pick is the
+// directory that saves the most on top of the aliases picked before it,
+// so a deep directory can get its own alias beneath an aliased ancestor.
+func pathAliases(files []FileData) []PathAlias {
+	ancestors := make([][]string, len(files))
+	for i, file := range files {
+		for dir := path.Dir(filepath.ToSlash(file.RelPath)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			ancestors[i] = append(ancestors[i], dir)
+		}
+	}
+
+	var aliases []PathAlias
+	saved := make([]int, len(files)) // header bytes saved per file so far
+	total := 0
+	for len(aliases) < maxAliases {
+		alias := fmt.Sprintf("@%d", len(aliases)+1)
+		gains := make(map[string]int)
+		for i, dirs := range ancestors {
+			for _, dir := range dirs {
+				if s := len(dir) - len(alias); s > saved[i] {
+					gains[dir] += s - saved[i]
+				}
+			}
+		}
+
+		best, bestGain := "", 0
+		for dir, gain := range gains {
+			gain -= len(aliasLegendEntry(PathAlias{Alias: alias, Dir: dir}))
+			if gain > bestGain || (gain == bestGain && gain > 0 && dir < best) {
+				best, bestGain = dir, gain
+			}
+		}
+		if best == "" {
+			break
+		}
+
+		aliases = append(aliases, PathAlias{Alias: alias, Dir: best})
+		total += bestGain
+		for i, dirs := range ancestors {
+			for _, dir := range dirs {
+				if dir == best {
+					saved[i] = max(saved[i], len(best)-len(alias))
+				}
+			}
+		}
+	}
+
+	// The legend's title and separator must pay for themselves too
+	if total <= len(aliasLegendTitle)+len("\n\n\n---\n\n") {
+		return nil
+	}
+	return aliases
+}
+
+// aliasLegendEntry renders one alias as a legend line
+func aliasLegendEntry(a PathAlias) string {
+	return fmt.Sprintf("- %s = %s/\n", a.Alias, MarkdownPath(a.Dir))
+}
+
+// writeAliasLegend writes the legend of aliases
+func writeAliasLegend(w io.Writer, aliases []PathAlias) {
+	io.WriteString(w, aliasLegendTitle+"\n\n")
+	for _, a := range aliases {
+		io.WriteString(w, aliasLegendEntry(a))
+	}
+	io.WriteString(w, "\n---\n\n")
+}
+
+// aliasPath replaces the longest aliased directory of relPath with its
+// alias. relPath is returned slash-separated either way.
+func aliasPath(relPath string, aliases []PathAlias) string {
+	relPath = filepath.ToSlash(relPath)
+	var best *PathAlias
+	for i, a := range aliases {
+		if strings.HasPrefix(relPath, a.Dir+"/") && (best == nil || len(a.Dir) > len(best.Dir)) {
+			best = &aliases[i]
+		}
+	}
+	if best == nil {
+		return relPath
+	}
+	return best.Alias + strings.TrimPrefix(relPath, best.Dir)
+}
+
+// parseAliasLegend reads the alias legend from the lines of a payload
+// before its first file header, if it has one
+func parseAliasLegend(lines []string) []PathAlias {
+	var aliases []PathAlias
+	inLegend := false
+	for _, line := range lines {
+		switch {
+		case line == aliasLegendTitle:
+			inLegend = true
+		case !inLegend:
+			continue
+		case line == "":
+		default:
+			match := aliasLegendLine.FindStringSubmatch(line)
+			if match == nil {
+				return aliases
+			}
+			aliases = append(aliases, PathAlias{Alias: match[1], Dir: UnescapeMarkdownPath(match[2])})
+		}
+	}
+	return aliases
+}
+
+// unaliasPath expands an alias at the start of relPath
+func unaliasPath(relPath string, aliases []PathAlias) string {
+	for _, a := range aliases {
+		if rest, ok := strings.CutPrefix(relPath, a.Alias+"/"); ok {
+			return a.Dir + "/" + rest
+		}
+	}
+	return relPath
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultAroundLines is the context of Options.Around when none is set
+const DefaultAroundLines = 10
+
+// SymbolPattern matches name as a whole identifier, so "Run" finds Run( and
+// x.Run but not RunAll
+func SymbolPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\pL\pN_])` + regexp.QuoteMeta(name) + `($|[^\pL\pN_])`)
+}
+
+// aroundRanges returns the line ranges within context lines of every line
+// of content matching re, merged where they touch. It returns nil when
+// nothing matches.
+func aroundRanges(content string, re *regexp.Regexp, context int) []LineRange {
+	context = max(context, 0)
+
+	var ranges []LineRange
+	for i, line := range strings.Split(content, "\n") {
+		if !re.MatchString(line) {
+			continue
+		}
+		r := LineRange{Start: max(1, i+1-context), End: i + 1 + context}
+		if n := len(ranges); n > 0 && r.Start <= ranges[n-1].End+1 {
+			ranges[n-1].End = r.End
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
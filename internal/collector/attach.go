@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Attachment is extra content placed in a payload that is not a file of
+// the tree, such as piped logs, a stack trace, or scratch notes
+type Attachment struct {
+	Title    string
+	Language string
+	Content  string
+}
+
+// AttachmentsMarkdown renders attachments for FormatOptions.Appendix. Each
+// gets an "Attachment:" header instead of "File: ./", so neither readers
+// nor bcopy paste take it for a file of the tree.
+func AttachmentsMarkdown(attachments []Attachment) string {
+	var sb strings.Builder
+	for _, a := range attachments {
+		fence := codeFence(a.Content)
+		fmt.Fprintf(&sb, "\n---\n\nAttachment: %s\n\n%s%s\n%s", DisplayPath(a.Title), fence, a.Language, a.Content)
+		if !strings.HasSuffix(a.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fence + "\n")
+	}
+	return sb.String()
+}
+
+// CommandOutput is the captured output of a command run for the payload
+type CommandOutput struct {
+	Command  string
+	Output   string
+	ExitCode int
+}
+
+// CommandOutputMarkdown renders command outputs for FormatOptions.Appendix,
+// each under a "Command output:" header with the command and, when it
+// failed, its exit status
+func CommandOutputMarkdown(outputs []CommandOutput) string {
+	var sb strings.Builder
+	for _, out := range outputs {
+		fmt.Fprintf(&sb, "\n---\n\nCommand output: %s", inlineCode(out.Command))
+		if out.ExitCode != 0 {
+			fmt.Fprintf(&sb, " (exit status %d)", out.ExitCode)
+		}
+		fence := codeFence(out.Output)
+		fmt.Fprintf(&sb, "\n\n%s\n%s", fence, out.Output)
+		if !strings.HasSuffix(out.Output, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fence + "\n")
+	}
+	return sb.String()
+}
+
+// inlineCode wraps s in a backtick code span long enough for the backticks
+// inside it, padded when s starts or ends with one
+func inlineCode(s string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+)
+
+// Stage is the cost of one pipeline stage in a Benchmark run
+type Stage struct {
+	Name     string
+	Items    int // files found, considered, read, or written
+	Duration time.Duration
+	Allocs   uint64 // heap allocations
+	Bytes    uint64 // heap bytes allocated
+}
+
+// Benchmark runs the stages of Collect and WriteMarkdown one at a time and
+// measures each: walk (directory enumeration with pruning), filter (file
+// selection rules), read (the worker pool, without post-read selection),
+// and format (markdown to io.Discard). Allocation counts are process-wide,
+// so nothing else should run alongside.
+func Benchmark(ctx context.Context, rootPath string, filter *analyzer.Filter, opts Options) ([]Stage, error) {
+	w := newWalker(rootPath, filter, opts)
+	includeFile := w.includeFile
+	w.includeFile = func(string, string, os.DirEntry) bool { return true }
+
+	var stages []Stage
+	measure := func(name string, run func() (int, error)) error {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		items, err := run()
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		stages = append(stages, Stage{
+			Name:     name,
+			Items:    items,
+			Duration: elapsed,
+			Allocs:   after.Mallocs - before.Mallocs,
+			Bytes:    after.TotalAlloc - before.TotalAlloc,
+		})
+		return err
+	}
+
+	var candidates, jobs []fileJob
+	if err := measure("walk", func() (int, error) {
+		var err error
+		candidates, err = w.walk(ctx)
+		return len(candidates), err
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := measure("filter", func() (int, error) {
+		for _, job := range candidates {
+			if includeFile(job.fullPath, job.relPath, job.entry) {
+				jobs = append(jobs, job)
+			}
+		}
+		return len(candidates), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	result := &CollectionResult{}
+	if err := measure("read", func() (int, error) {
+		results, wait := readFiles(ctx, jobs, opts, nil, false)
+		for res := range results {
+			if res.err == nil && res.skipped == 0 {
+				result.Files = append(result.Files, res.data)
+				result.TotalSize += res.data.Size
+			}
+		}
+		result.FileCount = len(result.Files)
+		return len(jobs), wait()
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := measure("format", func() (int, error) {
+		return len(result.Files), WriteMarkdown(io.Discard, result, FormatOptions{})
+	}); err != nil {
+		return nil, err
+	}
+
+	return stages, nil
+}
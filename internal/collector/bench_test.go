@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// benchmarkTree writes a synthetic repository of dirs directories with
+// perDir Go files each, plus excluded noise (node_modules, build output,
+// images) that the filter has to prune, and returns its root
+func benchmarkTree(b *testing.B, dirs, perDir int) string {
+	b.Helper()
+	root := b.TempDir()
+	files := syntheticTree(dirs, perDir)
+	for d := range dirs / 4 {
+		files[fmt.Sprintf("node_modules/dep%d/index.js", d)] = "module.exports = {}\n"
+		files[fmt.Sprintf("build/out%d.txt", d)] = "artifact\n"
+		files[fmt.Sprintf("assets/img%d.png", d)] = "\x89PNG\r\n"
+	}
+	writeTree(b, root, files)
+	return root
+}
+
+func BenchmarkCollect(b *testing.B) {
+	sizes := []struct{ dirs, perDir int }{{10, 20}, {100, 50}}
+	for _, size := range sizes {
+		root := benchmarkTree(b, size.dirs, size.perDir)
+		filter := newTestFilter()
+		for _, lowMemory := range []bool{false, true} {
+			name := fmt.Sprintf("files=%d/low-memory=%v", size.dirs*size.perDir, lowMemory)
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				for range b.N {
+					result, err := Collect(context.Background(), root, filter, Options{MaxFileSizeMB: 1, LowMemory: lowMemory})
+					if err != nil {
+						b.Fatal(err)
+					}
+					result.Close()
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkWriteMarkdown(b *testing.B) {
+	root := benchmarkTree(b, 100, 50)
+	result, err := Collect(context.Background(), root, newTestFilter(), Options{MaxFileSizeMB: 1})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer result.Close()
+
+	b.ReportAllocs()
+	b.SetBytes(result.TotalSize)
+	b.ResetTimer()
+	for range b.N {
+		if err := WriteMarkdown(io.Discard, result, FormatOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExtSize is the combined size of the selected files with one extension
+type ExtSize struct {
+	// Ext is the lowercased extension with its dot, or "" for files
+	// without one
+	Ext   string
+	Files int
+	Size  int64
+}
+
+// extSizes accumulates ExtSize entries by extension
+type extSizes map[string]*ExtSize
+
+func (m extSizes) add(relPath string, size int64) {
+	ext := strings.ToLower(filepath.Ext(relPath))
+	entry, ok := m[ext]
+	if !ok {
+		entry = &ExtSize{Ext: ext}
+		m[ext] = entry
+	}
+	entry.Files++
+	entry.Size += size
+}
+
+// sorted returns the entries largest first
+func (m extSizes) sorted() []ExtSize {
+	sizes := make([]ExtSize, 0, len(m))
+	for _, entry := range m {
+		sizes = append(sizes, *entry)
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].Size != sizes[j].Size {
+			return sizes[i].Size > sizes[j].Size
+		}
+		return sizes[i].Ext < sizes[j].Ext
+	})
+	return sizes
+}
+
+// SizeByExtension breaks the size of the collected files down by
+// extension, largest first, so an oversized payload can be traced to the
+// kind of file that dominates it
+func (r *CollectionResult) SizeByExtension() []ExtSize {
+	m := make(extSizes)
+	for _, file := range r.Files {
+		m.add(file.RelPath, file.Size)
+	}
+	return m.sorted()
+}
+
+// estimateByExtension is SizeByExtension for the on-disk sizes of jobs, as
+// counted by estimateSize
+func estimateByExtension(jobs []fileJob, maxFileSizeMB float64) []ExtSize {
+	m := make(extSizes)
+	for _, job := range jobs {
+		if maxFileSizeMB > 0 && float64(job.size)/(1024*1024) > maxFileSizeMB {
+			continue
+		}
+		m.add(job.relPath, job.size)
+	}
+	return m.sorted()
+}
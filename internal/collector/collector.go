@@ -1,16 +1,25 @@
 package collector
 
 import (
-	"bytes"
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/nodelike/bcopy/internal/analyzer"
-	"golang.org/x/sync/errgroup"
+	"github.com/nodelike/bcopy/internal/idl"
+	"github.com/nodelike/bcopy/internal/ui"
 )
 
 type FileData struct {
@@ -18,336 +27,766 @@ type FileData struct {
 	Content  string
 	Size     int64
 	Language string
+	// Charset and EndOfLine describe the file on disk: the encoding its
+	// content was decoded from (utf-8, utf-16le, utf-16be, or latin1) and
+	// its line endings (lf, crlf, cr, or mixed; "" without a line break),
+	// as declared in .editorconfig or else detected
+	Charset   string
+	EndOfLine string
+
+	matched     bool     // content matched Options.Grep
+	seed        bool     // starting point of the Options.IDL import closure
+	imports     []string // import targets, with Options.IDL
+	rank        int      // output group; files sort by rank, then path
+	lines       string   // header annotation of a file limited to line ranges
+	spilled     bool     // Content lives in the result's spill file
+	spillOffset int64    // offset of the content in the spill file
+	spillLen    int64
+}
+
+// Partial reports whether the file holds only some line ranges of the file
+// on disk, as selected by an editor selection
+func (f FileData) Partial() bool {
+	return f.lines != ""
 }
 
 type CollectionResult struct {
 	Files     []FileData
 	TotalSize int64
 	FileCount int
+
+	// PermissionDenied lists relative paths of files and directories that
+	// were skipped because they could not be read
+	PermissionDenied []string
+	// ReadErrors lists files and directories that failed to read for other
+	// reasons, typically because they were deleted or replaced mid-run
+	ReadErrors []ReadError
+	// InvalidNames lists relative paths that are not valid UTF-8. Headers
+	// render them lossily, with U+FFFD in place of the offending bytes.
+	InvalidNames []string
+	// OtherFileSystems lists directories that were not entered because
+	// they are mount points of another filesystem (Options.OneFileSystem)
+	OtherFileSystems []string
+	// SkippedContent lists files that were selected but left out because
+	// they are binary or larger than Options.MaxFileSizeMB
+	SkippedContent []SkippedFile
+	// OverQuota lists files left out because they didn't fit
+	// Options.Quotas
+	OverQuota []SkippedFile
+	// Minified lists files left out because they look minified
+	// (Options.Minified)
+	Minified []SkippedFile
+	// ExtLimited lists files left out or cut short to fit
+	// Options.ExtLimits, with the number of bytes that were dropped
+	ExtLimited []SkippedFile
+	// Skipped records every file or directory above that was left out,
+	// with the reason, sorted by path
+	Skipped []SkipRecord
+
+	spill *spillFile
 }
 
-func Collect(ctx context.Context, rootPath string, filter *analyzer.Filter, maxDepth int, maxFileSizeMB float64) (*CollectionResult, error) {
-	result := &CollectionResult{
-		Files: make([]FileData, 0),
+// ReadError records a path that could not be read
+type ReadError struct {
+	RelPath string
+	Err     error
+}
+
+// SkippedFile records a file left out for its content
+type SkippedFile struct {
+	RelPath string
+	Size    int64
+}
+
+// Unreadable returns the number of paths skipped due to read errors
+func (r *CollectionResult) Unreadable() int {
+	return len(r.PermissionDenied) + len(r.ReadErrors)
+}
+
+// ReadContent returns the content of file, loading it back from disk when
+// the result was collected with Options.LowMemory
+func (r *CollectionResult) ReadContent(file FileData) (string, error) {
+	if !file.spilled {
+		return file.Content, nil
+	}
+	return r.spill.load(file.spillOffset, file.spillLen)
+}
+
+// Close releases the temporary storage of a low-memory result. It is safe
+// to call on any result.
+func (r *CollectionResult) Close() error {
+	if r.spill == nil {
+		return nil
+	}
+	return r.spill.close()
+}
+
+// Subset returns a result holding only the files for which keep returns
+// true. It shares storage with r, so only r needs to be closed.
+func (r *CollectionResult) Subset(keep func(FileData) bool) *CollectionResult {
+	subset := &CollectionResult{
+		PermissionDenied: r.PermissionDenied,
+		Skipped:          r.Skipped,
+		spill:            r.spill,
+	}
+	for _, file := range r.Files {
+		if keep(file) {
+			subset.Files = append(subset.Files, file)
+			subset.TotalSize += file.Size
+		}
 	}
+	subset.FileCount = len(subset.Files)
+	return subset
+}
 
-	type fileJob struct {
-		fullPath string
-		relPath  string
+// Renamed returns a result whose files carry the paths rename gives them,
+// for output only: ReadContent still works, but the paths no longer name
+// files on disk. It shares storage with r, so only r needs to be closed.
+func (r *CollectionResult) Renamed(rename func(relPath string) string) *CollectionResult {
+	renamed := *r
+	renamed.Files = make([]FileData, len(r.Files))
+	for i, file := range r.Files {
+		file.RelPath = rename(file.RelPath)
+		renamed.Files[i] = file
 	}
+	return &renamed
+}
+
+// ErrTooManyFiles is returned when the walk selects more than
+// Options.MaxFiles files. Collect fails before reading any content.
+var ErrTooManyFiles = errors.New("too many files selected")
+
+// ErrTooLarge is returned when the selection is larger than
+// Options.MaxTotalSize, judged by the on-disk size before any content is
+// read and by the collected size after
+var ErrTooLarge = errors.New("selection too large")
+
+// ErrCanceled is returned when the context of Collect is canceled or
+// times out; the context's error is wrapped as well
+var ErrCanceled = errors.New("collection canceled")
+
+// Transform rewrites a collected file in place (content and possibly path)
+type Transform func(file *FileData)
+
+// Options controls how Collect walks the tree and which files it keeps
+type Options struct {
+	// MaxDepth limits how many path components a selected file may have:
+	// 1 keeps the files in the root only (0 = unlimited)
+	MaxDepth int
+	// DepthRules override MaxDepth for matching paths; the first match wins
+	DepthRules    []DepthRule
+	MaxFileSizeMB float64
+	// MaxFiles aborts the collection when more files are selected (0 = unlimited)
+	MaxFiles int
+	// MaxTotalSize aborts the collection with ErrTooLarge when the selected
+	// files add up to more bytes (0 = unlimited)
+	MaxTotalSize int64
+	// Quotas bound the files selected under matching globs; the first
+	// match wins. Dropped files are listed in OverQuota.
+	Quotas []Quota
+	// QuotaOrder decides which files fill a quota first (QuotaByPath if
+	// empty), and which fill an extension limit first
+	QuotaOrder QuotaOrder
+	// ExtLimits cap the bytes selected per extension, after Quotas; files
+	// that don't fit are cut or dropped and listed in ExtLimited
+	ExtLimits []ExtLimit
+	// Minified decides what happens to files that look minified (see
+	// IsMinified); entry points are always kept whole. Empty keeps them.
+	Minified MinifiedMode
+	// GroupByDir sorts files by directory first, so each directory's files
+	// are adjacent; within a directory they sort by rank, then name
+	GroupByDir bool
+
+	// Grep, when set, keeps only files whose content matches the expression
+	Grep *regexp.Regexp
+	// ContextFiles also keeps the directory siblings of files matched by Grep
+	ContextFiles bool
+	// Around, when set, keeps only the lines within AroundLines of a match
+	// in each file (see SymbolPattern) and drops files without one
+	Around      *regexp.Regexp
+	AroundLines int
+
+	// ChangedAfter, when non-zero, keeps only files modified after this time
+	ChangedAfter time.Time
+	// ChangedPaths, when non-nil, replaces the mtime check for ChangedAfter
+	// with membership in this set of absolute paths (e.g. from git history)
+	ChangedPaths map[string]bool
+
+	// Owner, when set, keeps only files CodeOwners assigns to this user or team
+	Owner      string
+	CodeOwners *analyzer.CodeOwners
+
+	// Attributes applies .gitattributes linguist overrides when non-nil
+	Attributes *analyzer.GitAttributes
+	// EditorConfig, when non-nil, supplies each file's declared charset and
+	// end_of_line, which override what sniffing its content would decide
+	EditorConfig *analyzer.EditorConfig
+
+	// Transforms run in order over every file after it is read
+	Transforms []Transform
+
+	// Preflight, when set, is called after the walk with the number of
+	// selected files and their combined on-disk size, overall and by
+	// extension, before any content is read. Returning an error aborts the
+	// collection. It is skipped with Grep and Around, since the final
+	// selection is then much smaller than the estimate.
+	Preflight func(files int, estimatedSize int64, byExt []ExtSize) error
+
+	// Within, when non-empty, limits the selection to these slash-separated
+	// directories (relative to the root) plus the files directly in the root
+	Within []string
+
+	// EntryPoints are slash-separated paths relative to the root that are
+	// always selected, placed first, and annotated as entry points
+	EntryPoints []string
+
+	// IncludeEnv selects .env files. They are excluded by default, except
+	// for templates such as .env.example, which are always selected.
+	IncludeEnv bool
+
+	// WithDocs selects READMEs, CONTRIBUTING, ARCHITECTURE.md, and docs/**.md
+	// regardless of the allowed extensions and Grep, and places them first
+	WithDocs bool
+
+	// WithMeta selects repository metadata (.github/ templates and
+	// workflows, LICENSE, CODE_OF_CONDUCT, SECURITY, ...) regardless of the
+	// allowed extensions and Grep, and places it after the docs
+	WithMeta bool
+
+	// WithFixtures selects test data (testdata/, fixtures/, golden files)
+	// regardless of the allowed extensions and test exclusion. Golden
+	// files are kept only when a test next to them refers to them.
+	WithFixtures bool
+
+	// Survey selects a representative sample instead of everything: the
+	// top-level README, manifests and config files, entry points, and one
+	// file per directory, within SurveyTokens estimated tokens
+	Survey bool
+	// SurveyTokens is the token budget of Survey (DefaultSurveyTokens if 0)
+	SurveyTokens int
+
+	// IDL selects only API contract files (Protobuf, GraphQL, Thrift, Avro,
+	// OpenAPI) plus everything they import, transitively. Within and Grep
+	// narrow the starting set; imports are followed anywhere under the root.
+	IDL bool
+
+	// Schema selects only .sql files and replaces the migrations of each
+	// directory (goose, golang-migrate, Flyway naming) with one synthesized
+	// current-schema file
+	Schema bool
+
+	// OneFileSystem keeps the walk on the filesystem of the root: mount
+	// points found inside the tree, such as network shares or external
+	// volumes, are skipped and listed in OtherFileSystems
+	OneFileSystem bool
+
+	// LowMemory spills file contents to a temporary file instead of keeping
+	// them in the result, so peak memory stays flat regardless of payload
+	// size. Use ReadContent to access contents and Close when done.
+	LowMemory bool
+}
+
+func Collect(ctx context.Context, rootPath string, filter *analyzer.Filter, opts Options) (*CollectionResult, error) {
+	maxFileSizeMB := opts.MaxFileSizeMB
 
-	fileJobs := make([]fileJob, 0)
-	visitedDirs := make(map[string]bool) // Track visited directories to avoid symlink loops
+	result := &CollectionResult{
+		Files: make([]FileData, 0),
+	}
 
-	err := filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+	if opts.LowMemory {
+		spill, err := newSpillFile()
 		if err != nil {
-			return nil
+			return nil, err
 		}
+		result.spill = spill
+	}
 
-		// Handle symlinks to avoid infinite loops
-		if d.Type()&os.ModeSymlink != 0 {
-			realPath, err := filepath.EvalSymlinks(path)
-			if err != nil {
-				return nil // Skip broken symlinks
-			}
+	w := newWalker(rootPath, filter, opts)
+	fileJobs, err := w.walk(ctx)
+	if err != nil {
+		result.Close()
+		return nil, collectError(ctx, err)
+	}
+	if opts.WithFixtures {
+		fileJobs = selectReferencedGoldens(rootPath, filter, fileJobs)
+	}
+	if opts.Survey {
+		fileJobs = selectSurvey(fileJobs, opts)
+	}
+	fileJobs, result.OverQuota = applyQuotas(fileJobs, opts)
+	var dropped []string
+	fileJobs, result.ExtLimited, dropped = applyExtLimits(fileJobs, opts)
 
-			// Check if we've already visited this real path
-			if visitedDirs[realPath] {
-				return filepath.SkipDir // Skip to avoid loop
-			}
+	if opts.MaxFiles > 0 && len(fileJobs) > opts.MaxFiles {
+		result.Close()
+		return nil, fmt.Errorf("%w: %d files (limit %d)", ErrTooManyFiles, len(fileJobs), opts.MaxFiles)
+	}
 
-			// Mark as visited if it's a directory
-			info, err := os.Stat(realPath)
-			if err == nil && info.IsDir() {
-				visitedDirs[realPath] = true
-			}
-		}
+	// With Grep, Around, IDL, and Schema the final selection is much
+	// smaller than the estimate, so only the collected size counts
+	estimated := opts.Grep == nil && opts.Around == nil && !opts.IDL && !opts.Schema
+	if size := estimateSize(fileJobs, maxFileSizeMB); estimated && opts.MaxTotalSize > 0 && size > opts.MaxTotalSize {
+		result.Close()
+		return nil, fmt.Errorf("%w: %s on disk (limit %s)", ErrTooLarge, FormatSize(size), FormatSize(opts.MaxTotalSize))
+	}
 
-		relPath, err := filepath.Rel(rootPath, path)
-		if err != nil {
-			return nil
+	if opts.Preflight != nil && estimated {
+		byExt := estimateByExtension(fileJobs, maxFileSizeMB)
+		if err := opts.Preflight(len(fileJobs), estimateSize(fileJobs, maxFileSizeMB), byExt); err != nil {
+			result.Close()
+			return nil, err
 		}
+	}
 
-		if d.IsDir() {
-			if relPath != "." {
-				depth := strings.Count(relPath, string(os.PathSeparator)) + 1
-				if maxDepth > 0 && depth > maxDepth {
-					return filepath.SkipDir
-				}
+	results, wait := readFiles(ctx, fileJobs, opts, result.spill, true)
 
-				if !filter.ShouldInclude(relPath + "/dummy.go") {
-					return filepath.SkipDir
-				}
+	result.PermissionDenied = w.denied
+	result.ReadErrors = w.failed
+	result.OtherFileSystems = w.crossed
+	for _, relPath := range w.denied {
+		result.skip(relPath, SkipPermission, fs.ErrPermission)
+	}
+	for _, failed := range w.failed {
+		result.skip(failed.RelPath, SkipReadError, failed.Err)
+	}
+	for _, relPath := range w.crossed {
+		result.skip(relPath, SkipOtherFileSystem, nil)
+	}
+	for _, file := range result.OverQuota {
+		result.skip(file.RelPath, SkipOverQuota, nil)
+	}
+	for _, relPath := range dropped {
+		result.skip(relPath, SkipExtLimit, nil)
+	}
+	for res := range results {
+		if res.err != nil {
+			if errors.Is(res.err, fs.ErrPermission) {
+				result.PermissionDenied = append(result.PermissionDenied, res.relPath)
+				result.skip(res.relPath, SkipPermission, res.err)
+			} else {
+				result.ReadErrors = append(result.ReadErrors, ReadError{RelPath: res.relPath, Err: res.err})
+				result.skip(res.relPath, SkipReadError, res.err)
 			}
-			return nil
+			continue
+		}
+		if res.reason == SkipMinified {
+			result.Minified = append(result.Minified, SkippedFile{RelPath: res.relPath, Size: res.skipped})
+			result.skip(res.relPath, SkipMinified, nil)
+			continue
 		}
+		if res.skipped > 0 {
+			result.SkippedContent = append(result.SkippedContent, SkippedFile{RelPath: res.relPath, Size: res.skipped})
+			result.skip(res.relPath, res.reason, nil)
+			continue
+		}
+		result.Files = append(result.Files, res.data)
+		result.TotalSize += res.data.Size
+	}
 
-		if !filter.ShouldInclude(relPath) {
-			return nil
+	if err := wait(); err != nil {
+		result.Close()
+		return nil, collectError(ctx, err)
+	}
+
+	if opts.IDL {
+		result.Files = selectImportClosure(result.Files)
+	} else if opts.Grep != nil {
+		result.Files = selectMatching(result.Files, opts.ContextFiles)
+	}
+	if opts.Schema {
+		if err := squashMigrations(result, opts); err != nil {
+			result.Close()
+			return nil, err
+		}
+	}
+	if opts.IDL || opts.Grep != nil || opts.Schema {
+		result.TotalSize = 0
+		for _, file := range result.Files {
+			result.TotalSize += file.Size
 		}
+	}
+	if opts.MaxTotalSize > 0 && result.TotalSize > opts.MaxTotalSize {
+		result.Close()
+		return nil, fmt.Errorf("%w: %s (limit %s)", ErrTooLarge, FormatSize(result.TotalSize), FormatSize(opts.MaxTotalSize))
+	}
 
-		fileJobs = append(fileJobs, fileJob{fullPath: path, relPath: relPath})
-		return nil
+	ui.Complete("collect.done", len(result.Files))
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		if opts.GroupByDir {
+			if di, dj := fileDir(result.Files[i].RelPath), fileDir(result.Files[j].RelPath); di != dj {
+				return di < dj
+			}
+		}
+		if result.Files[i].rank != result.Files[j].rank {
+			return result.Files[i].rank < result.Files[j].rank
+		}
+		return result.Files[i].RelPath < result.Files[j].RelPath
 	})
+	sort.Strings(result.PermissionDenied)
+	sort.Strings(result.OtherFileSystems)
+	for _, file := range result.Files {
+		if !utf8.ValidString(file.RelPath) {
+			result.InvalidNames = append(result.InvalidNames, file.RelPath)
+		}
+	}
+	sort.Slice(result.ReadErrors, func(i, j int) bool {
+		return result.ReadErrors[i].RelPath < result.ReadErrors[j].RelPath
+	})
+	result.sortSkipped()
 
-	if err != nil {
-		return nil, err
+	result.FileCount = len(result.Files)
+
+	return result, nil
+}
+
+// collectError returns ErrCanceled, wrapping err, when err is due to the
+// cancellation of ctx, and err otherwise
+func collectError(ctx context.Context, err error) error {
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	}
+	return err
+}
+
+// newWalker returns a walker applying filter and the selection options
+func newWalker(rootPath string, filter *analyzer.Filter, opts Options) *walker {
+	return &walker{
+		rootPath:      rootPath,
+		maxDepth:      walkDepth(opts),
+		oneFileSystem: opts.OneFileSystem,
+		includeDir: func(relPath string) bool {
+			return filter.ShouldIncludeDir(relPath) && (opts.IDL || dirWithin(opts.Within, relPath))
+		},
+		includeFile: func(path, relPath string, d os.DirEntry) bool {
+			return (opts.IDL || fileWithin(opts.Within, relPath)) && includeFile(filter, opts, path, relPath, d)
+		},
 	}
+}
 
-	eg, egCtx := errgroup.WithContext(ctx)
-	eg.SetLimit(16)
+// FormatOptions controls the layout produced by FormatAsMarkdown
+type FormatOptions struct {
+	// TOC prepends a numbered table of contents linking to each file
+	TOC bool
+	// Preamble is written verbatim before everything else
+	Preamble string
+	// Appendix is written verbatim after everything else
+	Appendix string
+	// FenceLanguage, when set, replaces the detected language of every code
+	// fence
+	FenceLanguage string
+	// NoFences writes file contents without code fences, for consumers that
+	// render fenced blocks badly
+	NoFences bool
+	// JSONString writes the whole payload as a single JSON string
+	JSONString bool
+	// JSONLines writes one JSON object per file and line instead of
+	// markdown
+	JSONLines bool
+	// Reproducible writes byte-identical output for identical inputs:
+	// slash-separated paths and fixed timestamps in archives
+	Reproducible bool
+	// Delimited writes each file between BEGIN/END FILE lines carrying its
+	// size and SHA-256 instead of a markdown header and code fence, so
+	// ParseDelimited can restore it byte for byte
+	Delimited bool
+	// GroupByDir writes a "## dir/" heading before the first file of each
+	// directory; the files should be collected with Options.GroupByDir
+	GroupByDir bool
+	// AliasPaths replaces long directories that repeat across file headers
+	// with short aliases such as @1, defined in a legend at the top.
+	// ParseMarkdown expands them again.
+	AliasPaths bool
+	// Metadata annotates each file header with the file's charset and line
+	// endings on disk, as in "File: ./run.bat [charset=latin1
+	// end_of_line=crlf]"; the parsers read them back
+	Metadata bool
+}
 
-	type fileResult struct {
-		data FileData
-		err  error
+func FormatAsMarkdown(result *CollectionResult, opts FormatOptions) (string, error) {
+	var sb strings.Builder
+	if err := WriteMarkdown(&sb, result, opts); err != nil {
+		return "", err
 	}
+	return sb.String(), nil
+}
 
-	resultsChan := make(chan fileResult, len(fileJobs))
+// WriteMarkdown streams the markdown payload to w one file at a time, so
+// low-memory results never need to be held in memory as a whole. With
+// opts.Delimited the files are written in the delimited format instead,
+// and with opts.JSONString or opts.JSONLines as JSON.
+func WriteMarkdown(w io.Writer, result *CollectionResult, opts FormatOptions) error {
+	if opts.JSONString {
+		return writeJSONString(w, result, opts)
+	}
+	if opts.JSONLines {
+		return writeJSONLines(w, result, opts)
+	}
 
-	// Simple progress without terminal manipulation
-	fmt.Fprintf(os.Stderr, "\033[36m📦 Collecting files...\033[0m ")
+	bw := bufio.NewWriter(w)
+	bw.WriteString(opts.Preamble)
 
-	progressDots := 0
-	progressTicker := make(chan struct{}, 10)
+	var aliases []PathAlias
+	if opts.AliasPaths && !opts.Delimited {
+		aliases = pathAliases(result.Files)
+		if len(aliases) > 0 {
+			writeAliasLegend(bw, aliases)
+		}
+	}
+	if opts.TOC {
+		writeTOC(bw, result, aliases)
+	}
 
-	go func() {
-		for range progressTicker {
-			if progressDots < 3 {
-				fmt.Fprint(os.Stderr, ".")
-				progressDots++
-			}
+	if opts.Delimited {
+		if err := writeDelimited(bw, result, opts); err != nil {
+			return err
 		}
-	}()
+		bw.WriteString(opts.Appendix)
+		return bw.Flush()
+	}
 
-	for _, job := range fileJobs {
-		job := job
-		eg.Go(func() error {
-			select {
-			case <-egCtx.Done():
-				return egCtx.Err()
-			default:
-			}
+	lastDir := ""
+	for i, file := range result.Files {
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return err
+		}
 
-			// Check if file is binary by reading first chunk
-			if isBinary, err := isBinaryFile(job.fullPath); err != nil || isBinary {
-				select {
-				case progressTicker <- struct{}{}:
-				default:
-				}
-				return nil // Skip binary files
+		if dir := fileDir(file.RelPath); opts.GroupByDir && dir != lastDir {
+			fmt.Fprintf(bw, "## %s\n\n", MarkdownPath(dir))
+			lastDir = dir
+		}
+		if opts.TOC {
+			fmt.Fprintf(bw, "<a id=\"file-%d\"></a>\n", i+1)
+		}
+		relPath := file.RelPath
+		if opts.Reproducible {
+			relPath = filepath.ToSlash(relPath)
+		}
+		if len(aliases) > 0 {
+			relPath = aliasPath(relPath, aliases)
+		}
+		relPath = MarkdownPath(relPath)
+		meta := ""
+		if opts.Metadata {
+			meta = metadataSuffix(file)
+		}
+		if file.rank == rankEntry {
+			fmt.Fprintf(bw, "File: ./%s%s%s%s\n\n", relPath, entryPointSuffix, file.lines, meta)
+		} else {
+			fmt.Fprintf(bw, "File: ./%s%s%s\n\n", relPath, file.lines, meta)
+		}
+		if opts.NoFences {
+			bw.WriteString(content)
+			if !strings.HasSuffix(content, "\n") {
+				bw.WriteString("\n")
 			}
-
-			info, err := os.Stat(job.fullPath)
-			if err != nil {
-				resultsChan <- fileResult{err: err}
-				select {
-				case progressTicker <- struct{}{}:
-				default:
-				}
-				return nil
+		} else {
+			lang := file.Language
+			if opts.FenceLanguage != "" {
+				lang = opts.FenceLanguage
 			}
-
-			// Check file size limit
-			fileSizeMB := float64(info.Size()) / (1024 * 1024)
-			if maxFileSizeMB > 0 && fileSizeMB > maxFileSizeMB {
-				select {
-				case progressTicker <- struct{}{}:
-				default:
-				}
-				return nil // Skip files that are too large
+			fence := codeFence(content)
+			fmt.Fprintf(bw, "%s%s\n", fence, lang)
+			bw.WriteString(content)
+			if !strings.HasSuffix(content, "\n") {
+				bw.WriteString("\n")
 			}
+			bw.WriteString(fence + "\n")
+		}
 
-			content, err := os.ReadFile(job.fullPath)
-			if err != nil {
-				resultsChan <- fileResult{err: err}
-				select {
-				case progressTicker <- struct{}{}:
-				default:
-				}
-				return nil
-			}
+		if i < len(result.Files)-1 {
+			bw.WriteString("\n---\n\n")
+		}
+	}
 
-			fileData := FileData{
-				RelPath:  job.relPath,
-				Content:  string(content),
-				Size:     info.Size(),
-				Language: getLanguage(job.relPath),
-			}
+	bw.WriteString(opts.Appendix)
+	return bw.Flush()
+}
 
-			resultsChan <- fileResult{data: fileData}
-			select {
-			case progressTicker <- struct{}{}:
-			default:
-			}
-			return nil
-		})
+// fileDir returns the slash-separated directory of relPath with a
+// trailing slash, or "./" for files in the root
+func fileDir(relPath string) string {
+	dir := path.Dir(filepath.ToSlash(relPath))
+	if dir == "." {
+		return "./"
 	}
+	return dir + "/"
+}
 
-	go func() {
-		eg.Wait()
-		close(resultsChan)
-	}()
-
-	for res := range resultsChan {
-		if res.err != nil {
-			continue
+// writeTOC writes a numbered table of contents with per-file sizes,
+// abbreviating paths with aliases if there are any
+func writeTOC(w io.Writer, result *CollectionResult, aliases []PathAlias) {
+	fmt.Fprintf(w, "## Table of Contents (%d files, %s)\n\n", len(result.Files), FormatSize(result.TotalSize))
+	for i, file := range result.Files {
+		relPath := file.RelPath
+		if len(aliases) > 0 {
+			relPath = aliasPath(relPath, aliases)
 		}
-		result.Files = append(result.Files, res.data)
-		result.TotalSize += res.data.Size
+		fmt.Fprintf(w, "%d. [./%s](#file-%d) (%s)\n", i+1, MarkdownPath(relPath), i+1, FormatSize(file.Size))
 	}
+	io.WriteString(w, "\n---\n\n")
+}
 
-	if err := eg.Wait(); err != nil {
-		return nil, err
+// FormatSize renders a byte count in B, KB, or MB
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.2f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
 	}
+}
 
-	close(progressTicker)
-	fmt.Fprintf(os.Stderr, " \033[32m✓\033[0m (%d files)\n", len(result.Files))
+// estimateSize sums the on-disk sizes recorded during the walk, leaving out
+// files that will be skipped for exceeding maxFileSizeMB
+func estimateSize(jobs []fileJob, maxFileSizeMB float64) int64 {
+	var total int64
+	for _, job := range jobs {
+		if maxFileSizeMB > 0 && float64(job.size)/(1024*1024) > maxFileSizeMB {
+			continue
+		}
+		total += job.size
+	}
+	return total
+}
 
-	sort.Slice(result.Files, func(i, j int) bool {
-		return result.Files[i].RelPath < result.Files[j].RelPath
-	})
+// includeFile applies the filter and the selection options to a single
+// file found during the walk. It runs concurrently and must not mutate state.
+func includeFile(filter *analyzer.Filter, opts Options, path, relPath string, d os.DirEntry) bool {
+	if filter.IsBanned(relPath) {
+		slog.Debug("file excluded: banned by policy", "path", relPath)
+		return false
+	}
+	if isEntryPoint(opts, relPath) {
+		return true
+	}
 
-	result.FileCount = len(result.Files)
+	if !withinDepth(opts, relPath) {
+		slog.Debug("file excluded: max depth", "path", relPath, "max_depth", depthLimit(opts, relPath))
+		return false
+	}
 
-	return result, nil
-}
+	if opts.Schema {
+		if !isSQL(relPath) {
+			slog.Debug("file excluded: not an SQL file", "path", relPath)
+			return false
+		}
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if opts.IDL {
+		if !idl.IsImportable(relPath) {
+			slog.Debug("file excluded: not an IDL file", "path", relPath)
+			return false
+		}
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if analyzer.IsEnvFile(relPath) {
+		if !analyzer.IsEnvTemplate(relPath) && !opts.IncludeEnv {
+			slog.Debug("file excluded: dotenv file", "path", relPath)
+			return false
+		}
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if _, doc := docRank(relPath); doc && opts.WithDocs {
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if opts.WithMeta && isRepoMeta(relPath) {
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if opts.Survey && isManifest(relPath) {
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if opts.WithFixtures && analyzer.IsFixture(relPath) {
+		if filter.IsExcluded(relPath) {
+			slog.Debug("file excluded by filter", "path", relPath)
+			return false
+		}
+	} else if !filter.ShouldInclude(relPath) {
+		slog.Debug("file excluded by filter", "path", relPath)
+		return false
+	}
 
-func FormatAsMarkdown(result *CollectionResult) string {
-	var sb strings.Builder
+	if !opts.ChangedAfter.IsZero() && !changedAfter(path, d, opts) {
+		slog.Debug("file excluded: not changed recently", "path", relPath)
+		return false
+	}
 
-	for i, file := range result.Files {
-		sb.WriteString(fmt.Sprintf("File: ./%s\n\n", file.RelPath))
-		sb.WriteString(fmt.Sprintf("```%s\n", file.Language))
-		sb.WriteString(file.Content)
-		if !strings.HasSuffix(file.Content, "\n") {
-			sb.WriteString("\n")
+	if opts.Owner != "" {
+		absPath, err := filepath.Abs(path)
+		if err != nil || !opts.CodeOwners.Owns(opts.Owner, absPath) {
+			slog.Debug("file excluded: not owned", "path", relPath, "owner", opts.Owner)
+			return false
 		}
-		sb.WriteString("```\n")
+	}
 
-		if i < len(result.Files)-1 {
-			sb.WriteString("\n---\n\n")
+	if opts.Attributes != nil {
+		absPath, err := filepath.Abs(path)
+		if err != nil || opts.Attributes.IsExcluded(absPath) {
+			slog.Debug("file excluded by gitattributes", "path", relPath)
+			return false
 		}
 	}
 
-	return sb.String()
+	return true
 }
 
-// isBinaryFile checks if a file is binary by reading the first chunk
-// Returns true if the file contains null bytes (binary indicator)
-func isBinaryFile(filePath string) (bool, error) {
-	file, err := os.Open(filePath)
+// changedAfter reports whether the file at path was modified after
+// opts.ChangedAfter, by git history when ChangedPaths is set or mtime
+// otherwise
+func changedAfter(path string, d os.DirEntry, opts Options) bool {
+	if opts.ChangedPaths != nil {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return false
+		}
+		return opts.ChangedPaths[absPath]
+	}
+
+	info, err := d.Info()
 	if err != nil {
-		return false, err
+		return false
 	}
-	defer file.Close()
+	return info.ModTime().After(opts.ChangedAfter)
+}
 
-	// Read first 8KB to check for binary content
-	buf := make([]byte, 8192)
-	n, err := file.Read(buf)
-	if err != nil && n == 0 {
-		return false, err
+// selectMatching keeps files whose content matched Options.Grep, and docs
+// and metadata selected by Options.WithDocs and Options.WithMeta. With
+// withSiblings, every file that shares a directory with a match is kept
+// too.
+func selectMatching(files []FileData, withSiblings bool) []FileData {
+	matchedDirs := make(map[string]bool)
+	for _, file := range files {
+		if file.matched {
+			matchedDirs[filepath.Dir(file.RelPath)] = true
+		}
 	}
 
-	// Check for null bytes which indicate binary content
-	return bytes.IndexByte(buf[:n], 0) != -1, nil
+	selected := make([]FileData, 0)
+	for _, file := range files {
+		if file.matched || file.rank < rankDefault || (withSiblings && matchedDirs[filepath.Dir(file.RelPath)]) {
+			selected = append(selected, file)
+		}
+	}
+	return selected
 }
 
-func getLanguage(filename string) string {
-	base := filepath.Base(filename)
-	ext := filepath.Ext(filename)
-
-	// Check for special files without extensions
-	noExtMap := map[string]string{
-		"Makefile":    "makefile",
-		"Dockerfile":  "dockerfile",
-		"Rakefile":    "ruby",
-		"Gemfile":     "ruby",
-		"Procfile":    "yaml",
-		"Vagrantfile": "ruby",
-		"Cargo":       "toml",
-	}
-
-	if ext == "" {
-		if lang, ok := noExtMap[base]; ok {
-			return lang
-		}
-		return ""
-	}
-
-	languageMap := map[string]string{
-		".go":         "go",
-		".py":         "python",
-		".js":         "javascript",
-		".jsx":        "jsx",
-		".ts":         "typescript",
-		".tsx":        "tsx",
-		".vue":        "vue",
-		".svelte":     "svelte",
-		".mjs":        "javascript",
-		".cjs":        "javascript",
-		".rs":         "rust",
-		".java":       "java",
-		".c":          "c",
-		".cpp":        "cpp",
-		".cc":         "cpp",
-		".cxx":        "cpp",
-		".h":          "c",
-		".hpp":        "cpp",
-		".hh":         "cpp",
-		".cs":         "csharp",
-		".rb":         "ruby",
-		".php":        "php",
-		".swift":      "swift",
-		".kt":         "kotlin",
-		".kts":        "kotlin",
-		".scala":      "scala",
-		".sh":         "bash",
-		".bash":       "bash",
-		".zsh":        "zsh",
-		".fish":       "fish",
-		".yaml":       "yaml",
-		".yml":        "yaml",
-		".json":       "json",
-		".xml":        "xml",
-		".html":       "html",
-		".htm":        "html",
-		".css":        "css",
-		".scss":       "scss",
-		".sass":       "sass",
-		".less":       "less",
-		".md":         "markdown",
-		".markdown":   "markdown",
-		".sql":        "sql",
-		".toml":       "toml",
-		".ini":        "ini",
-		".conf":       "conf",
-		".env":        "bash",
-		".txt":        "text",
-		".dockerfile": "dockerfile",
-		".pl":         "perl",
-		".pm":         "perl",
-		".lua":        "lua",
-		".vim":        "vim",
-		".ex":         "elixir",
-		".exs":        "elixir",
-		".erl":        "erlang",
-		".hrl":        "erlang",
-		".clj":        "clojure",
-		".cljs":       "clojure",
-		".dart":       "dart",
-		".r":          "r",
-		".R":          "r",
-		".m":          "objective-c",
-		".mm":         "objective-c",
-		".groovy":     "groovy",
-		".gradle":     "gradle",
-		".tf":         "terraform",
-		".tfvars":     "terraform",
-		".hcl":        "hcl",
-	}
-
-	if lang, ok := languageMap[ext]; ok {
-		return lang
-	}
-	return ""
+// EstimateTokens approximates the LLM token count of size bytes of source
+// text using the common rule of thumb of four bytes per token
+func EstimateTokens(size int64) int64 {
+	return (size + 3) / 4
 }
@@ -0,0 +1,209 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/ui"
+)
+
+func TestMain(m *testing.M) {
+	// Keep status lines out of the test output
+	ui.Output = io.Discard
+	os.Exit(m.Run())
+}
+
+// writeTree creates files (relative path to content) under root
+func writeTree(t testing.TB, root string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// syntheticTree returns dirs directories of perDir Go files each, nested
+// two levels deep, so the walk and the read pool both fan out
+func syntheticTree(dirs, perDir int) map[string]string {
+	files := make(map[string]string, dirs*perDir)
+	for d := range dirs {
+		dir := fmt.Sprintf("pkg%02d/sub%d", d, d%3)
+		for f := range perDir {
+			files[fmt.Sprintf("%s/file%03d.go", dir, f)] = fmt.Sprintf("package sub%d\n\n// file %d of %s\nfunc F%d() int { return %d }\n", d%3, f, dir, f, d*perDir+f)
+		}
+	}
+	return files
+}
+
+func newTestFilter() *analyzer.Filter {
+	return analyzer.NewFilter(nil, analyzer.DefaultExcludes(), nil, false, false)
+}
+
+// checkCollected fails unless result holds exactly files, sorted by path,
+// with their contents
+func checkCollected(t *testing.T, result *CollectionResult, files map[string]string) {
+	t.Helper()
+	if result.FileCount != len(files) || len(result.Files) != len(files) {
+		t.Fatalf("collected %d files (FileCount %d), want %d", len(result.Files), result.FileCount, len(files))
+	}
+	if !sort.SliceIsSorted(result.Files, func(i, j int) bool { return result.Files[i].RelPath < result.Files[j].RelPath }) {
+		t.Error("files are not sorted by path")
+	}
+	var total int64
+	for _, file := range result.Files {
+		want, ok := files[filepath.ToSlash(file.RelPath)]
+		if !ok {
+			t.Errorf("unexpected file %s", file.RelPath)
+			continue
+		}
+		content, err := result.ReadContent(file)
+		if err != nil {
+			t.Fatalf("ReadContent(%s): %v", file.RelPath, err)
+		}
+		if content != want {
+			t.Errorf("%s: content %q, want %q", file.RelPath, content, want)
+		}
+		total += file.Size
+	}
+	if total != result.TotalSize {
+		t.Errorf("TotalSize = %d, want the sum of file sizes %d", result.TotalSize, total)
+	}
+}
+
+// resultOf builds a result holding files (relative path to content) in
+// memory
+func resultOf(files map[string]string) *CollectionResult {
+	result := &CollectionResult{}
+	for path, content := range files {
+		result.Files = append(result.Files, FileData{RelPath: path, Content: content, Size: int64(len(content))})
+		result.FileCount++
+		result.TotalSize += int64(len(content))
+	}
+	return result
+}
+
+func TestCollectManyFiles(t *testing.T) {
+	root := t.TempDir()
+	// More directories than walkWorkers and more files than readWorkers
+	files := syntheticTree(60, 20)
+	writeTree(t, root, files)
+
+	for _, lowMemory := range []bool{false, true} {
+		t.Run(fmt.Sprintf("low memory %v", lowMemory), func(t *testing.T) {
+			result, err := Collect(context.Background(), root, newTestFilter(), Options{MaxFileSizeMB: 1, LowMemory: lowMemory})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer result.Close()
+			checkCollected(t, result, files)
+		})
+	}
+}
+
+func TestCollectConcurrently(t *testing.T) {
+	root := t.TempDir()
+	files := syntheticTree(20, 10)
+	writeTree(t, root, files)
+	filter := newTestFilter()
+
+	// Collections sharing a filter must not interfere with each other
+	var wg sync.WaitGroup
+	results := make([]*CollectionResult, 8)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = Collect(context.Background(), root, filter, Options{MaxFileSizeMB: 1})
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if errs[i] != nil {
+			t.Fatalf("collection %d: %v", i, errs[i])
+		}
+		checkCollected(t, result, files)
+		result.Close()
+	}
+}
+
+func TestCollectCanceled(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, syntheticTree(5, 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Collect(ctx, root, newTestFilter(), Options{MaxFileSizeMB: 1})
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want ErrCanceled wrapping context.Canceled", err)
+	}
+}
+
+func TestReadFilesStopsWhenCanceled(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, syntheticTree(10, 50))
+	jobs, err := newWalker(root, newTestFilter(), Options{}).walk(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, wait := readFiles(ctx, jobs, Options{MaxFileSizeMB: 1}, nil, false)
+
+	// Cancel after the first result; the pool must wind down and close the
+	// channel without anyone reading the rest
+	read := 0
+	for range results {
+		if read == 0 {
+			cancel()
+		}
+		read++
+	}
+	if err := wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("wait() = %v, want context.Canceled", err)
+	}
+	if read >= len(jobs) {
+		t.Errorf("read all %d jobs despite the cancellation", read)
+	}
+}
+
+func TestReadFilesProgress(t *testing.T) {
+	root := t.TempDir()
+	files := syntheticTree(10, 30)
+	writeTree(t, root, files)
+	jobs, err := newWalker(root, newTestFilter(), Options{}).walk(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The progress printer polls the counter the workers update
+	results, wait := readFiles(context.Background(), jobs, Options{MaxFileSizeMB: 1}, nil, true)
+	read := 0
+	for res := range results {
+		if res.err != nil {
+			t.Errorf("%s: %v", res.relPath, res.err)
+		}
+		read++
+	}
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	if read != len(files) {
+		t.Errorf("read %d files, want %d", read, len(files))
+	}
+}
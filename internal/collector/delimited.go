@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	beginFilePrefix = "-----BEGIN FILE "
+	endFilePrefix   = "-----END FILE "
+	delimiterSuffix = " -----"
+)
+
+// ErrChecksum is returned by ParseDelimited when a file's content does not
+// match the checksum in its BEGIN line
+var ErrChecksum = errors.New("checksum mismatch")
+
+// writeDelimited writes each file between BEGIN and END FILE lines. The
+// BEGIN line carries the content's exact size and SHA-256, so a reader
+// never has to guess where a file ends and content that mimics the
+// delimiters is harmless:
+//
+//	-----BEGIN FILE src/main.go size=42 sha256=9f86d0... -----
+//	...42 bytes...
+//	-----END FILE src/main.go -----
+func writeDelimited(bw *bufio.Writer, result *CollectionResult, opts FormatOptions) error {
+	for i, file := range result.Files {
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return err
+		}
+
+		relPath := file.RelPath
+		if opts.Reproducible {
+			relPath = filepath.ToSlash(relPath)
+		}
+		name := delimitedPath(relPath)
+		sum := sha256.Sum256([]byte(content))
+
+		fmt.Fprintf(bw, "%s%s size=%d sha256=%s", beginFilePrefix, name, len(content), hex.EncodeToString(sum[:]))
+		if file.lines != "" {
+			ranges := strings.TrimSuffix(strings.TrimPrefix(file.lines, " (lines "), ")")
+			fmt.Fprintf(bw, " lines=%s", strings.ReplaceAll(ranges, " ", ""))
+		}
+		if opts.Metadata {
+			for _, field := range metadataFields(file) {
+				bw.WriteString(" " + field)
+			}
+		}
+		bw.WriteString(delimiterSuffix + "\n")
+		bw.WriteString(content)
+		if !strings.HasSuffix(content, "\n") {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%s%s%s\n", endFilePrefix, name, delimiterSuffix)
+
+		if i < len(result.Files)-1 {
+			bw.WriteString("\n")
+		}
+	}
+	return nil
+}
+
+// delimitedPath quotes relPath when it contains spaces, quotes, or bytes
+// that are not printable UTF-8, so the path is always one field and always
+// exact
+func delimitedPath(relPath string) string {
+	if relPath == "" || strings.ContainsAny(relPath, " \"") || strconv.Quote(relPath) != `"`+relPath+`"` {
+		return strconv.Quote(relPath)
+	}
+	return relPath
+}
+
+// ParseDelimited reads a payload written with FormatOptions.Delimited.
+// RelPath, Content, and Size are populated, as are Charset and EndOfLine
+// when written, and Partial reports files limited to line ranges. Text
+// outside the delimiters (a preamble or table of contents) is skipped.
+// Content is verified against its checksum.
+func ParseDelimited(r io.Reader) ([]FileData, error) {
+	br := bufio.NewReader(r)
+
+	var files []FileData
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return files, nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, beginFilePrefix) {
+			continue
+		}
+
+		file, sum, err := parseBeginLine(strings.TrimSuffix(line, "\n"))
+		if err != nil {
+			return nil, err
+		}
+
+		content := make([]byte, file.Size)
+		if _, err := io.ReadFull(br, content); err != nil {
+			return nil, fmt.Errorf("%s: truncated content: %w", file.RelPath, err)
+		}
+		if got := sha256.Sum256(content); !bytes.Equal(got[:], sum) {
+			return nil, fmt.Errorf("%s: %w", file.RelPath, ErrChecksum)
+		}
+
+		// Content without a trailing newline is followed by one before END
+		end, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if end == "\n" && !bytes.HasSuffix(content, []byte("\n")) {
+			end, err = br.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+		}
+		if !strings.HasPrefix(end, endFilePrefix) {
+			return nil, fmt.Errorf("%s: missing END FILE line", file.RelPath)
+		}
+
+		file.Content = string(content)
+		files = append(files, file)
+	}
+}
+
+// parseBeginLine parses the path and attributes of a BEGIN FILE line
+func parseBeginLine(line string) (FileData, []byte, error) {
+	rest, ok := strings.CutSuffix(strings.TrimPrefix(line, beginFilePrefix), delimiterSuffix)
+	if !ok {
+		return FileData{}, nil, fmt.Errorf("malformed BEGIN FILE line: %q", line)
+	}
+
+	var file FileData
+	if strings.HasPrefix(rest, `"`) {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return FileData{}, nil, fmt.Errorf("malformed path in BEGIN FILE line: %q", line)
+		}
+		file.RelPath, _ = strconv.Unquote(quoted)
+		rest = rest[len(quoted):]
+	} else {
+		file.RelPath, rest, _ = strings.Cut(rest, " ")
+	}
+
+	var sum []byte
+	file.Size = -1
+	for _, field := range strings.Fields(rest) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return FileData{}, nil, fmt.Errorf("%s: malformed size %q", file.RelPath, value)
+			}
+			file.Size = size
+		case "sha256":
+			decoded, err := hex.DecodeString(value)
+			if err != nil || len(decoded) != sha256.Size {
+				return FileData{}, nil, fmt.Errorf("%s: malformed sha256 %q", file.RelPath, value)
+			}
+			sum = decoded
+		case "lines":
+			file.lines = " (lines " + strings.ReplaceAll(value, ",", ", ") + ")"
+		default:
+			setMetadataField(&file, key, value)
+		}
+	}
+	if file.Size < 0 || sum == nil {
+		return FileData{}, nil, fmt.Errorf("%s: BEGIN FILE line needs size and sha256", file.RelPath)
+	}
+	return file, sum, nil
+}
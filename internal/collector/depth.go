@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// DepthRule overrides Options.MaxDepth for paths matching a glob
+type DepthRule struct {
+	Pattern string
+	Depth   int
+
+	glob glob.Glob
+}
+
+// ParseDepthRule parses a "glob=depth" rule such as "docs/**=2". The glob
+// is matched against slash-separated paths relative to the root, and depth
+// is counted from the root like MaxDepth (0 = unlimited).
+func ParseDepthRule(s string) (DepthRule, error) {
+	pattern, value, ok := strings.Cut(s, "=")
+	if !ok || pattern == "" {
+		return DepthRule{}, fmt.Errorf("invalid depth rule %q: want glob=depth", s)
+	}
+
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return DepthRule{}, fmt.Errorf("invalid depth rule %q: depth must be a non-negative integer", s)
+	}
+
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return DepthRule{}, fmt.Errorf("invalid depth rule %q: %w", s, err)
+	}
+	return DepthRule{Pattern: pattern, Depth: depth, glob: g}, nil
+}
+
+// pathDepth counts the components of relPath, so files in the root have
+// depth 1 and a directory has the same depth as a file in its place
+func pathDepth(relPath string) int {
+	return strings.Count(filepath.ToSlash(relPath), "/") + 1
+}
+
+// depthLimit returns the depth limit for relPath: that of the first
+// matching rule, or MaxDepth
+func depthLimit(opts Options, relPath string) int {
+	slashPath := filepath.ToSlash(relPath)
+	for _, rule := range opts.DepthRules {
+		if rule.glob.Match(slashPath) {
+			return rule.Depth
+		}
+	}
+	return opts.MaxDepth
+}
+
+// withinDepth reports whether the file at relPath is within its depth limit
+func withinDepth(opts Options, relPath string) bool {
+	limit := depthLimit(opts, relPath)
+	return limit == 0 || pathDepth(relPath) <= limit
+}
+
+// walkDepth returns the file depth the walk must reach to find every file
+// any limit allows (0 = unlimited). Rules can only be checked against
+// files, so with rules present the walk goes as deep as the loosest one.
+func walkDepth(opts Options) int {
+	depth := opts.MaxDepth
+	if depth == 0 {
+		return 0
+	}
+	for _, rule := range opts.DepthRules {
+		if rule.Depth == 0 {
+			return 0
+		}
+		depth = max(depth, rule.Depth)
+	}
+	return depth
+}
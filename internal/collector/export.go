@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportFiles writes every collected file into dir, mirroring the relative
+// layout of the scanned tree, and returns the number of bytes written
+func ExportFiles(result *CollectionResult, dir string) (int64, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	for _, file := range result.Files {
+		target := filepath.Join(absDir, file.RelPath)
+		if !strings.HasPrefix(target, absDir+string(os.PathSeparator)) {
+			return written, fmt.Errorf("refusing to write %s outside of %s", file.RelPath, dir)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return written, err
+		}
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			return written, err
+		}
+		written += int64(len(content))
+	}
+
+	return written, nil
+}
@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "export")
+	result := resultOf(map[string]string{"main.go": "package main\n", "pkg/util.go": "package pkg\n"})
+	written, err := ExportFiles(result, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != result.TotalSize {
+		t.Errorf("ExportFiles wrote %d bytes, want %d", written, result.TotalSize)
+	}
+	for _, file := range result.Files {
+		data, err := os.ReadFile(filepath.Join(dir, file.RelPath))
+		if err != nil || string(data) != file.Content {
+			t.Errorf("%s = %q, %v; want %q", file.RelPath, data, err, file.Content)
+		}
+	}
+}
+
+func TestExportFilesRefusesToLeaveDir(t *testing.T) {
+	tests := []struct {
+		name, relPath string
+	}{
+		{"parent", "../escaped.go"},
+		{"nested parent", "pkg/../../escaped.go"},
+		{"sibling with the same prefix", "../export-other/escaped.go"},
+		{"the directory itself", "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			dir := filepath.Join(base, "export")
+			result := resultOf(map[string]string{filepath.FromSlash(tt.relPath): "package escaped\n"})
+			_, err := ExportFiles(result, dir)
+			if err == nil || !strings.Contains(err.Error(), "outside of") {
+				t.Fatalf("ExportFiles(%q) error = %v, want a refusal", tt.relPath, err)
+			}
+			for _, path := range []string{filepath.Join(base, "escaped.go"), filepath.Join(base, "export-other", "escaped.go")} {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("%s was written (stat error %v)", path, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// minExtLimitKeep is the least of a file worth keeping when it is cut to
+// fit an extension limit; with less room left the file is left out
+const minExtLimitKeep = 1024
+
+// ExtLimit caps the combined size of the selected files with one extension
+type ExtLimit struct {
+	// Ext is the lowercased extension with its dot
+	Ext      string
+	MaxBytes int64
+}
+
+// ParseExtLimit parses the limit on ext, a size such as "200KB" or
+// "1.5 MB" (binary units, like FormatSize). The extension may be given
+// with or without its dot.
+func ParseExtLimit(ext, limit string) (ExtLimit, error) {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" || ext == "." {
+		return ExtLimit{}, fmt.Errorf("invalid extension limit: empty extension")
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	size, ok := parseSize(strings.ToUpper(strings.TrimSpace(limit)))
+	if !ok {
+		return ExtLimit{}, fmt.Errorf("invalid extension limit %s: %q: want a size such as 200KB", ext, limit)
+	}
+	return ExtLimit{Ext: ext, MaxBytes: size}, nil
+}
+
+// applyExtLimits enforces Options.ExtLimits on the files that passed their
+// quotas. The files of a limited extension are taken in quota order (see
+// Options.QuotaOrder); the first that doesn't fit is cut to the room left,
+// unless that is under minExtLimitKeep, and the rest are left out. Entry
+// points are exempt and don't count. Files left out or cut are returned
+// with the number of bytes that didn't make it, and the paths of those
+// left out in dropped.
+func applyExtLimits(jobs []fileJob, opts Options) (kept []fileJob, limited []SkippedFile, dropped []string) {
+	if len(opts.ExtLimits) == 0 {
+		return jobs, nil, nil
+	}
+
+	limits := make(map[string]int64, len(opts.ExtLimits))
+	for _, limit := range opts.ExtLimits {
+		limits[limit.Ext] = limit.MaxBytes
+	}
+	byExt := make(map[string][]fileJob)
+	for _, job := range jobs {
+		ext := strings.ToLower(filepath.Ext(job.relPath))
+		if _, ok := limits[ext]; !ok || isEntryPoint(opts, job.relPath) {
+			kept = append(kept, job)
+			continue
+		}
+		byExt[ext] = append(byExt[ext], job)
+	}
+
+	for ext, matching := range byExt {
+		sort.Slice(matching, func(a, b int) bool {
+			if opts.QuotaOrder == QuotaLargestFirst && matching[a].size != matching[b].size {
+				return matching[a].size > matching[b].size
+			}
+			return matching[a].relPath < matching[b].relPath
+		})
+
+		room := limits[ext]
+		for _, job := range matching {
+			switch {
+			case job.size <= room:
+				room -= job.size
+			case room >= minExtLimitKeep:
+				slog.Debug("file truncated: over extension limit", "path", job.relPath, "ext", ext)
+				job.keep = room
+				limited = append(limited, SkippedFile{RelPath: job.relPath, Size: job.size - room})
+				room = 0
+			default:
+				slog.Debug("file excluded: over extension limit", "path", job.relPath, "ext", ext)
+				limited = append(limited, SkippedFile{RelPath: job.relPath, Size: job.size})
+				dropped = append(dropped, job.relPath)
+				room = 0
+				continue
+			}
+			kept = append(kept, job)
+		}
+	}
+
+	sort.Slice(limited, func(a, b int) bool {
+		return limited[a].RelPath < limited[b].RelPath
+	})
+	return kept, limited, dropped
+}
+
+// truncateToLimit keeps the first keep bytes of content, cut at a line
+// break where one is near, and notes what was cut and which extension's
+// limit it was cut to fit
+func truncateToLimit(content string, keep int64, ext string) string {
+	cut := int(keep)
+	if i := strings.LastIndexByte(content[:cut], '\n'); i >= cut/2 {
+		cut = i + 1
+	}
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n[... truncated by bcopy to fit the %s limit: %d of %d bytes shown]\n", content[:cut], ext, cut, len(content))
+}
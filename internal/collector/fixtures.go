@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+)
+
+// maxTestScan caps how much of each test file is searched for golden file
+// references
+const maxTestScan = 1 << 20
+
+// selectReferencedGoldens drops golden files that Options.WithFixtures
+// selected but no test refers to. A golden file counts as referenced when
+// its name, or its name without the .golden extension, appears in a test
+// file of the directory that owns it (see analyzer.FixtureOwner). Golden
+// files the filter selects on their own are always kept.
+func selectReferencedGoldens(rootPath string, filter *analyzer.Filter, jobs []fileJob) []fileJob {
+	tests := make(map[string]string) // owner directory -> its test sources
+	kept := jobs[:0]
+	for _, job := range jobs {
+		if !analyzer.IsGolden(job.relPath) || filter.ShouldInclude(job.relPath) {
+			kept = append(kept, job)
+			continue
+		}
+
+		owner := analyzer.FixtureOwner(job.relPath)
+		source, ok := tests[owner]
+		if !ok {
+			source = readTestSources(filepath.Join(rootPath, filepath.FromSlash(owner)))
+			tests[owner] = source
+		}
+
+		name := path.Base(filepath.ToSlash(job.relPath))
+		stem := strings.TrimSuffix(name, ".golden")
+		if strings.Contains(source, name) || (stem != name && strings.Contains(source, stem)) {
+			kept = append(kept, job)
+			continue
+		}
+		slog.Debug("file excluded: golden file not referenced by a test", "path", job.relPath, "owner", owner)
+	}
+	return kept
+}
+
+// readTestSources returns the concatenated test files directly in dir
+func readTestSources(dir string) string {
+	entries, err := os.ReadDir(longPath(dir))
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, d := range entries {
+		if d.IsDir() || !analyzer.IsTestPath(d.Name()) {
+			continue
+		}
+		f, err := os.Open(longPath(filepath.Join(dir, d.Name())))
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(io.LimitReader(f, maxTestScan))
+		f.Close()
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
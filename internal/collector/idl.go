@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/idl"
+)
+
+// idlSeed reports whether a file starts the Options.IDL import closure: an
+// IDL file inside Options.Within that matched Options.Grep, or an entry point
+func idlSeed(opts Options, relPath string, matched bool) bool {
+	if isEntryPoint(opts, relPath) {
+		return true
+	}
+	if !idl.IsIDL(relPath) || (opts.Grep != nil && !matched) {
+		return false
+	}
+	if len(opts.Within) == 0 {
+		return true
+	}
+	relPath = filepath.ToSlash(relPath)
+	for _, dir := range opts.Within {
+		if dir == "." || strings.HasPrefix(relPath, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// selectImportClosure keeps the seed files and every file they import,
+// directly or transitively
+func selectImportClosure(files []FileData) []FileData {
+	index := make(map[string]int, len(files))
+	known := make(map[string]bool, len(files))
+	for i, file := range files {
+		relPath := filepath.ToSlash(file.RelPath)
+		index[relPath] = i
+		known[relPath] = true
+	}
+
+	keep := make([]bool, len(files))
+	var queue []int
+	for i, file := range files {
+		if file.seed {
+			keep[i] = true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		file := files[queue[0]]
+		queue = queue[1:]
+		for _, target := range file.imports {
+			resolved, ok := idl.Resolve(file.RelPath, target, known)
+			if !ok {
+				continue
+			}
+			if i := index[resolved]; !keep[i] {
+				keep[i] = true
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	selected := make([]FileData, 0)
+	for i, file := range files {
+		if keep[i] {
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
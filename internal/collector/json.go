@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// jsonFile is one line of the JSON Lines format
+type jsonFile struct {
+	Path      string `json:"path"`
+	Language  string `json:"language,omitempty"`
+	Lines     string `json:"lines,omitempty"`
+	Charset   string `json:"charset,omitempty"`
+	EndOfLine string `json:"end_of_line,omitempty"`
+	Content   string `json:"content"`
+}
+
+// writeJSONString writes the whole markdown payload as one JSON string on
+// a single line, ready to be placed in an API request body
+func writeJSONString(w io.Writer, result *CollectionResult, opts FormatOptions) error {
+	opts.JSONString = false
+	var sb strings.Builder
+	if err := WriteMarkdown(&sb, result, opts); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sb.String())
+}
+
+// writeJSONLines writes one JSON object per file and line, with its path,
+// language, line ranges of partial files, charset and line endings with
+// FormatOptions.Metadata, and content. The preamble, table of contents,
+// and appendix have no place in it and are left out.
+func writeJSONLines(w io.Writer, result *CollectionResult, opts FormatOptions) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	for _, file := range result.Files {
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return err
+		}
+
+		relPath := file.RelPath
+		if opts.Reproducible {
+			relPath = filepath.ToSlash(relPath)
+		}
+		ranges := strings.TrimSuffix(strings.TrimPrefix(file.lines, " (lines "), ")")
+		line := jsonFile{
+			Path:     relPath,
+			Language: file.Language,
+			Lines:    strings.ReplaceAll(ranges, " ", ""),
+			Content:  content,
+		}
+		if opts.Metadata {
+			line.Charset = file.Charset
+			line.EndOfLine = file.EndOfLine
+		}
+		err = enc.Encode(line)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// communityFiles are top-level files describing how a repository is run,
+// matched by upper-case name without extension
+var communityFiles = map[string]bool{
+	"LICENSE": true, "LICENCE": true, "COPYING": true, "NOTICE": true,
+	"CODE_OF_CONDUCT": true, "SECURITY": true, "SUPPORT": true, "GOVERNANCE": true,
+	"CODEOWNERS": true, "MAINTAINERS": true, "AUTHORS": true, "CITATION": true,
+}
+
+// isRepoMeta reports whether relPath is repository metadata selected by
+// Options.WithMeta: everything under .github/ (issue and pull request
+// templates, workflows, CODEOWNERS, dependabot and funding config) and
+// top-level community files such as LICENSE, CODE_OF_CONDUCT, and SECURITY
+func isRepoMeta(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if strings.HasPrefix(relPath, ".github/") {
+		return true
+	}
+	if strings.Contains(relPath, "/") {
+		return false
+	}
+	base := strings.ToUpper(relPath)
+	return communityFiles[strings.TrimSuffix(base, path.Ext(base))]
+}
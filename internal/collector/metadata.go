@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// metadataSuffixPattern matches the header annotation written with
+// FormatOptions.Metadata
+var metadataSuffixPattern = regexp.MustCompile(` \[(?:[a-z_]+=[a-z0-9-]+ ?)+\]$`)
+
+// metadataFields lists the charset and end_of_line of file as key=value
+// fields, leaving out unknown values
+func metadataFields(file FileData) []string {
+	var fields []string
+	if file.Charset != "" {
+		fields = append(fields, "charset="+file.Charset)
+	}
+	if file.EndOfLine != "" {
+		fields = append(fields, "end_of_line="+file.EndOfLine)
+	}
+	return fields
+}
+
+// metadataSuffix annotates a file header with the metadata fields of file,
+// as in " [charset=latin1 end_of_line=crlf]"
+func metadataSuffix(file FileData) string {
+	fields := metadataFields(file)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(fields, " "))
+}
+
+// setMetadataField sets the field of file named by key, reporting whether
+// key is a metadata field
+func setMetadataField(file *FileData, key, value string) bool {
+	switch key {
+	case "charset":
+		file.Charset = value
+	case "end_of_line":
+		file.EndOfLine = value
+	default:
+		return false
+	}
+	return true
+}
+
+// cutMetadataSuffix removes the metadata annotation from a file header
+// line, recording its fields in file
+func cutMetadataSuffix(line string, file *FileData) string {
+	suffix := metadataSuffixPattern.FindString(line)
+	if suffix == "" {
+		return line
+	}
+	for _, field := range strings.Fields(strings.Trim(suffix, " []")) {
+		key, value, _ := strings.Cut(field, "=")
+		setMetadataField(file, key, value)
+	}
+	return strings.TrimSuffix(line, suffix)
+}
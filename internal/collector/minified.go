@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MinifiedMode decides what happens to files that look minified
+type MinifiedMode string
+
+const (
+	// MinifiedSkip leaves minified files out, listing them in
+	// CollectionResult.Minified
+	MinifiedSkip MinifiedMode = "skip"
+	// MinifiedTruncate keeps the start of minified files with a note
+	MinifiedTruncate MinifiedMode = "truncate"
+	// MinifiedKeep treats minified files like any other
+	MinifiedKeep MinifiedMode = "keep"
+)
+
+const (
+	// minifiedAvgLine is the average line length above which a file is
+	// minified. Hand-written code averages well under 100.
+	minifiedAvgLine = 300
+	// minifiedMaxLine is the length of a single line that marks a file
+	// as minified however short its other lines are, as in a bundle with
+	// a license header
+	minifiedMaxLine = 5000
+	// minifiedMinSize keeps small one-liners, such as a short JSON file,
+	// from counting as minified
+	minifiedMinSize = 1024
+	// minifiedKeep is how much of a minified file MinifiedTruncate keeps
+	minifiedKeep = 500
+)
+
+// IsMinified reports whether content looks minified or obfuscated, such as
+// webpack output or a vendored bundle: its lines are very long on average,
+// or one line is extremely long
+func IsMinified(content string) bool {
+	if len(content) < minifiedMinSize {
+		return false
+	}
+	lines := 0
+	for line := range strings.Lines(content) {
+		lines++
+		if len(line) > minifiedMaxLine {
+			return true
+		}
+	}
+	return len(content)/lines > minifiedAvgLine
+}
+
+// truncateMinified keeps the start of a minified file and notes what was
+// cut
+func truncateMinified(content string) string {
+	cut := minifiedKeep
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n[... minified file truncated by bcopy: %d of %d bytes shown]\n", content[:cut], cut, len(content))
+}
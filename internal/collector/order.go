@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Output ranks: files sort by rank, then by path
+const (
+	rankEntry = iota
+	rankReadme
+	rankDoc
+	rankNestedDoc
+	rankMeta
+	rankDefault
+)
+
+// docRank reports whether relPath is project documentation selected by
+// Options.WithDocs and, if so, its rank: the top-level README first, then
+// other top-level docs, then everything else
+func docRank(relPath string) (int, bool) {
+	relPath = filepath.ToSlash(relPath)
+	base := strings.ToUpper(filepath.Base(relPath))
+	topLevel := !strings.Contains(relPath, "/")
+
+	switch {
+	case strings.HasPrefix(base, "README"):
+		if topLevel {
+			return rankReadme, true
+		}
+		return rankNestedDoc, true
+	case strings.HasPrefix(base, "CONTRIBUTING"), base == "ARCHITECTURE.MD":
+		if topLevel {
+			return rankDoc, true
+		}
+		return rankNestedDoc, true
+	case strings.HasPrefix(relPath, "docs/") && strings.EqualFold(filepath.Ext(relPath), ".md"):
+		return rankNestedDoc, true
+	}
+	return 0, false
+}
+
+// entryPointSuffix annotates the header of files listed in
+// Options.EntryPoints
+const entryPointSuffix = " (entry point)"
+
+// isEntryPoint reports whether relPath is listed in opts.EntryPoints
+func isEntryPoint(opts Options, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, entry := range opts.EntryPoints {
+		if entry == relPath {
+			return true
+		}
+	}
+	return false
+}
+
+// dirWithin reports whether the walk should enter relDir given
+// Options.Within: it must lead to or lie inside one of the directories
+func dirWithin(within []string, relDir string) bool {
+	if len(within) == 0 {
+		return true
+	}
+	relDir = filepath.ToSlash(relDir)
+	for _, dir := range within {
+		if relDir == dir || strings.HasPrefix(dir, relDir+"/") || strings.HasPrefix(relDir, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fileWithin reports whether relPath is selected by Options.Within: it is
+// directly in the root or inside one of the directories
+func fileWithin(within []string, relPath string) bool {
+	if len(within) == 0 {
+		return true
+	}
+	relPath = filepath.ToSlash(relPath)
+	if !strings.Contains(relPath, "/") {
+		return true
+	}
+	for _, dir := range within {
+		if strings.HasPrefix(relPath, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
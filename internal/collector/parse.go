@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+const fileHeaderPrefix = "File: ./"
+
+// ParsePayload reads a payload in either output format, telling them apart
+// by whichever kind of file marker comes first
+func ParsePayload(r io.Reader) ([]FileData, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, beginFilePrefix) {
+			return ParseDelimited(strings.NewReader(text))
+		}
+		if strings.HasPrefix(line, fileHeaderPrefix) {
+			break
+		}
+	}
+	return ParseMarkdown(strings.NewReader(text))
+}
+
+// ParseMarkdown reads a payload produced by WriteMarkdown back into its
+// files. Only RelPath, Content, Language, Size, and Partial are populated,
+// plus Charset and EndOfLine from headers written with
+// FormatOptions.Metadata. A table of contents, if present, is skipped, and
+// aliased paths are expanded using the alias legend.
+func ParseMarkdown(r io.Reader) ([]FileData, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// A header is "File: ./path", a blank line, then an opening fence.
+	// Headers are only recognized outside code blocks, and a block ends
+	// only at its own fence, so content that mimics the layout is kept
+	// verbatim.
+	isHeader := func(i int) bool {
+		return strings.HasPrefix(lines[i], fileHeaderPrefix) &&
+			i+2 < len(lines) && lines[i+1] == "" && strings.HasPrefix(lines[i+2], "```")
+	}
+
+	var files []FileData
+	var aliases []PathAlias
+	for i := 0; i < len(lines); i++ {
+		if !isHeader(i) {
+			continue
+		}
+		if len(files) == 0 {
+			aliases = parseAliasLegend(lines[:i])
+		}
+
+		opening := lines[i+2]
+		fence := opening[:len(opening)-len(strings.TrimLeft(opening, "`"))]
+
+		end := i + 3
+		for end < len(lines) && lines[end] != fence {
+			end++
+		}
+		body := lines[i+3 : end]
+
+		content := strings.Join(body, "\n")
+		if len(body) > 0 {
+			content += "\n"
+		}
+		var file FileData
+		header := cutMetadataSuffix(lines[i], &file)
+		file.RelPath = unaliasPath(headerPath(header), aliases)
+		file.Content = content
+		file.Size = int64(len(content))
+		file.Language = strings.TrimPrefix(opening, fence)
+		file.lines = linesSuffix.FindString(strings.TrimSuffix(header, entryPointSuffix))
+		files = append(files, file)
+		i = end
+	}
+
+	return files, nil
+}
+
+// headerPath extracts the path from a file header line, dropping the line
+// range and entry point annotations and undoing MarkdownPath's escaping
+func headerPath(line string) string {
+	p := strings.TrimPrefix(line, fileHeaderPrefix)
+	p = linesSuffix.ReplaceAllString(p, "")
+	p = strings.TrimSuffix(p, entryPointSuffix)
+	return UnescapeMarkdownPath(p)
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RootPart names the part holding the files at the top of the tree
+const RootPart = "_root"
+
+// Part is one payload of a partitioned result
+type Part struct {
+	Name   string
+	Result *CollectionResult
+	// Oversized is true when the part is a single file that alone exceeds
+	// the token budget
+	Oversized bool
+}
+
+// PartitionByDir groups the files of result by top-level directory, with
+// the files at the top in RootPart. A group whose estimated tokens exceed
+// maxTokens is split, in output order, into numbered parts (api-1, api-2,
+// ...) that each stay within it (0 = no limit). Parts share storage with
+// result, so only result needs to be closed.
+func PartitionByDir(result *CollectionResult, maxTokens int64) []Part {
+	groups := make(map[string][]FileData)
+	for _, file := range result.Files {
+		name := RootPart
+		if top, _, found := strings.Cut(filepath.ToSlash(file.RelPath), "/"); found {
+			name = top
+		}
+		groups[name] = append(groups[name], file)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []Part
+	for _, name := range names {
+		var chunks [][]FileData
+		var tokens int64
+		for _, file := range groups[name] {
+			fileTokens := EstimateTokens(file.Size)
+			if len(chunks) == 0 || (maxTokens > 0 && tokens+fileTokens > maxTokens) {
+				chunks = append(chunks, nil)
+				tokens = 0
+			}
+			chunks[len(chunks)-1] = append(chunks[len(chunks)-1], file)
+			tokens += fileTokens
+		}
+
+		for i, files := range chunks {
+			part := Part{Name: name, Result: &CollectionResult{Files: files, FileCount: len(files), spill: result.spill}}
+			if len(chunks) > 1 {
+				part.Name = fmt.Sprintf("%s-%d", name, i+1)
+			}
+			for _, file := range files {
+				part.Result.TotalSize += file.Size
+			}
+			part.Oversized = maxTokens > 0 && EstimateTokens(part.Result.TotalSize) > maxTokens
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPath is the length at which Windows APIs start rejecting paths that
+// lack the \\?\ prefix (MAX_PATH minus room for an 8.3 file name)
+const maxPath = 248
+
+// longPath returns path in a form the OS can open regardless of length. On
+// Windows, paths at or beyond MAX_PATH are made absolute and given the
+// \\?\ (or \\?\UNC\) prefix; everywhere else path is returned unchanged.
+func longPath(path string) string {
+	if runtime.GOOS != "windows" || len(path) < maxPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
+
+// DisplayPath renders relPath for a file header or terminal line. Control
+// characters (a newline in a file name would otherwise end the header) are
+// written as Go-style escapes, and bytes that are not valid UTF-8 become
+// U+FFFD. The result is for display only; it may not name the file.
+func DisplayPath(relPath string) string {
+	if isPlainPath(relPath) {
+		return relPath
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(relPath); {
+		r, size := utf8.DecodeRuneInString(relPath[i:])
+		i += size
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			sb.WriteRune(utf8.RuneError)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < utf8.RuneSelf && unicode.IsControl(r):
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case unicode.IsControl(r) || r == '\u2028' || r == '\u2029':
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// isPlainPath reports whether s is printable ASCII, the overwhelmingly
+// common case that DisplayPath returns as is
+func isPlainPath(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// markdownSpecial are the characters MarkdownPath escapes anywhere in a
+// path: they could close a code span, a table cell, a link, or open HTML
+const markdownSpecial = "`|[]<>"
+
+// markdownLeading are escaped only at the start of a path, where they would
+// begin a list item or heading
+const markdownLeading = "-+#"
+
+// MarkdownPath renders relPath for a file header, table of contents, or
+// manifest: DisplayPath plus a backslash before characters markdown would
+// interpret. UnescapeMarkdownPath reverses the escaping.
+func MarkdownPath(relPath string) string {
+	p := DisplayPath(relPath)
+	if !strings.ContainsAny(p, markdownSpecial) && (p == "" || !strings.ContainsRune(markdownLeading, rune(p[0]))) {
+		return p
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(p); i++ {
+		if strings.IndexByte(markdownSpecial, p[i]) >= 0 || (i == 0 && strings.IndexByte(markdownLeading, p[i]) >= 0) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(p[i])
+	}
+	return sb.String()
+}
+
+// UnescapeMarkdownPath reverses MarkdownPath. A backslash is dropped only
+// before a character MarkdownPath escapes, so Windows separators survive.
+func UnescapeMarkdownPath(p string) string {
+	if !strings.Contains(p, `\`) {
+		return p
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] == '\\' && i+1 < len(p) {
+			next := p[i+1]
+			if strings.IndexByte(markdownSpecial, next) >= 0 || (i == 0 && strings.IndexByte(markdownLeading, next) >= 0) {
+				continue
+			}
+		}
+		sb.WriteByte(p[i])
+	}
+	return sb.String()
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// content, so a file that itself contains ``` (a README, a markdown
+// template) can't close its own code block
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(content); i++ {
+		if content[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDisplayPath(t *testing.T) {
+	tests := []struct {
+		name, relPath, want string
+	}{
+		{"plain", "src/main.go", "src/main.go"},
+		{"unicode", "docs/naïve café.md", "docs/naïve café.md"},
+		{"newline", "a\nb.go", `a\nb.go`},
+		{"carriage return", "a\rb.go", `a\rb.go`},
+		{"tab", "a\tb.go", `a\tb.go`},
+		{"escape", "a\x1b[31mred.go", `a\x1b[31mred.go`},
+		{"delete", "a\x7fb.go", `a\x7fb.go`},
+		{"C1 control", "a\u0085b.go", `a\u0085b.go`},
+		{"line separator", "a\u2028b.go", `a\u2028b.go`},
+		{"invalid UTF-8", "bad\xff\xfe.go", "bad��.go"},
+		{"truncated rune", "cut\xe2\x82.go", "cut��.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DisplayPath(tt.relPath); got != tt.want {
+				t.Errorf("DisplayPath(%q) = %q, want %q", tt.relPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownPathRoundTrip(t *testing.T) {
+	tests := []struct {
+		relPath, want string
+	}{
+		{"src/main.go", "src/main.go"},
+		{"-flag.go", `\-flag.go`},
+		{"a|b`c.go", "a\\|b\\`c.go"},
+		{"[x]/<y>.go", `\[x\]/\<y\>.go`},
+		{`win\dir\file.go`, `win\dir\file.go`},
+	}
+	for _, tt := range tests {
+		got := MarkdownPath(tt.relPath)
+		if got != tt.want {
+			t.Errorf("MarkdownPath(%q) = %q, want %q", tt.relPath, got, tt.want)
+		}
+		if back := UnescapeMarkdownPath(got); back != tt.relPath {
+			t.Errorf("UnescapeMarkdownPath(%q) = %q, want %q", got, back, tt.relPath)
+		}
+	}
+}
+
+func TestLongPath(t *testing.T) {
+	short := filepath.Join("a", "b.go")
+	if got := longPath(short); got != short {
+		t.Errorf("longPath(%q) = %q, want it unchanged", short, got)
+	}
+
+	long := filepath.Join(strings.Repeat("d", 150), strings.Repeat("f", 150)+".go")
+	got := longPath(long)
+	if runtime.GOOS != "windows" {
+		if got != long {
+			t.Errorf("longPath changed %q to %q outside Windows", long, got)
+		}
+		return
+	}
+	if !strings.HasPrefix(got, `\\?\`) || !filepath.IsAbs(strings.TrimPrefix(got, `\\?\`)) {
+		t.Errorf("longPath(%q) = %q, want an absolute \\\\?\\ path", long, got)
+	}
+	if again := longPath(got); again != got {
+		t.Errorf("longPath prefixed %q twice: %q", got, again)
+	}
+}
+
+// TestCollectSpecialNames collects files whose names would corrupt the
+// output if written as is, and checks that each still gets one intact
+// header line and its content
+func TestCollectSpecialNames(t *testing.T) {
+	longDir := filepath.Join(strings.Repeat("a", 200), strings.Repeat("b", 200))
+	tests := []struct {
+		name    string
+		relPath string
+		header  string
+		invalid bool
+	}{
+		{"long path", filepath.Join(longDir, strings.Repeat("c", 200)+".go"), "File: ./" + filepath.ToSlash(longDir) + "/" + strings.Repeat("c", 200) + ".go", false},
+		{"newline", "new\nline.go", `File: ./new\nline.go`, false},
+		{"carriage return", "carriage\rreturn.go", `File: ./carriage\rreturn.go`, false},
+		{"escape sequence", "esc\x1b[2Jape.go", `File: ./esc\x1b\[2Jape.go`, false},
+		{"bell", "bell\x07.go", `File: ./bell\x07.go`, false},
+		{"non-UTF-8", "latin\xe9.go", "File: ./latin�.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, tt.relPath)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Skipf("filesystem rejects the directory: %v", err)
+			}
+			content := "package special\n"
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Skipf("filesystem rejects the name: %v", err)
+			}
+
+			result, err := Collect(context.Background(), root, newTestFilter(), Options{MaxFileSizeMB: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer result.Close()
+			if len(result.Files) != 1 || result.Files[0].RelPath != tt.relPath {
+				t.Fatalf("collected %v, want [%q]; skipped %v", result.Files, tt.relPath, result.Skipped)
+			}
+			if result.Files[0].Content != content {
+				t.Errorf("content = %q, want %q", result.Files[0].Content, content)
+			}
+			if got := slices.Contains(result.InvalidNames, tt.relPath); got != tt.invalid {
+				t.Errorf("InvalidNames = %q, want the file listed: %v", result.InvalidNames, tt.invalid)
+			}
+
+			var out bytes.Buffer
+			if err := WriteMarkdown(&out, result, FormatOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(out.String(), "\n")
+			if !slices.Contains(lines, tt.header) {
+				t.Errorf("no header line %q in:\n%s", tt.header, out.String())
+			}
+			for _, line := range lines {
+				if strings.ContainsAny(line, "\r\x1b\x07") {
+					t.Errorf("raw control character in output line %q", line)
+				}
+			}
+		})
+	}
+}
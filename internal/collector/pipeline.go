@@ -0,0 +1,235 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/idl"
+	"github.com/nodelike/bcopy/internal/language"
+	"github.com/nodelike/bcopy/internal/ui"
+	"golang.org/x/sync/errgroup"
+)
+
+// readWorkers bounds how many files are read concurrently
+const readWorkers = 16
+
+// fileResult is a read file, the path and error of one that failed, or
+// the path, size, and reason of one skipped for its content
+type fileResult struct {
+	data    FileData
+	relPath string
+	err     error
+	skipped int64
+	reason  SkipReason
+}
+
+// readFiles reads jobs on a fixed pool of workers. A feeder hands out jobs
+// one at a time and results come back on a channel with room for one per
+// worker, so memory in flight stays bounded however many files there are.
+// The caller must drain results until it is closed, then call wait for the
+// first error (a failed spill write or the cancellation of ctx).
+func readFiles(ctx context.Context, jobs []fileJob, opts Options, spill *spillFile, progress bool) (<-chan fileResult, func() error) {
+	pending := make(chan fileJob)
+	results := make(chan fileResult, readWorkers)
+
+	var done atomic.Int64
+	stopProgress := func() {}
+	if progress {
+		stopProgress = startProgress(len(jobs), &done)
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(pending)
+		for _, job := range jobs {
+			select {
+			case pending <- job:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+		}
+		return nil
+	})
+
+	for range readWorkers {
+		eg.Go(func() error {
+			for job := range pending {
+				res, ok, err := readJob(job, opts, spill)
+				done.Add(1)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				select {
+				case results <- res:
+				case <-egCtx.Done():
+					return egCtx.Err()
+				}
+			}
+			return nil
+		})
+	}
+
+	var err error
+	finished := make(chan struct{})
+	go func() {
+		err = eg.Wait()
+		stopProgress()
+		close(results)
+		close(finished)
+	}()
+
+	return results, func() error {
+		<-finished
+		return err
+	}
+}
+
+// readJob reads and annotates one file. ok is false for files that are
+// skipped silently (empty, or without a match); unreadable files come back
+// as a result carrying the error, binary and oversized ones as a result
+// carrying their size in skipped.
+func readJob(job fileJob, opts Options, spill *spillFile) (fileResult, bool, error) {
+	declared := declaredSettings(opts, job.fullPath)
+	content, kind, size, skip, err := readFile(job.fullPath, opts.MaxFileSizeMB, declared.Charset)
+	if err != nil {
+		slog.Debug("file unreadable", "path", job.relPath, "error", err)
+		return fileResult{relPath: job.relPath, err: err}, true, nil
+	}
+	if skip {
+		if size == 0 {
+			return fileResult{}, false, nil
+		}
+		return fileResult{relPath: job.relPath, skipped: size, reason: contentSkipReason(size, opts.MaxFileSizeMB)}, true, nil
+	}
+	if opts.Minified != "" && opts.Minified != MinifiedKeep && !isEntryPoint(opts, job.relPath) && IsMinified(content) {
+		if opts.Minified == MinifiedSkip {
+			slog.Debug("file skipped: minified", "path", job.relPath)
+			return fileResult{relPath: job.relPath, skipped: size, reason: SkipMinified}, true, nil
+		}
+		slog.Debug("file truncated: minified", "path", job.relPath)
+		content = truncateMinified(content)
+		size = int64(len(content))
+	}
+	if job.keep > 0 && int64(len(content)) > job.keep {
+		content = truncateToLimit(content, job.keep, strings.ToLower(filepath.Ext(job.relPath)))
+		size = int64(len(content))
+	}
+
+	fileData := FileData{
+		RelPath:   job.relPath,
+		Content:   content,
+		Size:      size,
+		Language:  language.Detect(job.relPath),
+		Charset:   kind.String(),
+		EndOfLine: endOfLine(declared, content),
+		rank:      rankDefault,
+	}
+	if opts.WithMeta && isRepoMeta(job.relPath) {
+		fileData.rank = rankMeta
+	}
+	if opts.WithDocs {
+		if rank, ok := docRank(job.relPath); ok {
+			fileData.rank = rank
+		}
+	}
+	if isEntryPoint(opts, job.relPath) {
+		fileData.rank = rankEntry
+	}
+	if opts.Attributes != nil {
+		if absPath, err := filepath.Abs(job.fullPath); err == nil {
+			if lang := opts.Attributes.Language(absPath); lang != "" {
+				fileData.Language = lang
+			}
+		}
+	}
+
+	if opts.Grep != nil {
+		fileData.matched = opts.Grep.MatchString(fileData.Content)
+	}
+	if opts.IDL {
+		fileData.seed = idlSeed(opts, job.relPath, fileData.matched)
+		fileData.imports = idl.Imports(job.relPath, fileData.Content)
+	}
+
+	// Files placed first (entry points, docs) stay whole
+	if opts.Around != nil && fileData.rank == rankDefault {
+		ranges := aroundRanges(fileData.Content, opts.Around, opts.AroundLines)
+		if ranges == nil {
+			slog.Debug("file excluded: symbol not found", "path", job.relPath)
+			return fileResult{}, false, nil
+		}
+		fileData.Content, fileData.lines = selectLines(fileData.Content, ranges)
+		fileData.Size = int64(len(fileData.Content))
+	}
+
+	if len(opts.Transforms) > 0 {
+		for _, transform := range opts.Transforms {
+			transform(&fileData)
+		}
+		fileData.Size = int64(len(fileData.Content))
+	}
+
+	if spill != nil {
+		offset, err := spill.store(fileData.Content)
+		if err != nil {
+			return fileResult{}, false, err
+		}
+		fileData.spilled = true
+		fileData.spillOffset = offset
+		fileData.spillLen = int64(len(fileData.Content))
+		fileData.Content = ""
+	}
+
+	return fileResult{data: fileData}, true, nil
+}
+
+// startProgress prints the collecting line and a dot for each third of
+// the jobs done, polling the shared counter so workers never block on
+// progress output. The returned stop waits for the printer to exit.
+func startProgress(total int, done *atomic.Int64) (stop func()) {
+	ui.Begin("collect.start")
+
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		printed := 0
+		for {
+			stopping := false
+			select {
+			case <-quit:
+				stopping = true
+			case <-ticker.C:
+			}
+			// Screen readers announce each dot; accessible mode shows none
+			if total > 0 && !ui.Accessible() {
+				if dots := int(done.Load()) * 3 / total; dots > printed {
+					fmt.Fprint(ui.Output, strings.Repeat(".", dots-printed))
+					printed = dots
+				}
+			}
+			if stopping {
+				if printed > 0 {
+					fmt.Fprint(ui.Output, " ")
+				}
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-exited
+	}
+}
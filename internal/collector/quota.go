@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// Quota bounds how much of the files matching a glob is selected, by
+// total on-disk size or by number of files
+type Quota struct {
+	Pattern  string
+	MaxBytes int64 // 0 when the quota counts files
+	MaxFiles int   // 0 when the quota counts bytes
+
+	glob glob.Glob
+}
+
+// sizeUnits are the suffixes ParseQuota accepts, longest first so "KB"
+// isn't read as "B"
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseQuota parses the limit of a quota on pattern: a size such as
+// "200KB" or "1.5 MB" (binary units, like FormatSize), or a file count
+// such as "20 files". The glob is matched against slash-separated paths
+// relative to the root.
+func ParseQuota(pattern, limit string) (Quota, error) {
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return Quota{}, fmt.Errorf("invalid quota %q: %w", pattern, err)
+	}
+	quota := Quota{Pattern: pattern, glob: g}
+
+	value := strings.ToUpper(strings.TrimSpace(limit))
+	if n, ok := strings.CutSuffix(value, "FILES"); ok || strings.HasSuffix(value, "FILE") {
+		if !ok {
+			n = strings.TrimSuffix(value, "FILE")
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil || count <= 0 {
+			return Quota{}, fmt.Errorf("invalid quota %s: %q: want a positive number of files", pattern, limit)
+		}
+		quota.MaxFiles = count
+		return quota, nil
+	}
+
+	if size, ok := parseSize(value); ok {
+		quota.MaxBytes = size
+		return quota, nil
+	}
+	return Quota{}, fmt.Errorf("invalid quota %s: %q: want a size such as 200KB or a count such as 20 files", pattern, limit)
+}
+
+// parseSize reads a positive size with one of sizeUnits, given in upper
+// case
+func parseSize(value string) (int64, bool) {
+	for _, unit := range sizeUnits {
+		if n, ok := strings.CutSuffix(value, unit.suffix); ok {
+			size, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			if err != nil || size <= 0 {
+				return 0, false
+			}
+			return int64(size * unit.bytes), true
+		}
+	}
+	return 0, false
+}
+
+// QuotaOrder decides which files fill a quota first
+type QuotaOrder string
+
+const (
+	// QuotaByPath fills quotas in path order
+	QuotaByPath QuotaOrder = "path"
+	// QuotaLargestFirst fills quotas with the largest files first
+	QuotaLargestFirst QuotaOrder = "largest"
+)
+
+// applyQuotas drops the files that don't fit their quota. Each file counts
+// against the first quota matching it; entry points are exempt. Files are
+// taken in order and a file too large for what is left of a byte quota is
+// skipped, so smaller ones after it can still fit; the outcome depends only
+// on the paths and sizes, never on walk order. Dropped files are returned
+// separately.
+func applyQuotas(jobs []fileJob, opts Options) (kept []fileJob, dropped []SkippedFile) {
+	if len(opts.Quotas) == 0 {
+		return jobs, nil
+	}
+
+	byQuota := make([][]fileJob, len(opts.Quotas))
+	for _, job := range jobs {
+		slashPath := filepath.ToSlash(job.relPath)
+		matched := false
+		for i, quota := range opts.Quotas {
+			if isEntryPoint(opts, job.relPath) {
+				break
+			}
+			if quota.glob.Match(slashPath) {
+				byQuota[i] = append(byQuota[i], job)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			kept = append(kept, job)
+		}
+	}
+
+	for i, quota := range opts.Quotas {
+		matching := byQuota[i]
+		sort.Slice(matching, func(a, b int) bool {
+			if opts.QuotaOrder == QuotaLargestFirst && matching[a].size != matching[b].size {
+				return matching[a].size > matching[b].size
+			}
+			return matching[a].relPath < matching[b].relPath
+		})
+
+		var files int
+		var bytes int64
+		for _, job := range matching {
+			fits := quota.MaxFiles > 0 && files < quota.MaxFiles ||
+				quota.MaxBytes > 0 && bytes+job.size <= quota.MaxBytes
+			if !fits {
+				slog.Debug("file excluded: over quota", "path", job.relPath, "quota", quota.Pattern)
+				dropped = append(dropped, SkippedFile{RelPath: job.relPath, Size: job.size})
+				continue
+			}
+			kept = append(kept, job)
+			files++
+			bytes += job.size
+		}
+	}
+
+	sort.Slice(dropped, func(a, b int) bool {
+		return dropped[a].RelPath < dropped[b].RelPath
+	})
+	return kept, dropped
+}
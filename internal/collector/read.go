@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/detect"
+)
+
+// bufferPool recycles read buffers across files so a large run doesn't
+// allocate (and garbage collect) one buffer per file
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// readFile opens path once, checks its size against maxFileSizeMB, sniffs
+// the first chunk for binary content, then reads the remainder into a pooled
+// buffer. UTF-16 and Latin-1 files are converted to UTF-8; charset is the
+// file's declared charset, if any (see detect.SniffCharset), and kind the
+// encoding the content was decoded from. skip is true for binary, empty,
+// and oversized files.
+func readFile(path string, maxFileSizeMB float64, charset string) (content string, kind detect.Kind, size int64, skip bool, err error) {
+	f, err := os.Open(longPath(path))
+	if err != nil {
+		return "", detect.Binary, 0, false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", detect.Binary, 0, false, err
+	}
+	size = info.Size()
+
+	// Check file size limit
+	if maxFileSizeMB > 0 && float64(size)/(1024*1024) > maxFileSizeMB {
+		slog.Debug("file skipped: too large", "path", path, "size", size, "max_mb", maxFileSizeMB)
+		return "", detect.Binary, size, true, nil
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+	buf.Grow(int(size) + bytes.MinRead)
+
+	n, err := io.CopyN(buf, f, detect.SniffSize)
+	if err != nil && err != io.EOF {
+		return "", detect.Binary, size, false, err
+	}
+	if n == 0 {
+		slog.Debug("file skipped: empty", "path", path)
+		return "", detect.Binary, size, true, nil
+	}
+	kind = detect.SniffCharset(path, buf.Bytes(), charset)
+	if kind == detect.Binary {
+		slog.Debug("file skipped: binary", "path", path)
+		return "", detect.Binary, size, true, nil
+	}
+
+	if _, err := buf.ReadFrom(f); err != nil {
+		return "", detect.Binary, size, false, err
+	}
+
+	if kind != detect.UTF8 {
+		slog.Debug("file decoded", "path", path, "encoding", kind)
+		content, err = detect.Decode(kind, buf.Bytes())
+		return content, kind, size, false, err
+	}
+	return buf.String(), kind, size, false, nil
+}
+
+// declaredSettings returns the .editorconfig settings of the file at path,
+// or none without Options.EditorConfig
+func declaredSettings(opts Options, path string) analyzer.EditorConfigSettings {
+	if opts.EditorConfig == nil {
+		return analyzer.EditorConfigSettings{}
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return analyzer.EditorConfigSettings{}
+	}
+	return opts.EditorConfig.Settings(absPath)
+}
+
+// endOfLine is the declared end_of_line of a file, or else the line
+// endings found in its content
+func endOfLine(declared analyzer.EditorConfigSettings, content string) string {
+	if declared.EndOfLine != "" {
+		return declared.EndOfLine
+	}
+	return detect.LineEnding(content)
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/schema"
+)
+
+// squashedSchemaName names the synthesized file that replaces the
+// migrations of a directory with Options.Schema
+const squashedSchemaName = "schema.squashed.sql"
+
+// isSQL reports whether relPath is selected by Options.Schema
+func isSQL(relPath string) bool {
+	return strings.EqualFold(filepath.Ext(relPath), ".sql")
+}
+
+// squashMigrations replaces the migrations of each directory with one
+// synthesized current-schema file. Down migrations are dropped and other
+// SQL files are kept as they are.
+func squashMigrations(result *CollectionResult, opts Options) error {
+	byDir := make(map[string][]schema.Migration)
+	kept := make([]FileData, 0, len(result.Files))
+	for _, file := range result.Files {
+		relPath := filepath.ToSlash(file.RelPath)
+		m, down, ok := schema.Parse(relPath)
+		if !ok {
+			kept = append(kept, file)
+			continue
+		}
+		if down {
+			continue
+		}
+
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return err
+		}
+		m.Content = content
+		byDir[path.Dir(relPath)] = append(byDir[path.Dir(relPath)], m)
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		relPath := filepath.FromSlash(path.Join(dir, squashedSchemaName))
+		fileData := FileData{
+			RelPath:  relPath,
+			Content:  schema.Squash(byDir[dir]),
+			Language: "sql",
+			rank:     rankDefault,
+		}
+		for _, transform := range opts.Transforms {
+			transform(&fileData)
+		}
+		fileData.Size = int64(len(fileData.Content))
+
+		if result.spill != nil {
+			offset, err := result.spill.store(fileData.Content)
+			if err != nil {
+				return err
+			}
+			fileData.spilled = true
+			fileData.spillOffset = offset
+			fileData.spillLen = fileData.Size
+			fileData.Content = ""
+		}
+		kept = append(kept, fileData)
+	}
+
+	result.Files = kept
+	return nil
+}
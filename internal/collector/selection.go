@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/language"
+)
+
+// Selected is a file chosen explicitly, e.g. from an editor, optionally
+// limited to some of its lines
+type Selected struct {
+	RelPath string
+	Ranges  []LineRange
+}
+
+// LineRange is an inclusive, 1-based range of lines
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// linesSuffix matches the header annotation of files limited to line ranges
+var linesSuffix = regexp.MustCompile(` \(lines [0-9, -]+\)$`)
+
+// CollectSelected reads exactly the selected files, bypassing the walk and
+// every filter. Binary, empty, and oversized files are still skipped, as are
+// .env files without Options.IncludeEnv, and Options.Transforms are applied.
+// Files keep the order of selected unless Options.GroupByDir is set. Files
+// that could not be read or were skipped for their content are recorded
+// in Skipped.
+func CollectSelected(rootPath string, selected []Selected, opts Options) (*CollectionResult, error) {
+	result := &CollectionResult{Files: make([]FileData, 0, len(selected))}
+
+	for _, sel := range selected {
+		if analyzer.IsEnvFile(sel.RelPath) && !analyzer.IsEnvTemplate(sel.RelPath) && !opts.IncludeEnv {
+			slog.Debug("selected file excluded: dotenv file", "path", sel.RelPath)
+			continue
+		}
+
+		fullPath := filepath.Join(rootPath, filepath.FromSlash(sel.RelPath))
+		declared := declaredSettings(opts, fullPath)
+		content, kind, size, skip, err := readFile(fullPath, opts.MaxFileSizeMB, declared.Charset)
+		if err != nil {
+			if os.IsPermission(err) {
+				result.PermissionDenied = append(result.PermissionDenied, sel.RelPath)
+				result.skip(sel.RelPath, SkipPermission, err)
+			} else {
+				result.ReadErrors = append(result.ReadErrors, ReadError{RelPath: sel.RelPath, Err: err})
+				result.skip(sel.RelPath, SkipReadError, err)
+			}
+			continue
+		}
+		if skip {
+			slog.Debug("selected file skipped", "path", sel.RelPath)
+			if size > 0 {
+				result.skip(sel.RelPath, contentSkipReason(size, opts.MaxFileSizeMB), nil)
+			}
+			continue
+		}
+
+		fileData := FileData{
+			RelPath:   filepath.FromSlash(sel.RelPath),
+			Content:   content,
+			Language:  language.Detect(sel.RelPath),
+			Charset:   kind.String(),
+			EndOfLine: endOfLine(declared, content),
+			rank:      rankDefault,
+		}
+		if len(sel.Ranges) > 0 {
+			fileData.Content, fileData.lines = selectLines(content, sel.Ranges)
+		}
+		for _, transform := range opts.Transforms {
+			transform(&fileData)
+		}
+		fileData.Size = int64(len(fileData.Content))
+
+		result.Files = append(result.Files, fileData)
+		result.TotalSize += fileData.Size
+	}
+
+	if opts.GroupByDir {
+		slices.SortStableFunc(result.Files, func(a, b FileData) int {
+			return strings.Compare(fileDir(a.RelPath), fileDir(b.RelPath))
+		})
+	}
+	result.sortSkipped()
+	result.FileCount = len(result.Files)
+	return result, nil
+}
+
+// selectLines keeps the lines of content inside ranges, separating
+// non-adjacent ranges with a "..." line, and returns the header annotation
+func selectLines(content string, ranges []LineRange) (string, string) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	ranges = slices.Clone(ranges)
+	slices.SortFunc(ranges, func(a, b LineRange) int { return a.Start - b.Start })
+
+	var sb strings.Builder
+	var labels []string
+	last := 0
+	for _, r := range ranges {
+		// Overlapping ranges continue where the previous one ended
+		start, end := max(r.Start, 1, last+1), min(r.End, len(lines))
+		if end < start {
+			continue
+		}
+		if last > 0 && start > last+1 {
+			sb.WriteString("...\n")
+		}
+		for _, line := range lines[start-1 : end] {
+			sb.WriteString(line)
+		}
+		last = end
+		labels = append(labels, fmt.Sprintf("%d-%d", start, end))
+	}
+	return sb.String(), fmt.Sprintf(" (lines %s)", strings.Join(labels, ", "))
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"sort"
+)
+
+// SkipReason says why a path was left out of a result
+type SkipReason string
+
+const (
+	// SkipPermission is a file or directory that could not be read due to
+	// permissions
+	SkipPermission SkipReason = "permission denied"
+	// SkipReadError is a file or directory that failed to read for another
+	// reason, carried in SkipRecord.Err
+	SkipReadError SkipReason = "read error"
+	// SkipBinary is a file whose content is not text
+	SkipBinary SkipReason = "binary"
+	// SkipTooLarge is a file larger than Options.MaxFileSizeMB
+	SkipTooLarge SkipReason = "too large"
+	// SkipMinified is a file that looks minified (Options.Minified)
+	SkipMinified SkipReason = "minified"
+	// SkipOverQuota is a file that didn't fit Options.Quotas
+	SkipOverQuota SkipReason = "over quota"
+	// SkipExtLimit is a file with no room left under Options.ExtLimits;
+	// files cut short to fit are kept and not recorded
+	SkipExtLimit SkipReason = "over extension limit"
+	// SkipOtherFileSystem is a directory on another filesystem, not entered
+	// with Options.OneFileSystem
+	SkipOtherFileSystem SkipReason = "other filesystem"
+)
+
+// SkipRecord is a path left out of a result and why
+type SkipRecord struct {
+	Path   string
+	Reason SkipReason
+	// Err is the error behind SkipReadError and SkipPermission
+	Err error
+}
+
+// skip records relPath as left out for reason
+func (r *CollectionResult) skip(relPath string, reason SkipReason, err error) {
+	r.Skipped = append(r.Skipped, SkipRecord{Path: relPath, Reason: reason, Err: err})
+}
+
+// sortSkipped orders the skip records by path
+func (r *CollectionResult) sortSkipped() {
+	sort.SliceStable(r.Skipped, func(i, j int) bool {
+		return r.Skipped[i].Path < r.Skipped[j].Path
+	})
+}
+
+// contentSkipReason tells a file skipped for its content as too large or
+// binary, given its size on disk
+func contentSkipReason(size int64, maxFileSizeMB float64) SkipReason {
+	if maxFileSizeMB > 0 && float64(size)/(1024*1024) > maxFileSizeMB {
+		return SkipTooLarge
+	}
+	return SkipBinary
+}
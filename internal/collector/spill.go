@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// spillFile holds file contents on disk during low-memory collection so
+// the result only keeps offsets in memory
+type spillFile struct {
+	mu     sync.Mutex
+	f      *os.File
+	offset int64
+}
+
+func newSpillFile() (*spillFile, error) {
+	f, err := os.CreateTemp("", "bcopy-spill-*")
+	if err != nil {
+		return nil, err
+	}
+
+	// Unlink right away where the OS allows it, so the data disappears even
+	// if the process exits without calling close
+	if runtime.GOOS != "windows" {
+		os.Remove(f.Name())
+	}
+	return &spillFile{f: f}, nil
+}
+
+// store appends content and returns its offset
+func (s *spillFile) store(content string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset := s.offset
+	n, err := io.WriteString(s.f, content)
+	s.offset += int64(n)
+	return offset, err
+}
+
+// load reads back length bytes stored at offset. ReadAt is safe to call
+// concurrently with store since stored regions are never rewritten.
+func (s *spillFile) load(offset, length int64) (string, error) {
+	buf := make([]byte, length)
+	if _, err := s.f.ReadAt(buf, offset); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (s *spillFile) close() error {
+	name := s.f.Name()
+	err := s.f.Close()
+	if rmErr := os.Remove(name); err == nil && !os.IsNotExist(rmErr) {
+		err = rmErr
+	}
+	return err
+}
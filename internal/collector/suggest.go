@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// suggestMinSkipped is how much skipped content a directory needs
+	// before excluding it is suggested
+	suggestMinSkipped = 1 << 20
+	// suggestSkippedShare is the share of a directory's bytes that must be
+	// skipped content; directories of mostly selected files are kept
+	suggestSkippedShare = 0.9
+	// suggestLargeFile is the size from which a selected file is suggested
+	// on its own
+	suggestLargeFile = 512 << 10
+)
+
+// IgnoreSuggestion is a path worth excluding, with the bytes and files
+// it accounts for. Skipped is true when its content was left out anyway
+// (binary or oversized) and excluding it only saves the walk.
+type IgnoreSuggestion struct {
+	Pattern string
+	Size    int64
+	Files   int
+	Skipped bool
+}
+
+// SuggestIgnores derives exclusion patterns from a run's stats: the
+// topmost directories made almost entirely of skipped content, as dir/**,
+// and other files large enough to matter on their own, whether skipped or
+// crowding out the rest of the payload. The largest come first.
+func SuggestIgnores(result *CollectionResult) []IgnoreSuggestion {
+	type stats struct {
+		skipped, total int64
+		files          int
+	}
+	dirs := make(map[string]*stats)
+	add := func(relPath string, size int64, skipped bool) {
+		for dir := path.Dir(filepath.ToSlash(relPath)); dir != "."; dir = path.Dir(dir) {
+			s := dirs[dir]
+			if s == nil {
+				s = &stats{}
+				dirs[dir] = s
+			}
+			s.total += size
+			if skipped {
+				s.skipped += size
+				s.files++
+			}
+		}
+	}
+	for _, file := range result.SkippedContent {
+		add(file.RelPath, file.Size, true)
+	}
+	for _, file := range result.Files {
+		add(file.RelPath, file.Size, false)
+	}
+
+	candidate := func(dir string) bool {
+		s := dirs[dir]
+		return s.skipped >= suggestMinSkipped && float64(s.skipped) >= suggestSkippedShare*float64(s.total)
+	}
+	within := func(relPath string) bool {
+		for dir := path.Dir(relPath); dir != "."; dir = path.Dir(dir) {
+			if candidate(dir) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var suggestions []IgnoreSuggestion
+	for dir, s := range dirs {
+		if candidate(dir) && !within(dir) {
+			suggestions = append(suggestions, IgnoreSuggestion{Pattern: dir + "/**", Size: s.skipped, Files: s.files, Skipped: true})
+		}
+	}
+	for _, file := range result.SkippedContent {
+		relPath := filepath.ToSlash(file.RelPath)
+		if file.Size >= suggestLargeFile && !within(relPath) {
+			suggestions = append(suggestions, IgnoreSuggestion{Pattern: relPath, Size: file.Size, Files: 1, Skipped: true})
+		}
+	}
+	for _, file := range result.Files {
+		relPath := filepath.ToSlash(file.RelPath)
+		if file.Size >= suggestLargeFile && !within(relPath) {
+			suggestions = append(suggestions, IgnoreSuggestion{Pattern: relPath, Size: file.Size, Files: 1})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Size != suggestions[j].Size {
+			return suggestions[i].Size > suggestions[j].Size
+		}
+		return suggestions[i].Pattern < suggestions[j].Pattern
+	})
+	return suggestions
+}
@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"log/slog"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/language"
+)
+
+// DefaultSurveyTokens is the token budget of Options.Survey when none is set
+const DefaultSurveyTokens = 50000
+
+// manifestNames are build and dependency manifests, selected wherever they
+// are found because they describe the module around them
+var manifestNames = map[string]bool{
+	"go.mod": true, "go.work": true, "package.json": true, "tsconfig.json": true,
+	"Cargo.toml": true, "pyproject.toml": true, "setup.py": true, "setup.cfg": true,
+	"requirements.txt": true, "Pipfile": true, "Gemfile": true, "pom.xml": true,
+	"build.gradle": true, "build.gradle.kts": true, "settings.gradle": true,
+	"composer.json": true, "mix.exs": true, "CMakeLists.txt": true,
+	"Dockerfile": true, "docker-compose.yml": true, "docker-compose.yaml": true,
+}
+
+// configExts mark configuration files, selected at the top level only
+var configExts = map[string]bool{
+	".toml": true, ".yaml": true, ".yml": true, ".ini": true, ".cfg": true,
+}
+
+// proseExts are documentation rather than source; they never represent a
+// directory
+var proseExts = map[string]bool{
+	".md": true, ".markdown": true, ".rst": true, ".txt": true, ".adoc": true,
+}
+
+// entryNames are file stems that usually hold a program's entry point
+var entryNames = map[string]bool{
+	"main": true, "__main__": true, "index": true, "app": true, "server": true, "cli": true,
+}
+
+// Survey tiers, in the order the budget is spent on them
+const (
+	surveyManifest = iota
+	surveyEntry
+	surveyPackage
+)
+
+// selectSurvey keeps a representative sample of jobs for Options.Survey:
+// the top-level README, manifests, and config files first, then entry
+// points, then one source file per directory (the one named after the
+// directory, or else the largest). Within a tier shallower files come
+// first. Files are added while their estimated tokens fit the budget; one
+// that doesn't fit is skipped in favor of smaller ones after it.
+func selectSurvey(jobs []fileJob, opts Options) []fileJob {
+	budget := int64(opts.SurveyTokens)
+	if budget <= 0 {
+		budget = DefaultSurveyTokens
+	}
+
+	type candidate struct {
+		job  fileJob
+		tier int
+	}
+	var candidates []candidate
+	representative := make(map[string]int) // directory -> index in candidates
+	for _, job := range jobs {
+		if tier, ok := surveyTier(opts, job.relPath); ok {
+			candidates = append(candidates, candidate{job, tier})
+			continue
+		}
+
+		if proseExts[strings.ToLower(filepath.Ext(job.relPath))] {
+			continue
+		}
+		dir := filepath.Dir(job.relPath)
+		if i, seen := representative[dir]; !seen {
+			representative[dir] = len(candidates)
+			candidates = append(candidates, candidate{job, surveyPackage})
+		} else if betterRepresentative(job, candidates[i].job) {
+			candidates[i].job = job
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.tier != b.tier {
+			return a.tier < b.tier
+		}
+		da, db := strings.Count(filepath.ToSlash(a.job.relPath), "/"), strings.Count(filepath.ToSlash(b.job.relPath), "/")
+		if da != db {
+			return da < db
+		}
+		return a.job.relPath < b.job.relPath
+	})
+
+	var selected []fileJob
+	var tokens int64
+	for _, c := range candidates {
+		cost := EstimateTokens(c.job.size)
+		if tokens+cost > budget {
+			slog.Debug("file excluded: survey budget", "path", c.job.relPath, "tokens", cost)
+			continue
+		}
+		tokens += cost
+		selected = append(selected, c.job)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].relPath < selected[j].relPath
+	})
+	return selected
+}
+
+// surveyTier returns the tier of files a survey always considers:
+// manifests and config files, and entry points
+func surveyTier(opts Options, relPath string) (int, bool) {
+	if rank, doc := docRank(relPath); doc && rank == rankReadme {
+		return surveyManifest, true
+	}
+	if isManifest(relPath) {
+		return surveyManifest, true
+	}
+
+	base := filepath.Base(relPath)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	if isEntryPoint(opts, relPath) || entryNames[strings.ToLower(stem)] {
+		return surveyEntry, true
+	}
+	return 0, false
+}
+
+// isManifest reports whether relPath is a build manifest or a top-level
+// config file, which Options.Survey selects regardless of the allowed
+// extensions
+func isManifest(relPath string) bool {
+	slashPath := filepath.ToSlash(relPath)
+	base := path.Base(slashPath)
+	topLevel := !strings.Contains(slashPath, "/")
+	return manifestNames[base] || language.IsSpecialFile(base) || (topLevel && configExts[strings.ToLower(path.Ext(base))])
+}
+
+// betterRepresentative reports whether a describes its directory better
+// than b: source beats tests, a file named after the directory wins, then
+// the larger file
+func betterRepresentative(a, b fileJob) bool {
+	if testA, testB := analyzer.IsTestPath(a.relPath), analyzer.IsTestPath(b.relPath); testA != testB {
+		return testB
+	}
+
+	dir := filepath.Base(filepath.Dir(a.relPath))
+	stemA := strings.TrimSuffix(filepath.Base(a.relPath), filepath.Ext(a.relPath))
+	stemB := strings.TrimSuffix(filepath.Base(b.relPath), filepath.Ext(b.relPath))
+	if (stemA == dir) != (stemB == dir) {
+		return stemA == dir
+	}
+	return a.size > b.size
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Tree renders the selected files as an indented directory tree under a
+// line naming the root
+func Tree(rootName string, result *CollectionResult) string {
+	paths := make([]string, len(result.Files))
+	for i, file := range result.Files {
+		paths[i] = DisplayPath(filepath.ToSlash(file.RelPath))
+	}
+	sort.Strings(paths)
+
+	root := &treeNode{}
+	for _, p := range paths {
+		node := root
+		for _, part := range strings.Split(p, "/") {
+			child := node.children[part]
+			if child == nil {
+				if node.children == nil {
+					node.children = make(map[string]*treeNode)
+				}
+				child = &treeNode{}
+				node.children[part] = child
+				node.order = append(node.order, part)
+			}
+			node = child
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(rootName)
+	sb.WriteString("/\n")
+	root.write(&sb, "")
+	return sb.String()
+}
+
+type treeNode struct {
+	children map[string]*treeNode
+	order    []string
+}
+
+func (n *treeNode) write(sb *strings.Builder, indent string) {
+	// Directories first, like most file explorers
+	names := append([]string(nil), n.order...)
+	sort.SliceStable(names, func(i, j int) bool {
+		return n.children[names[i]].children != nil && n.children[names[j]].children == nil
+	})
+
+	for i, name := range names {
+		child := n.children[name]
+		branch, next := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, next = "└── ", "    "
+		}
+		sb.WriteString(indent + branch + name)
+		if child.children != nil {
+			sb.WriteString("/")
+		}
+		sb.WriteString("\n")
+		child.write(sb, indent+next)
+	}
+}
@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"golang.org/x/sync/errgroup"
+)
+
+// walkWorkers bounds how many directories are read concurrently
+const walkWorkers = 8
+
+type fileJob struct {
+	fullPath string
+	relPath  string
+	entry    os.DirEntry
+	size     int64 // on-disk size from the directory entry, for estimates
+	keep     int64 // bytes kept of a file cut to fit an extension limit; 0 keeps it whole
+}
+
+// walker enumerates a tree with a bounded pool of goroutines, one directory
+// per task. When the pool is saturated the current goroutine reads the
+// subdirectory itself, so recursion can never deadlock on the limit.
+type walker struct {
+	rootPath string
+	// maxDepth is the deepest file depth to reach (0 = unlimited), so a
+	// directory is entered only while its files are no deeper than that
+	maxDepth int
+
+	// oneFileSystem stops the walk at directories on another device
+	oneFileSystem bool
+	device        uint64
+	checkDevice   bool
+
+	// includeDir decides whether to descend into a directory
+	includeDir func(relPath string) bool
+	// includeFile decides whether a file becomes a job
+	includeFile func(path, relPath string, d os.DirEntry) bool
+
+	mu          sync.Mutex
+	jobs        []fileJob
+	denied      []string        // directories that could not be read due to permissions
+	failed      []ReadError     // directories that could not be read for other reasons
+	crossed     []string        // mount points skipped by oneFileSystem
+	visitedDirs map[string]bool // Track visited directories to avoid symlink loops
+}
+
+// walk returns the selected files sorted by relative path, independent of
+// the order in which directories happened to be read
+func (w *walker) walk(ctx context.Context) ([]fileJob, error) {
+	w.visitedDirs = make(map[string]bool)
+	// A link back to the root itself is a loop too
+	if realRoot, err := filepath.EvalSymlinks(w.rootPath); err == nil {
+		w.visitedDirs[realRoot] = true
+	}
+	if w.oneFileSystem {
+		if info, err := os.Stat(w.rootPath); err == nil {
+			w.device, w.checkDevice = analyzer.DeviceID(info)
+		}
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(walkWorkers)
+	eg.Go(func() error {
+		return w.readDir(egCtx, eg, w.rootPath, ".", 0)
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(w.jobs, func(i, j int) bool {
+		return w.jobs[i].relPath < w.jobs[j].relPath
+	})
+	return w.jobs, nil
+}
+
+func (w *walker) readDir(ctx context.Context, eg *errgroup.Group, dirPath, relDir string, depth int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(longPath(dirPath))
+	if err != nil {
+		slog.Debug("directory unreadable", "path", relDir, "error", err)
+		w.mu.Lock()
+		if errors.Is(err, fs.ErrPermission) {
+			w.denied = append(w.denied, relDir)
+		} else {
+			w.failed = append(w.failed, ReadError{RelPath: relDir, Err: err})
+		}
+		w.mu.Unlock()
+		return nil
+	}
+
+	var jobs []fileJob
+	for _, d := range entries {
+		path := filepath.Join(dirPath, d.Name())
+		relPath := filepath.Join(relDir, d.Name())
+
+		// Handle symlinks to avoid infinite loops
+		if d.Type()&os.ModeSymlink != 0 && !w.markSymlink(path) {
+			continue
+		}
+
+		if d.IsDir() {
+			if w.maxDepth > 0 && depth+1 >= w.maxDepth {
+				slog.Debug("directory skipped: max depth", "path", relPath, "max_depth", w.maxDepth)
+				continue
+			}
+			if !w.includeDir(relPath) {
+				slog.Debug("directory excluded by filter", "path", relPath)
+				continue
+			}
+			if w.crossesDevice(d) {
+				slog.Debug("directory skipped: other filesystem", "path", relPath)
+				w.mu.Lock()
+				w.crossed = append(w.crossed, relPath)
+				w.mu.Unlock()
+				continue
+			}
+
+			subDepth := depth + 1
+			task := func() error {
+				return w.readDir(ctx, eg, path, relPath, subDepth)
+			}
+			if !eg.TryGo(task) {
+				if err := task(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if w.includeFile(path, relPath, d) {
+			job := fileJob{fullPath: path, relPath: relPath, entry: d}
+			if info, err := d.Info(); err == nil {
+				job.size = info.Size()
+			}
+			jobs = append(jobs, job)
+		}
+	}
+
+	if len(jobs) > 0 {
+		w.mu.Lock()
+		w.jobs = append(w.jobs, jobs...)
+		w.mu.Unlock()
+	}
+	return nil
+}
+
+// crossesDevice reports whether the directory entry d is a mount point of
+// another filesystem and oneFileSystem forbids entering it
+func (w *walker) crossesDevice(d os.DirEntry) bool {
+	if !w.checkDevice {
+		return false
+	}
+	info, err := d.Info()
+	if err != nil {
+		return false
+	}
+	dev, ok := analyzer.DeviceID(info)
+	return ok && dev != w.device
+}
+
+// markSymlink records the target of a symlink and reports whether the walk
+// should continue with it (false for broken links and already-seen targets)
+func (w *walker) markSymlink(path string) bool {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false // Skip broken symlinks
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Check if we've already visited this real path
+	if w.visitedDirs[realPath] {
+		return false
+	}
+
+	// Mark as visited if it's a directory
+	info, err := os.Stat(realPath)
+	if err == nil && info.IsDir() {
+		w.visitedDirs[realPath] = true
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+// mount mounts source on target, skipping the test unless running as root
+// somewhere mounts are permitted, and unmounts it when the test ends
+func mount(t *testing.T, source, target, fstype string, flags uintptr) {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		t.Skip("mounting needs root")
+	}
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mount(source, target, fstype, flags, ""); err != nil {
+		t.Skipf("can't mount here: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := syscall.Unmount(target, syscall.MNT_DETACH); err != nil {
+			t.Errorf("unmounting %s: %v", target, err)
+		}
+	})
+}
+
+func TestCollectBindMountedRoot(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	writeTree(t, source, map[string]string{
+		"main.go":     "package main\n",
+		"pkg/util.go": "package pkg\n",
+	})
+	bind := filepath.Join(dir, "bind")
+	mount(t, source, bind, "", syscall.MS_BIND)
+
+	// Unlike a symlink, a bind mount can't be resolved to its source, so
+	// it is walked as the root it appears to be
+	want := []string{"main.go", "pkg/util.go"}
+	for _, oneFileSystem := range []bool{false, true} {
+		if got := collectPaths(t, bind, Options{OneFileSystem: oneFileSystem}); !slices.Equal(got, want) {
+			t.Errorf("OneFileSystem %v: collected %v, want %v", oneFileSystem, got, want)
+		}
+	}
+}
+
+func TestCollectMountInsideTree(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"main.go": "package main\n"})
+	share := filepath.Join(root, "share")
+	mount(t, "tmpfs", share, "tmpfs", 0)
+	writeTree(t, share, map[string]string{"remote.go": "package share\n"})
+
+	if got, want := collectPaths(t, root, Options{}), []string{"main.go", "share/remote.go"}; !slices.Equal(got, want) {
+		t.Errorf("collected %v, want %v", got, want)
+	}
+	if got, want := collectPaths(t, root, Options{OneFileSystem: true}), []string{"main.go"}; !slices.Equal(got, want) {
+		t.Errorf("OneFileSystem: collected %v, want %v", got, want)
+	}
+}
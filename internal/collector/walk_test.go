@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+)
+
+// symlink creates a link at name pointing to target, skipping the test
+// where the platform or user can't create links
+func symlink(t *testing.T, target, name string) {
+	t.Helper()
+	if err := os.Symlink(target, name); err != nil {
+		t.Skipf("can't create symlinks: %v", err)
+	}
+}
+
+// collectPaths collects root and returns the slash-separated relative
+// paths, failing the test if the walk takes implausibly long
+func collectPaths(t *testing.T, root string, opts Options) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	opts.MaxFileSizeMB = 1
+	result, err := Collect(ctx, root, newTestFilter(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+
+	paths := make([]string, len(result.Files))
+	for i, file := range result.Files {
+		paths[i] = filepath.ToSlash(file.RelPath)
+	}
+	return paths
+}
+
+func TestCollectSymlinkedRoot(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	files := map[string]string{
+		"main.go":        "package main\n",
+		"pkg/util.go":    "package pkg\n",
+		"pkg/deep/x.go":  "package deep\n",
+		"node_modules/a": "ignored\n",
+	}
+	writeTree(t, source, files)
+	link := filepath.Join(dir, "link")
+	symlink(t, source, link)
+	// A chain of links, the last one relative
+	chain := filepath.Join(dir, "chain")
+	symlink(t, "link", chain)
+
+	want := []string{"main.go", "pkg/deep/x.go", "pkg/util.go"}
+	for _, root := range []string{source, link, chain} {
+		resolved, err := analyzer.ResolveRoot(root)
+		if err != nil {
+			t.Fatalf("ResolveRoot(%s): %v", root, err)
+		}
+		if resolvedSource, _ := filepath.EvalSymlinks(source); resolved != resolvedSource {
+			t.Errorf("ResolveRoot(%s) = %s, want %s", root, resolved, resolvedSource)
+		}
+		if got := collectPaths(t, resolved, Options{}); !slices.Equal(got, want) {
+			t.Errorf("collecting %s resolved to %s: %v, want %v", root, resolved, got, want)
+		}
+	}
+
+	if _, err := analyzer.ResolveRoot(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ResolveRoot of a missing path succeeded")
+	}
+}
+
+func TestCollectSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":     "package main\n",
+		"a/b/util.go": "package b\n",
+	})
+	// Links back to the root, to an ancestor, to themselves, and to each
+	// other must neither hang the walk nor duplicate files
+	symlink(t, root, filepath.Join(root, "a", "root"))
+	symlink(t, "..", filepath.Join(root, "a", "b", "up"))
+	symlink(t, "self", filepath.Join(root, "self"))
+	symlink(t, "pong", filepath.Join(root, "ping"))
+	symlink(t, "ping", filepath.Join(root, "pong"))
+
+	want := []string{"a/b/util.go", "main.go"}
+	if got := collectPaths(t, root, Options{}); !slices.Equal(got, want) {
+		t.Errorf("collected %v, want %v", got, want)
+	}
+
+	// The same holds when the walk starts at a link to the root
+	link := filepath.Join(t.TempDir(), "link")
+	symlink(t, root, link)
+	if got := collectPaths(t, link, Options{}); !slices.Equal(got, want) {
+		t.Errorf("collected %v through a linked root, want %v", got, want)
+	}
+}
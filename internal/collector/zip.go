@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// reproducibleTime is the modification time of zip entries written with
+// FormatOptions.Reproducible, the earliest time the zip format can store
+var reproducibleTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// zipManifestNames are where WriteZip puts the manifest, in order of
+// preference: the archive root, unless a collected file is already there
+var zipManifestNames = []string{"MANIFEST.md", ".bcopy/MANIFEST.md"}
+
+// WriteZip writes every collected file into a zip archive at its relative
+// path, plus a MANIFEST.md listing the archive contents. The manifest moves
+// to .bcopy/MANIFEST.md when the collection has its own MANIFEST.md.
+func WriteZip(result *CollectionResult, w io.Writer, opts FormatOptions) error {
+	name, err := manifestName(result)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	modified := time.Now()
+	if opts.Reproducible {
+		modified = reproducibleTime
+	}
+
+	manifest, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(manifest, formatManifest(result)); err != nil {
+		return err
+	}
+
+	for _, file := range result.Files {
+		entry, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     filepath.ToSlash(file.RelPath),
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return err
+		}
+		content, err := result.ReadContent(file)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// manifestName returns the first of zipManifestNames no collected file uses
+func manifestName(result *CollectionResult) (string, error) {
+	taken := make(map[string]bool, len(result.Files))
+	for _, file := range result.Files {
+		taken[filepath.ToSlash(file.RelPath)] = true
+	}
+	for _, name := range zipManifestNames {
+		if !taken[name] {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("collected files occupy every manifest path (%s)", strings.Join(zipManifestNames, ", "))
+}
+
+func formatManifest(result *CollectionResult) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Manifest\n\n")
+	sb.WriteString(fmt.Sprintf("%d files, %s\n\n", result.FileCount, FormatSize(result.TotalSize)))
+	sb.WriteString("| File | Language | Size |\n")
+	sb.WriteString("|------|----------|------|\n")
+	for _, file := range result.Files {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", MarkdownPath(filepath.ToSlash(file.RelPath)), file.Language, FormatSize(file.Size)))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// zipEntries writes result as a zip archive and returns each entry's
+// content by name
+func zipEntries(t *testing.T, result *CollectionResult) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteZip(result, &buf, FormatOptions{Reproducible: true}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]string)
+	for _, f := range zr.File {
+		if _, dup := entries[f.Name]; dup {
+			t.Errorf("duplicate zip entry %s", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name] = string(data)
+	}
+	return entries
+}
+
+func TestWriteZipManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		manifest string
+	}{
+		{"root", map[string]string{"main.go": "package main\n"}, "MANIFEST.md"},
+		{"collected MANIFEST.md", map[string]string{"main.go": "package main\n", "MANIFEST.md": "# Ours\n"}, ".bcopy/MANIFEST.md"},
+		{"nested MANIFEST.md", map[string]string{"docs/MANIFEST.md": "# Docs\n"}, "MANIFEST.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := zipEntries(t, resultOf(tt.files))
+			if !strings.HasPrefix(entries[tt.manifest], "# Manifest\n") {
+				t.Errorf("no generated manifest at %s; entries %v", tt.manifest, entries)
+			}
+			for path, content := range tt.files {
+				if entries[path] != content {
+					t.Errorf("%s = %q, want the collected %q", path, entries[path], content)
+				}
+			}
+		})
+	}
+
+	taken := resultOf(map[string]string{"MANIFEST.md": "a\n", ".bcopy/MANIFEST.md": "b\n"})
+	if err := WriteZip(taken, io.Discard, FormatOptions{}); err == nil {
+		t.Error("WriteZip succeeded with every manifest path taken")
+	}
+}
@@ -0,0 +1,90 @@
+// Package credentials stores API tokens for services bcopy talks to in the
+// OS keychain, so they never have to be written to a config file.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService namespaces bcopy's entries in the OS keychain
+const keyringService = "bcopy"
+
+// Service is an external service bcopy can hold a token for
+type Service struct {
+	Name        string
+	Description string
+}
+
+// Services lists the services tokens can be stored for
+var Services = []Service{
+	{Name: "github", Description: "GitHub token for gist uploads"},
+	{Name: "openai", Description: "OpenAI API key for LLM submission"},
+	{Name: "anthropic", Description: "Anthropic API key for LLM submission"},
+}
+
+// ErrNotFound is returned when no token is stored for a service
+var ErrNotFound = errors.New("no token stored")
+
+// Source describes where a token was found
+type Source string
+
+const (
+	SourceEnv     Source = "environment"
+	SourceKeyring Source = "keyring"
+)
+
+// Lookup finds a service by name
+func Lookup(name string) (Service, error) {
+	for _, s := range Services {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	names := make([]string, len(Services))
+	for i, s := range Services {
+		names[i] = s.Name
+	}
+	return Service{}, fmt.Errorf("unknown service %q (use %s)", name, strings.Join(names, ", "))
+}
+
+// EnvVar returns the environment variable that overrides the stored token,
+// e.g. BCOPY_GITHUB_TOKEN
+func (s Service) EnvVar() string {
+	return "BCOPY_" + strings.ToUpper(s.Name) + "_TOKEN"
+}
+
+// Get returns the token for s. The environment variable takes precedence
+// over the keychain so CI can inject tokens without a keychain.
+func Get(s Service) (string, Source, error) {
+	if token := os.Getenv(s.EnvVar()); token != "" {
+		return token, SourceEnv, nil
+	}
+
+	token, err := keyring.Get(keyringService, s.Name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return token, SourceKeyring, nil
+}
+
+// Set stores token for s in the keychain
+func Set(s Service, token string) error {
+	return keyring.Set(keyringService, s.Name, token)
+}
+
+// Delete removes the token for s from the keychain
+func Delete(s Service) error {
+	err := keyring.Delete(keyringService, s.Name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,285 @@
+// Package detect tells text files from binary ones by their first bytes,
+// recognizing UTF-16 text that a null-byte check would reject and binaries
+// that happen to have no null bytes early on.
+package detect
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nodelike/bcopy/internal/language"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// SniffSize is how much of a file Sniff needs to see
+const SniffSize = 8192
+
+// Kind is the encoding of a file's content, or Binary
+type Kind int
+
+const (
+	Binary Kind = iota
+	UTF8
+	UTF16LE
+	UTF16BE
+	// Latin1 is ISO-8859-1, which sniffing can't tell from UTF-8; it is
+	// only ever declared (see SniffCharset)
+	Latin1
+)
+
+func (k Kind) String() string {
+	switch k {
+	case UTF8:
+		return "utf-8"
+	case UTF16LE:
+		return "utf-16le"
+	case UTF16BE:
+		return "utf-16be"
+	case Latin1:
+		return "latin1"
+	default:
+		return "binary"
+	}
+}
+
+const (
+	// maxSuspicious is the share of invalid UTF-8 and control bytes above
+	// which content is binary. Legacy 8-bit text stays well below it.
+	maxSuspicious = 0.1
+	// maxEntropy is the bits per byte above which content without a
+	// known text extension is taken to be compressed or encrypted. Source
+	// code sits around 4.5-5.5 and base64 at 6.
+	maxEntropy = 7.2
+	// minEntropySample is the smallest head the entropy check applies to;
+	// short samples can't reach a meaningful entropy
+	minEntropySample = 512
+	// minUTF16Zeros is the share of zero bytes in one byte lane that marks
+	// BOM-less UTF-16 holding mostly ASCII
+	minUTF16Zeros = 0.7
+)
+
+// binaryExtensions are formats that are never worth reading as text
+var binaryExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".ico": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".zst": true,
+	".7z": true, ".rar": true, ".tar": true, ".jar": true, ".war": true, ".class": true,
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".o": true, ".a": true, ".wasm": true,
+	".pyc": true, ".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp3": true, ".mp4": true, ".mov": true, ".avi": true, ".wav": true, ".flac": true, ".ogg": true,
+	".sqlite": true, ".db": true, ".bin": true,
+}
+
+// Sniff classifies head, the first SniffSize bytes (or fewer) of the file
+// at path. The extension of path is a hint: known binary formats are
+// Binary outright, and known source files are exempt from the entropy
+// check. An empty head is UTF8.
+func Sniff(path string, head []byte) Kind {
+	if binaryExtensions[strings.ToLower(filepath.Ext(path))] {
+		return Binary
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE, 0, 0}), bytes.HasPrefix(head, []byte{0, 0, 0xFE, 0xFF}):
+		return Binary // UTF-32 is not worth supporting
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return UTF8
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return UTF16LE
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return UTF16BE
+	}
+	if kind, ok := sniffUTF16(head); ok {
+		return kind
+	}
+
+	if contentType := http.DetectContentType(head); !isTextType(contentType) {
+		return Binary
+	}
+	if bytes.IndexByte(head, 0) != -1 {
+		return Binary
+	}
+	if suspiciousRatio(head) > maxSuspicious {
+		return Binary
+	}
+	if language.Detect(path) == "" && len(head) >= minEntropySample && entropy(head) > maxEntropy {
+		return Binary
+	}
+	return UTF8
+}
+
+// SniffCharset is Sniff for a file whose charset is declared, as by the
+// charset property of .editorconfig: latin1, utf-8, utf-8-bom, utf-16le,
+// or utf-16be. A byte order mark still wins, but declared UTF-16 is taken
+// at its word where its zero bytes would make it look binary, and declared
+// Latin-1 where its high bytes would look binary or pass for UTF-8. Other
+// charsets, including "", leave the decision to Sniff.
+func SniffCharset(path string, head []byte, charset string) Kind {
+	if binaryExtensions[strings.ToLower(filepath.Ext(path))] {
+		return Binary
+	}
+	hasBOM := bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}) ||
+		bytes.HasPrefix(head, []byte{0xFF, 0xFE}) || bytes.HasPrefix(head, []byte{0xFE, 0xFF})
+
+	switch charset {
+	case "utf-16le":
+		if !hasBOM {
+			return UTF16LE
+		}
+	case "utf-16be":
+		if !hasBOM {
+			return UTF16BE
+		}
+	case "latin1":
+		// Its high bytes are invalid UTF-8, so only the signatures and
+		// null bytes of binaries count against it
+		if !hasBOM && isTextType(http.DetectContentType(head)) && bytes.IndexByte(head, 0) == -1 {
+			return Latin1
+		}
+	}
+	return Sniff(path, head)
+}
+
+// LineEnding names the line endings of content: "lf", "crlf", or "cr", or
+// "mixed" when it has more than one kind. Content without a line break has
+// none, and "" is returned.
+func LineEnding(content string) string {
+	var lf, crlf, cr bool
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\n':
+			lf = true
+		case '\r':
+			if i+1 < len(content) && content[i+1] == '\n' {
+				crlf = true
+				i++
+			} else {
+				cr = true
+			}
+		}
+	}
+	switch {
+	case lf && !crlf && !cr:
+		return "lf"
+	case crlf && !lf && !cr:
+		return "crlf"
+	case cr && !lf && !crlf:
+		return "cr"
+	case lf || crlf || cr:
+		return "mixed"
+	default:
+		return ""
+	}
+}
+
+// sniffUTF16 recognizes BOM-less UTF-16 by one byte lane being mostly zero
+// and the other not, as in ASCII text stored as UTF-16, and by decoding to
+// text. Tables of small 16-bit numbers in binaries fail the second test.
+func sniffUTF16(head []byte) (Kind, bool) {
+	if len(head) < 4 {
+		return Binary, false
+	}
+	var zeros [2]int
+	pairs := len(head) / 2
+	for i := 0; i < pairs*2; i++ {
+		if head[i] == 0 {
+			zeros[i%2]++
+		}
+	}
+	even, odd := float64(zeros[0])/float64(pairs), float64(zeros[1])/float64(pairs)
+	kind := Binary
+	switch {
+	case odd >= minUTF16Zeros && even < 0.1:
+		kind = UTF16LE
+	case even >= minUTF16Zeros && odd < 0.1:
+		kind = UTF16BE
+	default:
+		return Binary, false
+	}
+	text, err := Decode(kind, head[:pairs*2])
+	if err != nil || suspiciousRatio([]byte(text)) > maxSuspicious {
+		return Binary, false
+	}
+	return kind, true
+}
+
+// isTextType reports whether a sniffed MIME type may be text. Anything
+// DetectContentType can't place is application/octet-stream, which says
+// nothing, so only recognized binary signatures count against a file.
+func isTextType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/octet-stream" ||
+		contentType == "application/postscript"
+}
+
+// suspiciousRatio is the share of bytes in head that are invalid UTF-8 or
+// control characters other than whitespace and escape. A rune cut off at
+// the end of head is not counted.
+func suspiciousRatio(head []byte) float64 {
+	if len(head) == 0 {
+		return 0
+	}
+	suspicious := 0
+	for i := 0; i < len(head); {
+		r, size := utf8.DecodeRune(head[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			if len(head)-i < utf8.UTFMax && !utf8.FullRune(head[i:]) {
+				i = len(head)
+				continue
+			}
+			suspicious++
+		case r < 0x20 && !strings.ContainsRune("\t\n\r\f\v\x1b", r):
+			suspicious++
+		}
+		i += size
+	}
+	return float64(suspicious) / float64(len(head))
+}
+
+// entropy is the Shannon entropy of data in bits per byte
+func entropy(data []byte) float64 {
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	bits := 0.0
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(len(data))
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// Decode returns content of the given kind as UTF-8 text. UTF-16 and
+// Latin-1 are converted, dropping a UTF-16 byte order mark; UTF-8 is
+// returned as is.
+func Decode(kind Kind, content []byte) (string, error) {
+	var endianness unicode.Endianness
+	switch kind {
+	case Latin1:
+		decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(content)
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	case UTF16LE:
+		endianness = unicode.LittleEndian
+	case UTF16BE:
+		endianness = unicode.BigEndian
+	default:
+		return string(content), nil
+	}
+	decoded, err := unicode.UTF16(endianness, unicode.UseBOM).NewDecoder().Bytes(content)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
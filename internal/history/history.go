@@ -0,0 +1,217 @@
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Run describes one bcopy invocation
+type Run struct {
+	Time      time.Time `json:"time"`
+	Root      string    `json:"root"`
+	FileCount int       `json:"file_count"`
+	TotalSize int64     `json:"total_size"`
+
+	// Files maps each relative path to the SHA-256 of its emitted content.
+	// It is kept only for the last run of each root, not in the log.
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// Dir returns the directory holding bcopy's history
+func Dir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "bcopy"), nil
+}
+
+// Hash returns the content hash stored in Run.Files
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// rootDir returns the directory holding the last run of root
+func rootDir(root string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, "runs", hex.EncodeToString(sum[:8])), nil
+}
+
+// Record appends run to the history log and stores it, with the payload
+// written by writePayload, as the last run of run.Root
+func Record(run Run, writePayload func(io.Writer) error) error {
+	dir, err := rootDir(run.Root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "last.md"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = writePayload(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "last.json"), data, 0600); err != nil {
+		return err
+	}
+
+	entry := run
+	entry.Files = nil
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	logDir := filepath.Dir(filepath.Dir(dir))
+	log, err := os.OpenFile(filepath.Join(logDir, "history.jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := log.Write(append(line, '\n')); err != nil {
+		log.Close()
+		return err
+	}
+	return log.Close()
+}
+
+// Last returns the last recorded run of root and the path of its payload.
+// It returns nil if root has no history.
+func Last(root string) (*Run, string, error) {
+	dir, err := rootDir(root)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "last.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, "", err
+	}
+	return &run, filepath.Join(dir, "last.md"), nil
+}
+
+// Log returns every run in the history log, oldest first. Lines that can't
+// be parsed, such as one cut short by a crash, are skipped.
+func Log() ([]Run, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, "history.jsonl"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, scanner.Err()
+}
+
+// maxOutputs caps the output paths kept by RecordOutputs
+const maxOutputs = 200
+
+// RecordOutputs adds the absolute paths of files and directories a run
+// wrote to the list returned by Outputs. Paths that no longer exist are
+// dropped from the list, and only the latest maxOutputs are kept.
+func RecordOutputs(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	existing, err := Outputs()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var kept []string
+	for _, p := range append(existing, paths...) {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := os.Stat(p); err == nil {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) > maxOutputs {
+		kept = kept[len(kept)-maxOutputs:]
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data := strings.Join(kept, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	return os.WriteFile(filepath.Join(dir, "outputs"), []byte(data), 0600)
+}
+
+// Outputs returns the paths recorded by RecordOutputs, oldest first
+func Outputs() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "outputs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
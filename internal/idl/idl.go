@@ -0,0 +1,123 @@
+// Package idl recognizes API contract files (Protobuf, GraphQL, Thrift,
+// Avro, OpenAPI) and the files they import
+package idl
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// extensions are the IDL file types, by extension
+var extensions = map[string]bool{
+	".proto":    true,
+	".graphql":  true,
+	".graphqls": true,
+	".gql":      true,
+	".thrift":   true,
+	".avsc":     true,
+	".avdl":     true,
+	".avpr":     true,
+}
+
+// openAPINames are the base names, without extension, of OpenAPI documents
+var openAPINames = map[string]bool{
+	"openapi": true,
+	"swagger": true,
+}
+
+var documentExts = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+var (
+	protoImport   = regexp.MustCompile(`(?m)^\s*import\s+(?:public\s+|weak\s+)?"([^"]+)"\s*;`)
+	graphqlImport = regexp.MustCompile(`(?m)^\s*#\s*import\s+(?:.*\s+from\s+)?["']([^"']+)["']`)
+	thriftInclude = regexp.MustCompile(`(?m)^\s*(?:include|cpp_include)\s+"([^"]+)"`)
+	avdlImport    = regexp.MustCompile(`(?m)^\s*import\s+(?:idl|protocol|schema)\s+"([^"]+)"\s*;`)
+	// refTarget matches the file part of an OpenAPI/JSON Schema $ref; local
+	// references ("#/components/...") have none and don't match
+	refTarget = regexp.MustCompile(`\$ref["']?\s*:\s*["']?([^"'#\s,}]+)`)
+)
+
+// IsIDL reports whether path is an API contract file: .proto, .graphql,
+// .thrift, Avro (.avsc, .avdl, .avpr), or an OpenAPI document
+// (openapi.yaml, swagger.json, *.openapi.yaml, ...)
+func IsIDL(p string) bool {
+	base := strings.ToLower(filepath.Base(p))
+	ext := filepath.Ext(base)
+	if extensions[ext] {
+		return true
+	}
+	if !documentExts[ext] {
+		return false
+	}
+	stem := strings.TrimSuffix(base, ext)
+	return openAPINames[stem] || openAPINames[strings.TrimPrefix(filepath.Ext(stem), ".")]
+}
+
+// IsImportable reports whether path can be pulled in by an IDL file: any IDL
+// file, or a YAML/JSON document referenced by an OpenAPI $ref
+func IsImportable(p string) bool {
+	return IsIDL(p) || documentExts[strings.ToLower(filepath.Ext(p))]
+}
+
+// Imports returns the import targets named in content, as written
+func Imports(p, content string) []string {
+	var re *regexp.Regexp
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".proto":
+		re = protoImport
+	case ".graphql", ".graphqls", ".gql":
+		re = graphqlImport
+	case ".thrift":
+		re = thriftInclude
+	case ".avdl":
+		re = avdlImport
+	case ".yaml", ".yml", ".json":
+		re = refTarget
+	default:
+		return nil
+	}
+
+	var imports []string
+	for _, match := range re.FindAllStringSubmatch(content, -1) {
+		target := match[1]
+		if strings.Contains(target, "://") {
+			continue
+		}
+		imports = append(imports, target)
+	}
+	return imports
+}
+
+// Resolve finds the file imported as target by the file at from, among
+// files (slash-separated paths relative to the project root). The target is
+// tried relative to the importing file, then to the root, then as a suffix
+// of any known path, which covers Protobuf include roots such as proto/.
+func Resolve(from, target string, files map[string]bool) (string, bool) {
+	target = path.Clean(filepath.ToSlash(target))
+	candidates := []string{
+		path.Join(path.Dir(filepath.ToSlash(from)), target),
+		strings.TrimPrefix(target, "/"),
+	}
+	for _, candidate := range candidates {
+		if files[candidate] {
+			return candidate, true
+		}
+	}
+
+	if strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	var found string
+	for file := range files {
+		if strings.HasSuffix(file, "/"+target) && (found == "" || len(file) < len(found) || len(file) == len(found) && file < found) {
+			found = file
+		}
+	}
+	return found, found != ""
+}
@@ -0,0 +1,173 @@
+// Package language maps file names to markdown fence languages and holds
+// the set of files bcopy selects by default. The collector and the filter
+// both use it so detection and selection stay in step.
+package language
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// filenames maps special file names to their language. They are matched
+// before extensions, so CMakeLists.txt is cmake rather than text.
+var filenames = map[string]string{
+	"Makefile":        "makefile",
+	"GNUmakefile":     "makefile",
+	"Dockerfile":      "dockerfile",
+	"Containerfile":   "dockerfile",
+	"Rakefile":        "ruby",
+	"Gemfile":         "ruby",
+	"Podfile":         "ruby",
+	"Brewfile":        "ruby",
+	"Procfile":        "yaml",
+	"Vagrantfile":     "ruby",
+	"Cargo":           "toml",
+	"Justfile":        "just",
+	"justfile":        "just",
+	"Jenkinsfile":     "groovy",
+	"Tiltfile":        "python",
+	"Earthfile":       "dockerfile",
+	"CMakeLists.txt":  "cmake",
+	"BUILD":           "python",
+	"WORKSPACE":       "python",
+	"BUILD.bazel":     "python",
+	"WORKSPACE.bazel": "python",
+	"MODULE.bazel":    "python",
+}
+
+// extensions maps file extensions to their language
+var extensions = map[string]string{
+	".go":         "go",
+	".py":         "python",
+	".pyi":        "python",
+	".js":         "javascript",
+	".jsx":        "jsx",
+	".ts":         "typescript",
+	".tsx":        "tsx",
+	".vue":        "vue",
+	".svelte":     "svelte",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".rs":         "rust",
+	".java":       "java",
+	".c":          "c",
+	".cpp":        "cpp",
+	".cc":         "cpp",
+	".cxx":        "cpp",
+	".h":          "c",
+	".hpp":        "cpp",
+	".hh":         "cpp",
+	".cs":         "csharp",
+	".fs":         "fsharp",
+	".rb":         "ruby",
+	".php":        "php",
+	".swift":      "swift",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".scala":      "scala",
+	".sh":         "bash",
+	".bash":       "bash",
+	".zsh":        "zsh",
+	".fish":       "fish",
+	".ps1":        "powershell",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".json":       "json",
+	".jsonc":      "jsonc",
+	".xml":        "xml",
+	".html":       "html",
+	".htm":        "html",
+	".css":        "css",
+	".scss":       "scss",
+	".sass":       "sass",
+	".less":       "less",
+	".md":         "markdown",
+	".markdown":   "markdown",
+	".mdx":        "mdx",
+	".rst":        "rst",
+	".sql":        "sql",
+	".toml":       "toml",
+	".ini":        "ini",
+	".conf":       "conf",
+	".env":        "bash",
+	".txt":        "text",
+	".dockerfile": "dockerfile",
+	".pl":         "perl",
+	".pm":         "perl",
+	".lua":        "lua",
+	".vim":        "vim",
+	".ex":         "elixir",
+	".exs":        "elixir",
+	".erl":        "erlang",
+	".hrl":        "erlang",
+	".clj":        "clojure",
+	".cljs":       "clojure",
+	".dart":       "dart",
+	".r":          "r",
+	".R":          "r",
+	".m":          "objective-c",
+	".mm":         "objective-c",
+	".groovy":     "groovy",
+	".gradle":     "gradle",
+	".tf":         "terraform",
+	".tfvars":     "terraform",
+	".hcl":        "hcl",
+	".proto":      "protobuf",
+	".graphql":    "graphql",
+	".gql":        "graphql",
+	".graphqls":   "graphql",
+	".thrift":     "thrift",
+	".avsc":       "json",
+	".avpr":       "json",
+	".avdl":       "avdl",
+	".prisma":     "prisma",
+	".cmake":      "cmake",
+	".zig":        "zig",
+	".nim":        "nim",
+	".hs":         "haskell",
+	".lhs":        "haskell",
+	".ml":         "ocaml",
+	".mli":        "ocaml",
+	".elm":        "elm",
+	".jl":         "julia",
+	".sol":        "solidity",
+	".bazel":      "python",
+	".bzl":        "python",
+	".just":       "just",
+	".nix":        "nix",
+	".templ":      "templ",
+}
+
+// defaultExtensions are the extensions selected when --ext is not given
+var defaultExtensions = []string{
+	".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".vue", ".svelte", ".mjs", ".cjs",
+	".yaml", ".yml", ".json", ".toml", ".md", ".txt", ".sh", ".bash",
+	".c", ".cpp", ".h", ".hpp", ".rs", ".java", ".rb", ".php", ".swift", ".kt",
+	".tf", ".tfvars", ".hcl",
+	".proto", ".graphql", ".gql", ".prisma", ".cmake", ".zig", ".nim", ".hs", ".ml",
+	".bazel", ".bzl",
+}
+
+// Detect returns the fence language for path, or "" if it is unknown
+func Detect(path string) string {
+	base := filepath.Base(path)
+	if lang, ok := filenames[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, ".env.") {
+		return "bash"
+	}
+	return extensions[filepath.Ext(path)]
+}
+
+// DefaultExtensions returns the extensions selected by default
+func DefaultExtensions() []string {
+	return append([]string(nil), defaultExtensions...)
+}
+
+// IsSpecialFile reports whether name is a well-known file selected by
+// default regardless of its extension, such as Makefile or Justfile
+func IsSpecialFile(name string) bool {
+	_, ok := filenames[filepath.Base(name)]
+	return ok
+}
@@ -0,0 +1,115 @@
+// Package license identifies the licenses that govern source files, from
+// SPDX tags and license notices in the files themselves and from the
+// license files of the directories holding them.
+package license
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headerLines is how far into a file license tags and notices are looked for
+const headerLines = 40
+
+// spdxPattern matches an SPDX-License-Identifier tag and captures the
+// license expression
+var spdxPattern = regexp.MustCompile(`SPDX-License-Identifier:[ \t]*([A-Za-z0-9.+\-() ]+)`)
+
+// signature identifies a license by phrases of its text or notice. All
+// phrases must appear, compared case-insensitively with runs of whitespace
+// collapsed. More specific signatures come first.
+type signature struct {
+	id      string
+	phrases []string
+}
+
+var signatures = []signature{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"LGPL-2.0", []string{"gnu library general public license"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "2.0"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"BUSL-1.1", []string{"business source license"}},
+	{"SSPL-1.0", []string{"server side public license"}},
+	{"MIT", []string{"licensed under the mit license"}},
+	{"MIT", []string{"permission is hereby granted, free of charge", "the above copyright notice and this permission notice shall be included"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name of"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// Identify returns the SPDX identifier of the license whose text or notice
+// appears in text, or "" if none is recognized
+func Identify(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for _, sig := range signatures {
+		matched := true
+		for _, phrase := range sig.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return sig.id
+		}
+	}
+	return ""
+}
+
+// FromHeader returns the license a source file declares in its first
+// lines, as an SPDX tag or a license notice, or "" if it declares none
+func FromHeader(content string) string {
+	lines := strings.SplitN(content, "\n", headerLines+1)
+	if len(lines) > headerLines {
+		lines = lines[:headerLines]
+	}
+	head := strings.Join(lines, "\n")
+
+	if match := spdxPattern.FindStringSubmatch(head); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return Identify(head)
+}
+
+// licenseFilePattern matches the names license files go by: LICENSE,
+// LICENCE, COPYING, LICENSE-MIT, LICENSE.md, COPYING.txt, ...
+var licenseFilePattern = regexp.MustCompile(`(?i)^(un)?(licen[cs]e|copying)([-._][A-Za-z0-9.-]*)?$`)
+
+// IsLicenseFile reports whether a file name is that of a license file
+func IsLicenseFile(name string) bool {
+	return licenseFilePattern.MatchString(name)
+}
+
+// IDs splits an SPDX license expression such as "(MIT OR GPL-2.0+)" into
+// the identifiers it names
+func IDs(expr string) []string {
+	var ids []string
+	for _, field := range strings.FieldsFunc(expr, func(r rune) bool {
+		return r == ' ' || r == '(' || r == ')'
+	}) {
+		switch strings.ToUpper(field) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		ids = append(ids, field)
+	}
+	return ids
+}
+
+// Matches reports whether any identifier in expr starts with prefix,
+// ignoring case, so "GPL" matches "GPL-2.0-only" and "GPL-3.0+" but not
+// "LGPL-2.1"
+func Matches(expr, prefix string) bool {
+	for _, id := range IDs(expr) {
+		if len(id) >= len(prefix) && strings.EqualFold(id[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
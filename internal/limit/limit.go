@@ -0,0 +1,144 @@
+// Package limit parses the --threshold and --hard-max size limits, given in
+// megabytes, in tokens, or as a percentage of a model's context window.
+package limit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// ContextWindows maps model names to their context window in tokens. A
+// --model name matches the longest entry it starts with, so dated or
+// suffixed names such as claude-sonnet-4-5-20250929 resolve too.
+var ContextWindows = map[string]int64{
+	"gpt-4o":            128000,
+	"gpt-4.1":           1047576,
+	"gpt-5":             400000,
+	"o3":                200000,
+	"o4-mini":           200000,
+	"claude-opus-4":     200000,
+	"claude-sonnet-4":   200000,
+	"claude-haiku-4":    200000,
+	"claude-3-5-sonnet": 200000,
+	"claude-3-5-haiku":  200000,
+	"gemini-2.5-pro":    1048576,
+	"gemini-2.5-flash":  1048576,
+	"llama-3.1":         128000,
+	"mistral-large":     128000,
+}
+
+// ContextWindow returns the context window of model in tokens
+func ContextWindow(model string) (int64, error) {
+	name := strings.ToLower(model)
+	best := ""
+	for known := range ContextWindows {
+		if strings.HasPrefix(name, known) && len(known) > len(best) {
+			best = known
+		}
+	}
+	if best == "" {
+		known := make([]string, 0, len(ContextWindows))
+		for k := range ContextWindows {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+		return 0, fmt.Errorf("unknown model %q (known: %s)", model, strings.Join(known, ", "))
+	}
+	return ContextWindows[best], nil
+}
+
+// Limit is a size limit in megabytes or in estimated tokens
+type Limit struct {
+	// MB is the limit in megabytes; 0 when the limit is in tokens
+	MB float64
+	// Tokens is the limit in estimated tokens; 0 when the limit is in
+	// megabytes
+	Tokens int64
+	// Note explains a limit derived from a model, e.g. "80% of gpt-4o"
+	Note string
+}
+
+// Parse reads a limit: a plain number of megabytes ("2.5"), a token count
+// ("128k-tokens", "200000 tokens"), or a percentage of model's context
+// window ("80%")
+func Parse(spec, model string) (Limit, error) {
+	s := strings.ToLower(strings.TrimSpace(spec))
+
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || percent <= 0 {
+			return Limit{}, fmt.Errorf("invalid percentage %q", spec)
+		}
+		if model == "" {
+			return Limit{}, fmt.Errorf("%q is a percentage of a model's context window; set --model", spec)
+		}
+		window, err := ContextWindow(model)
+		if err != nil {
+			return Limit{}, err
+		}
+		return Limit{Tokens: int64(float64(window) * percent / 100), Note: fmt.Sprintf("%s of %s", spec, model)}, nil
+	}
+
+	for _, suffix := range []string{"-tokens", " tokens", "tokens"} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			tokens, err := parseCount(strings.TrimSpace(n))
+			if err != nil || tokens <= 0 {
+				return Limit{}, fmt.Errorf("invalid token count %q", spec)
+			}
+			return Limit{Tokens: tokens}, nil
+		}
+	}
+
+	mb, err := strconv.ParseFloat(s, 64)
+	if err != nil || mb < 0 {
+		return Limit{}, fmt.Errorf("invalid size limit %q (use megabytes like 2.5, tokens like 128k-tokens, or a percentage like 80%% with --model)", spec)
+	}
+	return Limit{MB: mb}, nil
+}
+
+// parseCount reads a count with an optional k or m suffix
+func parseCount(s string) (int64, error) {
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		multiplier, s = 1e6, strings.TrimSuffix(s, "m")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n * multiplier), nil
+}
+
+// Exceeds reports whether size bytes exceed the limit
+func (l Limit) Exceeds(size int64) bool {
+	if l.Tokens > 0 {
+		return collector.EstimateTokens(size) > l.Tokens
+	}
+	return float64(size)/(1024*1024) > l.MB
+}
+
+// String describes the limit in its own unit
+func (l Limit) String() string {
+	if l.Tokens == 0 {
+		return fmt.Sprintf("%.2f MB", l.MB)
+	}
+	if l.Note != "" {
+		return fmt.Sprintf("%d tokens, %s", l.Tokens, l.Note)
+	}
+	return fmt.Sprintf("%d tokens", l.Tokens)
+}
+
+// Measure describes size bytes in the limit's unit
+func (l Limit) Measure(size int64) string {
+	if l.Tokens == 0 {
+		return fmt.Sprintf("%.2f MB", float64(size)/(1024*1024))
+	}
+	return fmt.Sprintf("~%d tokens", collector.EstimateTokens(size))
+}
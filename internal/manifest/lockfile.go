@@ -0,0 +1,221 @@
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// lockManifests maps the lock files ParseLock understands to the manifest
+// that sits next to them
+var lockManifests = map[string]string{
+	"package-lock.json": "package.json",
+	"go.sum":            "go.mod",
+	"Cargo.lock":        "Cargo.toml",
+}
+
+// Lockfiles are the lock file names ParseLock understands
+var Lockfiles = []string{"package-lock.json", "go.sum", "Cargo.lock"}
+
+// IsLockfile reports whether path names a lock file ParseLock understands
+func IsLockfile(path string) bool {
+	_, ok := lockManifests[filepath.Base(path)]
+	return ok
+}
+
+// LockManifest returns the base name of the manifest next to the lock file
+// at path, which ParseLock needs to tell direct dependencies apart where
+// the lock file doesn't record them
+func LockManifest(path string) string {
+	return lockManifests[filepath.Base(path)]
+}
+
+// Lock is what a lock file says about the direct dependencies of a project
+type Lock struct {
+	// File is the base name of the lock file
+	File string
+	// Dependencies are the direct dependencies at the exact versions they
+	// are locked to. With Complete unset, they are every locked package.
+	Dependencies []Dependency
+	// Complete reports whether the direct dependencies could be told apart
+	// from the transitive ones
+	Complete bool
+	// Total is the number of packages locked, direct or not
+	Total int
+}
+
+// ParseLock reads the lock file at path from data. manifest holds the
+// manifest next to it (see LockManifest), or nil if there is none; it is
+// only consulted for lock files that don't record which dependencies are
+// direct. ParseLock returns nil if path is not a lock file or data can't
+// be parsed. Dependencies are sorted as by Parse.
+func ParseLock(path string, data, manifest []byte) *Lock {
+	var l *Lock
+	switch filepath.Base(path) {
+	case "package-lock.json":
+		l = parsePackageLock(data, manifest)
+	case "go.sum":
+		l = parseGoSum(data, manifest)
+	case "Cargo.lock":
+		l = parseCargoLock(data)
+	}
+	if l == nil {
+		return nil
+	}
+	l.File = filepath.Base(path)
+	sort.SliceStable(l.Dependencies, func(i, j int) bool {
+		if l.Dependencies[i].Dev != l.Dependencies[j].Dev {
+			return !l.Dependencies[i].Dev
+		}
+		return l.Dependencies[i].Name < l.Dependencies[j].Name
+	})
+	return l
+}
+
+// parsePackageLock reads npm lock files. Versions 2 and 3 record the root
+// package's dependencies under packages[""]; version 1 only has the
+// hoisted tree, so package.json names the direct dependencies.
+func parsePackageLock(data, manifest []byte) *Lock {
+	type lockedPackage struct {
+		Version              string            `json:"version"`
+		Dev                  bool              `json:"dev"`
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	var lock struct {
+		Packages     map[string]lockedPackage `json:"packages"`
+		Dependencies map[string]lockedPackage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+
+	if root, ok := lock.Packages[""]; ok {
+		l := &Lock{Complete: true, Total: len(lock.Packages) - 1}
+		add := func(names map[string]string, dev bool) {
+			for name := range names {
+				version := lock.Packages["node_modules/"+name].Version
+				l.Dependencies = append(l.Dependencies, Dependency{Name: name, Version: version, Dev: dev})
+			}
+		}
+		add(root.Dependencies, false)
+		add(root.OptionalDependencies, false)
+		add(root.DevDependencies, true)
+		return l
+	}
+
+	l := &Lock{Total: len(lock.Dependencies)}
+	direct := map[string]bool{}
+	if m := parsePackageJSON(manifest); m != nil {
+		l.Complete = true
+		for _, dep := range m.Dependencies {
+			direct[dep.Name] = true
+		}
+	}
+	for name, pkg := range lock.Dependencies {
+		if l.Complete && !direct[name] {
+			continue
+		}
+		l.Dependencies = append(l.Dependencies, Dependency{Name: name, Version: pkg.Version, Dev: pkg.Dev})
+	}
+	return l
+}
+
+// parseGoSum reads the module versions go.sum holds checksums for. go.sum
+// doesn't mark direct dependencies, so they come from go.mod; without it,
+// every module is listed at the highest version whose source is summed,
+// which is the last, since the go command keeps go.sum sorted.
+func parseGoSum(data, manifest []byte) *Lock {
+	versions := map[string]string{} // module -> last version with a source checksum
+	modules := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		module, version := fields[0], fields[1]
+		modules[module] = true
+		if strings.HasSuffix(version, "/go.mod") {
+			continue
+		}
+		versions[module] = version
+	}
+	if len(modules) == 0 && len(data) > 0 {
+		return nil
+	}
+
+	l := &Lock{Total: len(modules)}
+	if manifest != nil {
+		if m := parseGoMod(manifest); m != nil {
+			l.Complete = true
+			for _, dep := range m.Dependencies {
+				if modules[dep.Name] {
+					l.Dependencies = append(l.Dependencies, dep)
+				}
+			}
+			return l
+		}
+	}
+	for module, version := range versions {
+		l.Dependencies = append(l.Dependencies, Dependency{Name: module, Version: version})
+	}
+	return l
+}
+
+// parseCargoLock reads Cargo.lock. Packages without a source are the
+// workspace's own; their dependencies are the direct ones. A dependency
+// is recorded as "name", or as "name version" when several versions of it
+// are locked.
+func parseCargoLock(data []byte) *Lock {
+	var lock struct {
+		Package []struct {
+			Name         string   `toml:"name"`
+			Version      string   `toml:"version"`
+			Source       string   `toml:"source"`
+			Dependencies []string `toml:"dependencies"`
+		} `toml:"package"`
+	}
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+
+	versions := map[string][]string{}
+	members := map[string]bool{}
+	for _, pkg := range lock.Package {
+		versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+		if pkg.Source == "" {
+			members[pkg.Name] = true
+		}
+	}
+
+	l := &Lock{Complete: true, Total: len(lock.Package) - len(members)}
+	seen := map[string]bool{}
+	for _, pkg := range lock.Package {
+		if pkg.Source != "" {
+			continue
+		}
+		for _, spec := range pkg.Dependencies {
+			fields := strings.Fields(spec)
+			if len(fields) == 0 || members[fields[0]] {
+				continue
+			}
+			name, version := fields[0], ""
+			if len(fields) > 1 {
+				version = fields[1]
+			} else if len(versions[name]) > 0 {
+				version = versions[name][0]
+			}
+			if key := name + " " + version; !seen[key] {
+				seen[key] = true
+				l.Dependencies = append(l.Dependencies, Dependency{Name: name, Version: version})
+			}
+		}
+	}
+	return l
+}
@@ -0,0 +1,271 @@
+// Package manifest reads dependency manifests (go.mod, package.json,
+// requirements.txt, pyproject.toml, Cargo.toml) down to the runtime they
+// target and their direct dependencies.
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Dependency is a direct dependency and the version or constraint it is
+// pinned to ("" when unconstrained)
+type Dependency struct {
+	Name    string
+	Version string
+	// Dev marks development dependencies, and Python optional ones
+	Dev bool
+}
+
+// String renders the dependency as "name version", marking dev
+// dependencies
+func (d Dependency) String() string {
+	s := d.Name
+	if d.Version != "" {
+		s += " " + d.Version
+	}
+	if d.Dev {
+		s += " (dev)"
+	}
+	return s
+}
+
+// Manifest is what a manifest file says about a project
+type Manifest struct {
+	// File is the base name of the manifest
+	File string
+	// Runtime names the language ("Go", "Node.js", "Python", "Rust") and
+	// RuntimeVersion the version it requires, if stated
+	Runtime        string
+	RuntimeVersion string
+	Dependencies   []Dependency
+}
+
+// Files are the manifest names Parse understands
+var Files = []string{"go.mod", "package.json", "requirements.txt", "pyproject.toml", "Cargo.toml"}
+
+// IsManifest reports whether path names a manifest Parse understands
+func IsManifest(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range Files {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads the manifest at path from data. It returns nil if path is
+// not a manifest or data can't be parsed. Dependencies are sorted by name,
+// runtime dependencies before dev dependencies.
+func Parse(path string, data []byte) *Manifest {
+	var m *Manifest
+	switch filepath.Base(path) {
+	case "go.mod":
+		m = parseGoMod(data)
+	case "package.json":
+		m = parsePackageJSON(data)
+	case "requirements.txt":
+		m = parseRequirements(data)
+	case "pyproject.toml":
+		m = parsePyproject(data)
+	case "Cargo.toml":
+		m = parseCargo(data)
+	}
+	if m == nil {
+		return nil
+	}
+	m.File = filepath.Base(path)
+	sort.SliceStable(m.Dependencies, func(i, j int) bool {
+		if m.Dependencies[i].Dev != m.Dependencies[j].Dev {
+			return !m.Dependencies[i].Dev
+		}
+		return m.Dependencies[i].Name < m.Dependencies[j].Name
+	})
+	return m
+}
+
+// parseGoMod reads the go directive and the requirements not marked
+// // indirect
+func parseGoMod(data []byte) *Manifest {
+	m := &Manifest{Runtime: "Go"}
+	inRequire := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case inRequire && line == ")":
+			inRequire = false
+			continue
+		case line == "require (":
+			inRequire = true
+			continue
+		case strings.HasPrefix(line, "go "):
+			m.RuntimeVersion = strings.TrimSpace(strings.TrimPrefix(line, "go "))
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inRequire:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 2 && !indirect {
+			m.Dependencies = append(m.Dependencies, Dependency{Name: fields[0], Version: fields[1]})
+		}
+	}
+	return m
+}
+
+func parsePackageJSON(data []byte) *Manifest {
+	var pkg struct {
+		Engines         map[string]string `json:"engines"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	m := &Manifest{Runtime: "Node.js", RuntimeVersion: pkg.Engines["node"]}
+	for name, version := range pkg.Dependencies {
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: version})
+	}
+	for name, version := range pkg.DevDependencies {
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: version, Dev: true})
+	}
+	return m
+}
+
+// requirementPattern splits a PEP 508 requirement into its name (with
+// extras) and version specifier, ignoring environment markers
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*(?:\[[^\]]*\])?)\s*([^;]*)`)
+
+// parseRequirement splits a requirement such as
+// "requests>=2.31; python_version>'3'" into its name and version
+func parseRequirement(s string) (Dependency, bool) {
+	match := requirementPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return Dependency{}, false
+	}
+	return Dependency{Name: match[1], Version: strings.TrimSpace(match[2])}, true
+}
+
+// parseRequirements reads a pip requirements file, skipping options (-r,
+// -e, --index-url) and comments
+func parseRequirements(data []byte) *Manifest {
+	m := &Manifest{Runtime: "Python"}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if dep, ok := parseRequirement(line); ok {
+			m.Dependencies = append(m.Dependencies, dep)
+		}
+	}
+	return m
+}
+
+// parsePyproject reads PEP 621 [project] metadata, falling back to
+// [tool.poetry]
+func parsePyproject(data []byte) *Manifest {
+	var pyproject struct {
+		Project struct {
+			RequiresPython       string              `toml:"requires-python"`
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Dependencies    map[string]any `toml:"dependencies"`
+				DevDependencies map[string]any `toml:"dev-dependencies"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(data, &pyproject); err != nil {
+		return nil
+	}
+
+	m := &Manifest{Runtime: "Python", RuntimeVersion: pyproject.Project.RequiresPython}
+	for _, s := range pyproject.Project.Dependencies {
+		if dep, ok := parseRequirement(s); ok {
+			m.Dependencies = append(m.Dependencies, dep)
+		}
+	}
+	for _, group := range pyproject.Project.OptionalDependencies {
+		for _, s := range group {
+			if dep, ok := parseRequirement(s); ok {
+				dep.Dev = true
+				m.Dependencies = append(m.Dependencies, dep)
+			}
+		}
+	}
+
+	poetry := pyproject.Tool.Poetry
+	for name, spec := range poetry.Dependencies {
+		if name == "python" {
+			if m.RuntimeVersion == "" {
+				m.RuntimeVersion = specVersion(spec)
+			}
+			continue
+		}
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: specVersion(spec)})
+	}
+	for name, spec := range poetry.DevDependencies {
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: specVersion(spec), Dev: true})
+	}
+	return m
+}
+
+func parseCargo(data []byte) *Manifest {
+	var cargo struct {
+		Package struct {
+			RustVersion string `toml:"rust-version"`
+		} `toml:"package"`
+		Dependencies    map[string]any `toml:"dependencies"`
+		DevDependencies map[string]any `toml:"dev-dependencies"`
+	}
+	if err := toml.Unmarshal(data, &cargo); err != nil {
+		return nil
+	}
+
+	m := &Manifest{Runtime: "Rust", RuntimeVersion: cargo.Package.RustVersion}
+	for name, spec := range cargo.Dependencies {
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: specVersion(spec)})
+	}
+	for name, spec := range cargo.DevDependencies {
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: specVersion(spec), Dev: true})
+	}
+	return m
+}
+
+// specVersion returns the version of a TOML dependency given either as a
+// string or as a table with a version key
+func specVersion(spec any) string {
+	switch spec := spec.(type) {
+	case string:
+		return spec
+	case map[string]any:
+		if version, ok := spec["version"].(string); ok {
+			return version
+		}
+	}
+	return ""
+}
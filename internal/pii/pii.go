@@ -0,0 +1,61 @@
+package pii
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Finding is a likely piece of personal data found in a file
+type Finding struct {
+	Path  string
+	Line  int
+	Kind  string
+	Match string
+}
+
+type detector struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+var detectors = []detector{
+	{"email", regexp.MustCompile(`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+\.)+[A-Za-z]{2,}`)},
+	{"phone", regexp.MustCompile(`(\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b`)},
+	{"phone", regexp.MustCompile(`\+\d{1,3}[ .-]\d{1,4}([ .-]\d{2,4}){2,3}\b`)},
+	{"us-ssn", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	{"uk-nino", regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`)},
+}
+
+// ignoredEmailDomains are placeholder domains that never identify anyone
+var ignoredEmailDomains = []string{"example.com", "example.org", "example.net", "users.noreply.github.com"}
+
+// Scan reports likely emails, phone numbers, and national ID numbers in
+// content, one finding per match with its 1-based line number
+func Scan(path, content string) []Finding {
+	var findings []Finding
+
+	for i, line := range strings.Split(content, "\n") {
+		seen := make(map[string]bool)
+		for _, d := range detectors {
+			for _, match := range d.pattern.FindAllString(line, -1) {
+				if seen[match] || (d.kind == "email" && isPlaceholderEmail(match)) {
+					continue
+				}
+				seen[match] = true
+				findings = append(findings, Finding{Path: path, Line: i + 1, Kind: d.kind, Match: match})
+			}
+		}
+	}
+
+	return findings
+}
+
+func isPlaceholderEmail(email string) bool {
+	email = strings.ToLower(email)
+	for _, domain := range ignoredEmailDomains {
+		if strings.HasSuffix(email, "@"+domain) || strings.HasSuffix(email, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
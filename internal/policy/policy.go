@@ -0,0 +1,157 @@
+// Package policy reads the organization policy file. Administrators install
+// it read-only at /etc/bcopy/policy.yaml to constrain every run: what must
+// be redacted, which paths can never be selected, how large a payload may
+// be, and where it may go. A file named in BCOPY_POLICY adds restrictions
+// on top. Nothing in the user's config, environment, or flags can loosen
+// it.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// EnvVar points to a policy file whose restrictions are added to those of
+// the file at the default path
+const EnvVar = "BCOPY_POLICY"
+
+// Targets are the destinations a policy can disallow: the output sinks,
+// the servers, and the services bcopy holds tokens for
+var Targets = []string{"clipboard", "stdout", "file", "slot", "share", "serve", "github", "openai", "anthropic"}
+
+// Policy is the parsed policy file
+type Policy struct {
+	// Path is the file the policy was read from
+	Path string `yaml:"-"`
+	// Redact forces --anonymize, with RedactReplace added to the user's
+	// --anonymize-replace pairs (old=new)
+	Redact        bool     `yaml:"redact"`
+	RedactReplace []string `yaml:"redact-replace"`
+	// BannedPaths are gitignore-style patterns no run may select
+	BannedPaths []string `yaml:"banned-paths"`
+	// MaxPayloadMB caps the payload size (0 = no cap)
+	MaxPayloadMB float64 `yaml:"max-payload-mb"`
+	// DisallowedTargets lists Targets output may not go to
+	DisallowedTargets []string `yaml:"disallowed-targets"`
+}
+
+// DefaultPath is where the policy file is installed
+func DefaultPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "bcopy", "policy.yaml")
+	}
+	return "/etc/bcopy/policy.yaml"
+}
+
+// Load reads the policy file at the default path and the one named by
+// BCOPY_POLICY, combining their restrictions, so the environment can only
+// tighten the installed policy and never replace it. It returns nil when
+// neither exists, and an error when BCOPY_POLICY names a missing file or
+// either file is invalid: a policy that can't be read must not be silently
+// ignored.
+func Load() (*Policy, error) {
+	return load(DefaultPath())
+}
+
+// load is Load with the installed policy read from systemPath
+func load(systemPath string) (*Policy, error) {
+	p, err := read(systemPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		p, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	path := os.Getenv(EnvVar)
+	if path == "" {
+		return p, nil
+	}
+	extra, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.merge(extra), nil
+}
+
+// read reads and validates the policy file at path
+func read(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+
+	p := &Policy{Path: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	if p.MaxPayloadMB < 0 {
+		return nil, fmt.Errorf("policy %s: max-payload-mb can't be negative", path)
+	}
+	for _, target := range p.DisallowedTargets {
+		if !slices.Contains(Targets, target) {
+			return nil, fmt.Errorf("policy %s: unknown target %q in disallowed-targets (use %s)", path, target, strings.Join(Targets, ", "))
+		}
+	}
+	return p, nil
+}
+
+// merge returns a policy holding the restrictions of both p and other:
+// redaction if either redacts, every banned path and disallowed target,
+// and the smaller payload cap. p may be nil.
+func (p *Policy) merge(other *Policy) *Policy {
+	if p == nil {
+		return other
+	}
+	merged := &Policy{
+		Path:              p.Path + ", " + other.Path,
+		Redact:            p.Redact || other.Redact,
+		RedactReplace:     slices.Concat(p.RedactReplace, other.RedactReplace),
+		BannedPaths:       slices.Concat(p.BannedPaths, other.BannedPaths),
+		MaxPayloadMB:      p.MaxPayloadMB,
+		DisallowedTargets: slices.Clone(p.DisallowedTargets),
+	}
+	if other.MaxPayloadMB > 0 && (merged.MaxPayloadMB == 0 || other.MaxPayloadMB < merged.MaxPayloadMB) {
+		merged.MaxPayloadMB = other.MaxPayloadMB
+	}
+	for _, target := range other.DisallowedTargets {
+		if !slices.Contains(merged.DisallowedTargets, target) {
+			merged.DisallowedTargets = append(merged.DisallowedTargets, target)
+		}
+	}
+	return merged
+}
+
+// Allows reports whether output may go to target. A nil policy allows
+// everything.
+func (p *Policy) Allows(target string) bool {
+	return p == nil || !slices.Contains(p.DisallowedTargets, target)
+}
+
+// Check returns an error naming the policy file when target is disallowed
+func (p *Policy) Check(target string) error {
+	if p.Allows(target) {
+		return nil
+	}
+	return fmt.Errorf("%s is disallowed by the policy in %s", target, p.Path)
+}
+
+// Banned returns the banned path patterns; none for a nil policy
+func (p *Policy) Banned() []string {
+	if p == nil {
+		return nil
+	}
+	return p.BannedPaths
+}
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writePolicy writes a policy file into a temporary directory and returns
+// its path
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	const system = `redact: true
+redact-replace: [acme=example]
+banned-paths: ["*.pem"]
+max-payload-mb: 5
+disallowed-targets: [share]
+`
+	tests := []struct {
+		name    string
+		system  string // "" for no installed policy
+		env     string // BCOPY_POLICY content; "" leaves it unset
+		envPath string // BCOPY_POLICY path when env is empty
+		want    *Policy
+		wantErr string
+	}{
+		{name: "no policy"},
+		{
+			name:   "installed only",
+			system: system,
+			want: &Policy{
+				Redact:            true,
+				RedactReplace:     []string{"acme=example"},
+				BannedPaths:       []string{"*.pem"},
+				MaxPayloadMB:      5,
+				DisallowedTargets: []string{"share"},
+			},
+		},
+		{
+			name: "environment only",
+			env:  "disallowed-targets: [clipboard]\n",
+			want: &Policy{DisallowedTargets: []string{"clipboard"}},
+		},
+		{
+			name:   "environment tightens",
+			system: system,
+			env:    "redact-replace: [corp=co]\nbanned-paths: [secrets/]\nmax-payload-mb: 1\ndisallowed-targets: [share, clipboard]\n",
+			want: &Policy{
+				Redact:            true,
+				RedactReplace:     []string{"acme=example", "corp=co"},
+				BannedPaths:       []string{"*.pem", "secrets/"},
+				MaxPayloadMB:      1,
+				DisallowedTargets: []string{"share", "clipboard"},
+			},
+		},
+		{
+			name:   "environment can't loosen",
+			system: system,
+			env:    "redact: false\nmax-payload-mb: 50\n",
+			want: &Policy{
+				Redact:            true,
+				RedactReplace:     []string{"acme=example"},
+				BannedPaths:       []string{"*.pem"},
+				MaxPayloadMB:      5,
+				DisallowedTargets: []string{"share"},
+			},
+		},
+		{
+			name:    "empty environment file doesn't replace",
+			system:  system,
+			envPath: os.DevNull,
+			want: &Policy{
+				Redact:            true,
+				RedactReplace:     []string{"acme=example"},
+				BannedPaths:       []string{"*.pem"},
+				MaxPayloadMB:      5,
+				DisallowedTargets: []string{"share"},
+			},
+		},
+		{name: "missing environment file", envPath: "/nonexistent/policy.yaml", wantErr: "reading policy"},
+		{name: "unknown key", system: "redcat: true\n", wantErr: "redcat"},
+		{name: "unknown target", system: "disallowed-targets: [printer]\n", wantErr: `unknown target "printer"`},
+		{name: "negative cap", env: "max-payload-mb: -1\n", wantErr: "can't be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			systemPath := filepath.Join(t.TempDir(), "missing.yaml")
+			if tt.system != "" {
+				systemPath = writePolicy(t, tt.system)
+			}
+			envPath := tt.envPath
+			if tt.env != "" {
+				envPath = writePolicy(t, tt.env)
+			}
+			t.Setenv(EnvVar, envPath)
+
+			p, err := load(systemPath)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if p != nil {
+					t.Fatalf("load() = %+v, want nil", p)
+				}
+				return
+			}
+			if p == nil {
+				t.Fatalf("load() = nil, want %+v", tt.want)
+			}
+			if p.Redact != tt.want.Redact || p.MaxPayloadMB != tt.want.MaxPayloadMB ||
+				!slices.Equal(p.RedactReplace, tt.want.RedactReplace) ||
+				!slices.Equal(p.BannedPaths, tt.want.BannedPaths) ||
+				!slices.Equal(p.DisallowedTargets, tt.want.DisallowedTargets) {
+				t.Errorf("load() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := &Policy{Path: "/etc/bcopy/policy.yaml", DisallowedTargets: []string{"clipboard"}}
+	tests := []struct {
+		policy  *Policy
+		target  string
+		allowed bool
+	}{
+		{nil, "clipboard", true},
+		{p, "file", true},
+		{p, "clipboard", false},
+	}
+	for _, tt := range tests {
+		err := tt.policy.Check(tt.target)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%v.Check(%q) = %v, want allowed %v", tt.policy, tt.target, err, tt.allowed)
+		}
+		if err != nil && !strings.Contains(err.Error(), p.Path) {
+			t.Errorf("Check(%q) error %q doesn't name the policy file", tt.target, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package rank scores files by how central they are to a project, combining
+// import fan-in, git commit frequency, and path heuristics.
+package rank
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Weights of the individual signals in the final score
+const (
+	fanInWeight     = 2.0
+	commitsWeight   = 1.0
+	heuristicWeight = 1.0
+)
+
+// File is a candidate for ranking. Path is slash-separated and relative to
+// the project root.
+type File struct {
+	Path    string
+	Content string
+}
+
+// Result is the score of one file and the signals it was derived from
+type Result struct {
+	Path      string
+	Score     float64
+	FanIn     int // files importing this file or its package
+	Commits   int // recent commits touching this file
+	Heuristic float64
+}
+
+var (
+	goImportBlock  = regexp.MustCompile(`(?s)import\s*\((.*?)\)`)
+	goImportSingle = regexp.MustCompile(`import\s+(?:[\w.]+\s+)?"([^"]+)"`)
+	quoted         = regexp.MustCompile(`"([^"]+)"`)
+	jsImport       = regexp.MustCompile(`(?:from\s+|require\(\s*|import\(\s*|import\s+)['"](\.{1,2}/[^'"]+)['"]`)
+	pyImport       = regexp.MustCompile(`(?m)^\s*(?:from\s+([\w.]+)\s+import|import\s+([\w.]+))`)
+)
+
+// centralNames are file stems that usually hold wiring or entry points
+var centralNames = map[string]float64{
+	"main": 3, "app": 2, "server": 2, "router": 2, "routes": 2, "index": 1.5,
+	"api": 1.5, "handler": 1, "handlers": 1, "service": 1, "config": 1,
+	"cli": 1, "root": 1, "lib": 1, "mod": 1, "__init__": 0.5,
+}
+
+// Score ranks files, most central first. commits maps file paths to the
+// number of recent commits touching them and may be nil.
+func Score(files []File, commits map[string]int) []Result {
+	fanIn := importFanIn(files)
+
+	results := make([]Result, 0, len(files))
+	for _, f := range files {
+		r := Result{
+			Path:      f.Path,
+			FanIn:     fanIn[f.Path],
+			Commits:   commits[f.Path],
+			Heuristic: pathHeuristic(f.Path),
+		}
+		r.Score = fanInWeight*math.Log1p(float64(r.FanIn)) +
+			commitsWeight*math.Log1p(float64(r.Commits)) +
+			heuristicWeight*r.Heuristic
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+// pathHeuristic scores a path by its name and location: entry-point-like
+// names score up, tests, fixtures, and deeply nested files score down
+func pathHeuristic(p string) float64 {
+	base := path.Base(p)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	score := centralNames[strings.ToLower(stem)]
+
+	lower := strings.ToLower(p)
+	switch {
+	case strings.Contains(lower, "_test.") || strings.Contains(lower, ".test.") || strings.Contains(lower, ".spec."):
+		score -= 2
+	case strings.Contains(lower, "test/") || strings.Contains(lower, "tests/"):
+		score -= 2
+	case strings.Contains(lower, "fixture") || strings.Contains(lower, "mock") || strings.Contains(lower, "testdata/"):
+		score -= 2
+	}
+	if strings.HasPrefix(p, "cmd/") {
+		score += 1
+	}
+
+	score -= 0.25 * float64(strings.Count(p, "/"))
+	return score
+}
+
+// importFanIn counts, for each file, how many other files import it (or,
+// for Go, its package)
+func importFanIn(files []File) map[string]int {
+	byPath := make(map[string]bool, len(files))
+	byDir := make(map[string][]string)
+	for _, f := range files {
+		byPath[f.Path] = true
+		dir := path.Dir(f.Path)
+		byDir[dir] = append(byDir[dir], f.Path)
+	}
+
+	fanIn := make(map[string]int)
+	for _, f := range files {
+		targets := make(map[string]bool)
+		for _, t := range resolveImports(f, byPath, byDir) {
+			if t != f.Path {
+				targets[t] = true
+			}
+		}
+		for t := range targets {
+			fanIn[t]++
+		}
+	}
+	return fanIn
+}
+
+// resolveImports returns the project files that f imports
+func resolveImports(f File, byPath map[string]bool, byDir map[string][]string) []string {
+	var targets []string
+
+	switch path.Ext(f.Path) {
+	case ".go":
+		var specs []string
+		for _, block := range goImportBlock.FindAllStringSubmatch(f.Content, -1) {
+			for _, m := range quoted.FindAllStringSubmatch(block[1], -1) {
+				specs = append(specs, m[1])
+			}
+		}
+		for _, m := range goImportSingle.FindAllStringSubmatch(f.Content, -1) {
+			specs = append(specs, m[1])
+		}
+		for _, spec := range specs {
+			// Import paths end with the package directory
+			for dir, dirFiles := range byDir {
+				if dir != "." && (spec == dir || strings.HasSuffix(spec, "/"+dir)) {
+					for _, p := range dirFiles {
+						if strings.HasSuffix(p, ".go") && !strings.HasSuffix(p, "_test.go") {
+							targets = append(targets, p)
+						}
+					}
+				}
+			}
+		}
+
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue", ".svelte":
+		for _, m := range jsImport.FindAllStringSubmatch(f.Content, -1) {
+			base := path.Join(path.Dir(f.Path), m[1])
+			for _, candidate := range []string{
+				base, base + ".ts", base + ".tsx", base + ".js", base + ".jsx", base + ".mjs",
+				base + "/index.ts", base + "/index.tsx", base + "/index.js",
+			} {
+				if byPath[candidate] {
+					targets = append(targets, candidate)
+					break
+				}
+			}
+		}
+
+	case ".py":
+		for _, m := range pyImport.FindAllStringSubmatch(f.Content, -1) {
+			module := m[1]
+			if module == "" {
+				module = m[2]
+			}
+			base := strings.ReplaceAll(strings.TrimLeft(module, "."), ".", "/")
+			for _, candidate := range []string{base + ".py", base + "/__init__.py"} {
+				if byPath[candidate] {
+					targets = append(targets, candidate)
+					break
+				}
+			}
+		}
+	}
+
+	return targets
+}
@@ -0,0 +1,512 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: bcopy.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CollectRequest selects files the way the bcopy command line does
+type CollectRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Absolute path of the directory to collect
+	Root string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	// Only files with these extensions (default: bcopy's built-in list)
+	Ext []string `protobuf:"bytes,2,rep,name=ext,proto3" json:"ext,omitempty"`
+	// Regular expressions of paths to exclude
+	Exclude      []string `protobuf:"bytes,3,rep,name=exclude,proto3" json:"exclude,omitempty"`
+	ExcludeTests bool     `protobuf:"varint,4,opt,name=exclude_tests,json=excludeTests,proto3" json:"exclude_tests,omitempty"`
+	NoGitignore  bool     `protobuf:"varint,5,opt,name=no_gitignore,json=noGitignore,proto3" json:"no_gitignore,omitempty"`
+	// Maximum depth of selected files (1 = root files only, 0 = unlimited)
+	MaxDepth int32 `protobuf:"varint,6,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// Keep only files whose content matches this regular expression
+	Grep string `protobuf:"bytes,7,opt,name=grep,proto3" json:"grep,omitempty"`
+	// Prepend a table of contents
+	Toc bool `protobuf:"varint,8,opt,name=toc,proto3" json:"toc,omitempty"`
+	// Write checksummed delimiters for bcopy paste instead of markdown
+	Delimited     bool `protobuf:"varint,9,opt,name=delimited,proto3" json:"delimited,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectRequest) Reset() {
+	*x = CollectRequest{}
+	mi := &file_bcopy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectRequest) ProtoMessage() {}
+
+func (x *CollectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bcopy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectRequest.ProtoReflect.Descriptor instead.
+func (*CollectRequest) Descriptor() ([]byte, []int) {
+	return file_bcopy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CollectRequest) GetRoot() string {
+	if x != nil {
+		return x.Root
+	}
+	return ""
+}
+
+func (x *CollectRequest) GetExt() []string {
+	if x != nil {
+		return x.Ext
+	}
+	return nil
+}
+
+func (x *CollectRequest) GetExclude() []string {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+func (x *CollectRequest) GetExcludeTests() bool {
+	if x != nil {
+		return x.ExcludeTests
+	}
+	return false
+}
+
+func (x *CollectRequest) GetNoGitignore() bool {
+	if x != nil {
+		return x.NoGitignore
+	}
+	return false
+}
+
+func (x *CollectRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *CollectRequest) GetGrep() string {
+	if x != nil {
+		return x.Grep
+	}
+	return ""
+}
+
+func (x *CollectRequest) GetToc() bool {
+	if x != nil {
+		return x.Toc
+	}
+	return false
+}
+
+func (x *CollectRequest) GetDelimited() bool {
+	if x != nil {
+		return x.Delimited
+	}
+	return false
+}
+
+type CollectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payload       string                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	Stats         *CollectStats          `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectResponse) Reset() {
+	*x = CollectResponse{}
+	mi := &file_bcopy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectResponse) ProtoMessage() {}
+
+func (x *CollectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bcopy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectResponse.ProtoReflect.Descriptor instead.
+func (*CollectResponse) Descriptor() ([]byte, []int) {
+	return file_bcopy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CollectResponse) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *CollectResponse) GetStats() *CollectStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type CollectStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Files int32                  `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`
+	Size  int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// Estimated LLM tokens of the file contents
+	Tokens   int64   `protobuf:"varint,3,opt,name=tokens,proto3" json:"tokens,omitempty"`
+	Selected []*File `protobuf:"bytes,4,rep,name=selected,proto3" json:"selected,omitempty"`
+	// Paths that could not be read
+	Skipped       []string `protobuf:"bytes,5,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectStats) Reset() {
+	*x = CollectStats{}
+	mi := &file_bcopy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectStats) ProtoMessage() {}
+
+func (x *CollectStats) ProtoReflect() protoreflect.Message {
+	mi := &file_bcopy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectStats.ProtoReflect.Descriptor instead.
+func (*CollectStats) Descriptor() ([]byte, []int) {
+	return file_bcopy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CollectStats) GetFiles() int32 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *CollectStats) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CollectStats) GetTokens() int64 {
+	if x != nil {
+		return x.Tokens
+	}
+	return 0
+}
+
+func (x *CollectStats) GetSelected() []*File {
+	if x != nil {
+		return x.Selected
+	}
+	return nil
+}
+
+func (x *CollectStats) GetSkipped() []string {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type File struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *File) Reset() {
+	*x = File{}
+	mi := &file_bcopy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *File) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*File) ProtoMessage() {}
+
+func (x *File) ProtoReflect() protoreflect.Message {
+	mi := &file_bcopy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use File.ProtoReflect.Descriptor instead.
+func (*File) Descriptor() ([]byte, []int) {
+	return file_bcopy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *File) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *File) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *File) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type Chunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Chunk:
+	//
+	//	*Chunk_Data
+	//	*Chunk_Stats
+	Chunk         isChunk_Chunk `protobuf_oneof:"chunk"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chunk) Reset() {
+	*x = Chunk{}
+	mi := &file_bcopy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chunk) ProtoMessage() {}
+
+func (x *Chunk) ProtoReflect() protoreflect.Message {
+	mi := &file_bcopy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
+func (*Chunk) Descriptor() ([]byte, []int) {
+	return file_bcopy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Chunk) GetChunk() isChunk_Chunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *Chunk) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Chunk.(*Chunk_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+func (x *Chunk) GetStats() *CollectStats {
+	if x != nil {
+		if x, ok := x.Chunk.(*Chunk_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+type isChunk_Chunk interface {
+	isChunk_Chunk()
+}
+
+type Chunk_Data struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3,oneof"`
+}
+
+type Chunk_Stats struct {
+	Stats *CollectStats `protobuf:"bytes,2,opt,name=stats,proto3,oneof"`
+}
+
+func (*Chunk_Data) isChunk_Chunk() {}
+
+func (*Chunk_Stats) isChunk_Chunk() {}
+
+var File_bcopy_proto protoreflect.FileDescriptor
+
+const file_bcopy_proto_rawDesc = "" +
+	"\n" +
+	"\vbcopy.proto\x12\bbcopy.v1\"\xf9\x01\n" +
+	"\x0eCollectRequest\x12\x12\n" +
+	"\x04root\x18\x01 \x01(\tR\x04root\x12\x10\n" +
+	"\x03ext\x18\x02 \x03(\tR\x03ext\x12\x18\n" +
+	"\aexclude\x18\x03 \x03(\tR\aexclude\x12#\n" +
+	"\rexclude_tests\x18\x04 \x01(\bR\fexcludeTests\x12!\n" +
+	"\fno_gitignore\x18\x05 \x01(\bR\vnoGitignore\x12\x1b\n" +
+	"\tmax_depth\x18\x06 \x01(\x05R\bmaxDepth\x12\x12\n" +
+	"\x04grep\x18\a \x01(\tR\x04grep\x12\x10\n" +
+	"\x03toc\x18\b \x01(\bR\x03toc\x12\x1c\n" +
+	"\tdelimited\x18\t \x01(\bR\tdelimited\"Y\n" +
+	"\x0fCollectResponse\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\tR\apayload\x12,\n" +
+	"\x05stats\x18\x02 \x01(\v2\x16.bcopy.v1.CollectStatsR\x05stats\"\x96\x01\n" +
+	"\fCollectStats\x12\x14\n" +
+	"\x05files\x18\x01 \x01(\x05R\x05files\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06tokens\x18\x03 \x01(\x03R\x06tokens\x12*\n" +
+	"\bselected\x18\x04 \x03(\v2\x0e.bcopy.v1.FileR\bselected\x12\x18\n" +
+	"\askipped\x18\x05 \x03(\tR\askipped\"J\n" +
+	"\x04File\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"V\n" +
+	"\x05Chunk\x12\x14\n" +
+	"\x04data\x18\x01 \x01(\fH\x00R\x04data\x12.\n" +
+	"\x05stats\x18\x02 \x01(\v2\x16.bcopy.v1.CollectStatsH\x00R\x05statsB\a\n" +
+	"\x05chunk2\xbd\x01\n" +
+	"\tCollector\x12>\n" +
+	"\aCollect\x12\x18.bcopy.v1.CollectRequest\x1a\x19.bcopy.v1.CollectResponse\x129\n" +
+	"\x05Stats\x12\x18.bcopy.v1.CollectRequest\x1a\x16.bcopy.v1.CollectStats\x125\n" +
+	"\x06Stream\x12\x18.bcopy.v1.CollectRequest\x1a\x0f.bcopy.v1.Chunk0\x01B(Z&github.com/nodelike/bcopy/internal/rpcb\x06proto3"
+
+var (
+	file_bcopy_proto_rawDescOnce sync.Once
+	file_bcopy_proto_rawDescData []byte
+)
+
+func file_bcopy_proto_rawDescGZIP() []byte {
+	file_bcopy_proto_rawDescOnce.Do(func() {
+		file_bcopy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bcopy_proto_rawDesc), len(file_bcopy_proto_rawDesc)))
+	})
+	return file_bcopy_proto_rawDescData
+}
+
+var file_bcopy_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_bcopy_proto_goTypes = []any{
+	(*CollectRequest)(nil),  // 0: bcopy.v1.CollectRequest
+	(*CollectResponse)(nil), // 1: bcopy.v1.CollectResponse
+	(*CollectStats)(nil),    // 2: bcopy.v1.CollectStats
+	(*File)(nil),            // 3: bcopy.v1.File
+	(*Chunk)(nil),           // 4: bcopy.v1.Chunk
+}
+var file_bcopy_proto_depIdxs = []int32{
+	2, // 0: bcopy.v1.CollectResponse.stats:type_name -> bcopy.v1.CollectStats
+	3, // 1: bcopy.v1.CollectStats.selected:type_name -> bcopy.v1.File
+	2, // 2: bcopy.v1.Chunk.stats:type_name -> bcopy.v1.CollectStats
+	0, // 3: bcopy.v1.Collector.Collect:input_type -> bcopy.v1.CollectRequest
+	0, // 4: bcopy.v1.Collector.Stats:input_type -> bcopy.v1.CollectRequest
+	0, // 5: bcopy.v1.Collector.Stream:input_type -> bcopy.v1.CollectRequest
+	1, // 6: bcopy.v1.Collector.Collect:output_type -> bcopy.v1.CollectResponse
+	2, // 7: bcopy.v1.Collector.Stats:output_type -> bcopy.v1.CollectStats
+	4, // 8: bcopy.v1.Collector.Stream:output_type -> bcopy.v1.Chunk
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_bcopy_proto_init() }
+func file_bcopy_proto_init() {
+	if File_bcopy_proto != nil {
+		return
+	}
+	file_bcopy_proto_msgTypes[4].OneofWrappers = []any{
+		(*Chunk_Data)(nil),
+		(*Chunk_Stats)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bcopy_proto_rawDesc), len(file_bcopy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bcopy_proto_goTypes,
+		DependencyIndexes: file_bcopy_proto_depIdxs,
+		MessageInfos:      file_bcopy_proto_msgTypes,
+	}.Build()
+	File_bcopy_proto = out.File
+	file_bcopy_proto_goTypes = nil
+	file_bcopy_proto_depIdxs = nil
+}
@@ -0,0 +1,6 @@
+// Package rpc holds the gRPC service served by bcopy serve --grpc, generated
+// from bcopy.proto. Clients in other languages generate their stubs from the
+// same file.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative bcopy.proto
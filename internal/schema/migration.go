@@ -0,0 +1,122 @@
+// Package schema squashes sequential SQL migrations into one synthesized
+// view of the current schema
+package schema
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Tool is the migration tool whose naming convention a file follows
+type Tool string
+
+const (
+	Goose   Tool = "goose"
+	Migrate Tool = "golang-migrate"
+	Flyway  Tool = "flyway"
+)
+
+// Migration is one up migration
+type Migration struct {
+	Path    string // slash-separated, relative to the project root
+	Tool    Tool
+	Version string
+	// Repeatable Flyway migrations (R__name.sql) have no version and run
+	// after all versioned ones, in name order
+	Repeatable bool
+	Content    string
+}
+
+var (
+	// 001_init.up.sql, 20240101120000_add_users.down.sql
+	migrateName = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+	// V1__init.sql, V1.2__add_users.sql, U1__undo.sql, R__views.sql
+	flywayName = regexp.MustCompile(`^([VUR])([0-9._]*)__.*\.sql$`)
+	// 00001_init.sql, 20240101120000_add_users.sql
+	gooseName = regexp.MustCompile(`^(\d+)_.*\.sql$`)
+)
+
+// Parse recognizes a migration file by its name. Down and undo migrations
+// are reported with ok set and down set, so callers can drop them.
+func Parse(relPath string) (m Migration, down, ok bool) {
+	name := path.Base(relPath)
+	m.Path = relPath
+
+	if match := migrateName.FindStringSubmatch(name); match != nil {
+		m.Tool, m.Version = Migrate, match[1]
+		return m, match[2] == "down", true
+	}
+	if match := flywayName.FindStringSubmatch(name); match != nil {
+		m.Tool, m.Version = Flyway, match[2]
+		switch match[1] {
+		case "U":
+			return m, true, true
+		case "R":
+			m.Repeatable = true
+			return m, false, true
+		}
+		return m, false, m.Version != ""
+	}
+	if match := gooseName.FindStringSubmatch(name); match != nil {
+		m.Tool, m.Version = Goose, match[1]
+		return m, false, true
+	}
+	return m, false, false
+}
+
+// UpSection returns the part of a migration that applies it: the section
+// after "-- +goose Up" or "-- migrate:up" when the file has both
+// directions, and the whole file otherwise. Tool annotations are removed.
+func UpSection(content string) string {
+	var sb strings.Builder
+	up := true
+	for _, line := range strings.SplitAfter(content, "\n") {
+		marker := strings.ToLower(strings.Join(strings.Fields(line), " "))
+		switch {
+		case marker == "-- +goose up" || marker == "-- migrate:up":
+			up = true
+			continue
+		case marker == "-- +goose down" || marker == "-- migrate:down":
+			up = false
+			continue
+		case strings.HasPrefix(marker, "-- +goose"):
+			continue
+		}
+		if up {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// Less orders migrations by version, numerically per dot- or
+// underscore-separated segment, with repeatable migrations last
+func Less(a, b Migration) bool {
+	if a.Repeatable != b.Repeatable {
+		return b.Repeatable
+	}
+	if a.Repeatable {
+		return a.Path < b.Path
+	}
+
+	as := strings.FieldsFunc(a.Version, isVersionSep)
+	bs := strings.FieldsFunc(b.Version, isVersionSep)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, y := strings.TrimLeft(as[i], "0"), strings.TrimLeft(bs[i], "0")
+		if len(x) != len(y) {
+			return len(x) < len(y)
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	if len(as) != len(bs) {
+		return len(as) < len(bs)
+	}
+	return a.Path < b.Path
+}
+
+func isVersionSep(r rune) bool {
+	return r == '.' || r == '_'
+}
@@ -0,0 +1,139 @@
+package schema
+
+import "strings"
+
+// splitStatements splits SQL into statements at top-level semicolons,
+// skipping quoted strings, dollar-quoted bodies, and comments. Comments are
+// dropped from the result.
+func splitStatements(sql string) []string {
+	var statements []string
+	var sb strings.Builder
+
+	flush := func() {
+		if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		sb.Reset()
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+				sb.WriteByte('\n')
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+			sb.WriteByte(' ')
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(sql, i)
+			sb.WriteString(sql[i:end])
+			i = end - 1
+		case c == '$':
+			if tag := dollarTag(sql[i:]); tag != "" {
+				end := strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					end = len(sql)
+				} else {
+					end += i + 2*len(tag)
+				}
+				sb.WriteString(sql[i:end])
+				i = end - 1
+			} else {
+				sb.WriteByte(c)
+			}
+		case c == ';':
+			flush()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// closingQuote returns the index just past the string starting at sql[start],
+// treating a doubled quote as an escaped one
+func closingQuote(sql string, start int) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// dollarTag returns the PostgreSQL dollar-quote tag ($$ or $name$) at the
+// start of s, or "" if there is none
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+// splitTopLevel splits s at commas outside parentheses and quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '\'', '"', '`':
+			i = closingQuote(s, i) - 1
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// s[open], or -1
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '\'', '"', '`':
+			i = closingQuote(s, i) - 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,463 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// objectKinds are the CREATE/DROP object types tracked by name
+var objectKinds = []string{
+	"TABLE", "INDEX", "VIEW", "TYPE", "FUNCTION", "PROCEDURE", "TRIGGER",
+	"SEQUENCE", "EXTENSION", "SCHEMA", "DOMAIN", "POLICY", "RULE",
+}
+
+// dataStatements start statements that change rows rather than the schema
+var dataStatements = []string{"INSERT", "UPDATE", "DELETE", "MERGE", "COPY", "TRUNCATE", "SELECT", "REPLACE", "WITH"}
+
+// sessionStatements start statements with no lasting effect on the schema
+var sessionStatements = []string{"BEGIN", "COMMIT", "ROLLBACK", "START", "END", "SET", "USE", "PRAGMA"}
+
+var onKeyword = regexp.MustCompile(`(?i)\sON\s`)
+
+// object is one schema object in the squashed view
+type object struct {
+	kind  string
+	table string // key of the table an index belongs to
+	stmt  string // the statement, for everything but parsed tables
+
+	// Parsed tables are rebuilt from head, columns, and tail, so later ALTER
+	// TABLE statements can be folded into the column list
+	head    string   // "CREATE TABLE name"
+	columns []string // column and constraint definitions
+	tail    string   // table options after the column list
+	alters  []string // ALTER TABLE actions that could not be folded
+}
+
+type state struct {
+	objects map[string]*object
+	order   []string
+	next    int // counter for keys of unnamed statements
+	omitted int // data statements dropped
+}
+
+// Squash applies the up sections of migrations in version order and
+// returns the resulting schema as SQL: one CREATE statement per surviving
+// object with later ALTER TABLE changes folded in where possible, dropped
+// objects removed, and data statements omitted.
+func Squash(migrations []Migration) string {
+	migrations = append([]Migration(nil), migrations...)
+	sort.Slice(migrations, func(i, j int) bool { return Less(migrations[i], migrations[j]) })
+
+	s := &state{objects: make(map[string]*object)}
+	for _, m := range migrations {
+		for _, stmt := range splitStatements(UpSection(m.Content)) {
+			s.apply(stmt)
+		}
+	}
+
+	var sb strings.Builder
+	if len(migrations) > 0 {
+		fmt.Fprintf(&sb, "-- Current schema synthesized from %d %s migrations (%s … %s).\n",
+			len(migrations), migrations[0].Tool, path.Base(migrations[0].Path), path.Base(migrations[len(migrations)-1].Path))
+		sb.WriteString("-- Down migrations are skipped")
+		switch {
+		case s.omitted == 1:
+			sb.WriteString(" and 1 data statement was omitted")
+		case s.omitted > 1:
+			fmt.Fprintf(&sb, " and %d data statements were omitted", s.omitted)
+		}
+		sb.WriteString(".\n")
+	}
+	s.write(&sb)
+	return sb.String()
+}
+
+func (s *state) apply(stmt string) {
+	first := strings.ToUpper(firstWord(stmt))
+	switch {
+	case first == "CREATE":
+		s.create(stmt)
+	case first == "DROP":
+		s.drop(stmt)
+	case first == "ALTER" && hasPrefixFold(strings.TrimSpace(stmt[5:]), "TABLE"):
+		s.alterTable(stmt)
+	case slices.Contains(dataStatements, first):
+		s.omitted++
+	case slices.Contains(sessionStatements, first):
+	default:
+		s.add(s.unnamedKey(), &object{stmt: stmt})
+	}
+}
+
+func (s *state) create(stmt string) {
+	rest, _ := consume(stmt, "CREATE")
+	rest, _ = consume(rest, "OR", "REPLACE")
+
+	kind := ""
+	for i := 0; i < 4 && kind == ""; i++ {
+		word := strings.ToUpper(firstWord(rest))
+		if slices.Contains(objectKinds, word) {
+			kind = word
+		}
+		rest, _ = consume(rest, firstWord(rest))
+	}
+	if kind == "" {
+		s.add(s.unnamedKey(), &object{stmt: stmt})
+		return
+	}
+
+	rest, _ = consume(rest, "CONCURRENTLY")
+	rest, ifNotExists := consume(rest, "IF", "NOT", "EXISTS")
+	name, after := identifier(rest)
+	if name == "" || strings.EqualFold(name, "ON") {
+		s.add(s.unnamedKey(), &object{kind: kind, stmt: stmt})
+		return
+	}
+
+	key := objectKey(kind, name)
+	if ifNotExists && s.objects[key] != nil {
+		return
+	}
+	obj := &object{kind: kind, stmt: stmt}
+
+	switch kind {
+	case "TABLE":
+		after = strings.TrimLeft(after, " \t\r\n")
+		if strings.HasPrefix(after, "(") {
+			if end := matchingParen(after, 0); end > 0 {
+				obj.head = "CREATE TABLE " + name
+				obj.columns = splitTopLevel(after[1:end])
+				obj.tail = strings.TrimSpace(after[end+1:])
+			}
+		}
+	case "INDEX":
+		if loc := onKeyword.FindStringIndex(after); loc != nil {
+			on, _ := consume(after[loc[1]:], "ONLY")
+			if table, _ := identifier(on); table != "" {
+				obj.table = objectKey("TABLE", table)
+			}
+		}
+	}
+
+	if existing := s.objects[key]; existing != nil && kind != "TABLE" {
+		*existing = *obj // OR REPLACE keeps the original position
+		return
+	}
+	s.remove(key)
+	s.add(key, obj)
+}
+
+func (s *state) drop(stmt string) {
+	rest, _ := consume(stmt, "DROP")
+	rest, _ = consume(rest, "MATERIALIZED")
+	kind := strings.ToUpper(firstWord(rest))
+	if !slices.Contains(objectKinds, kind) {
+		s.add(s.unnamedKey(), &object{stmt: stmt})
+		return
+	}
+	rest, _ = consume(rest, kind)
+	rest, _ = consume(rest, "CONCURRENTLY")
+	rest, _ = consume(rest, "IF", "EXISTS")
+
+	for _, part := range splitTopLevel(rest) {
+		name, _ := identifier(part)
+		if name == "" {
+			continue
+		}
+		key := objectKey(kind, name)
+		s.remove(key)
+		if kind == "TABLE" {
+			for k, obj := range s.objects {
+				if obj.table == key {
+					s.remove(k)
+				}
+			}
+		}
+	}
+}
+
+func (s *state) alterTable(stmt string) {
+	rest, _ := consume(stmt, "ALTER", "TABLE")
+	rest, _ = consume(rest, "IF", "EXISTS")
+	rest, _ = consume(rest, "ONLY")
+	name, rest := identifier(rest)
+	key := objectKey("TABLE", name)
+
+	table := s.objects[key]
+	if table == nil || table.columns == nil {
+		s.add(s.unnamedKey(), &object{stmt: stmt})
+		return
+	}
+
+	for _, action := range splitTopLevel(rest) {
+		if newName, ok := consume(action, "RENAME", "TO"); ok {
+			newName, _ = identifier(newName)
+			newKey := objectKey("TABLE", newName)
+			table.head = "CREATE TABLE " + newName
+			for _, obj := range s.objects {
+				if obj.table == key {
+					obj.table = newKey
+				}
+			}
+			s.rekey(key, newKey)
+			key = newKey
+			continue
+		}
+		if !table.fold(action) {
+			table.alters = append(table.alters, action)
+		}
+	}
+}
+
+// fold applies one ALTER TABLE action to the column list, reporting false
+// if it is not one that can be folded
+func (t *object) fold(action string) bool {
+	if def, ok := consume(action, "ADD"); ok {
+		def, _ = consume(def, "COLUMN")
+		def, _ = consume(def, "IF", "NOT", "EXISTS")
+		t.columns = append(t.columns, strings.TrimSpace(def))
+		return true
+	}
+	if rest, ok := consume(action, "DROP", "CONSTRAINT"); ok {
+		rest, _ = consume(rest, "IF", "EXISTS")
+		name, _ := identifier(rest)
+		return t.removeColumn(func(col string) bool {
+			c, ok := consume(col, "CONSTRAINT")
+			n, _ := identifier(c)
+			return ok && normalize(n) == normalize(name)
+		})
+	}
+	if rest, ok := consume(action, "DROP"); ok {
+		rest, _ = consume(rest, "COLUMN")
+		rest, _ = consume(rest, "IF", "EXISTS")
+		name, _ := identifier(rest)
+		return t.removeColumn(func(col string) bool { return columnName(col) == normalize(name) })
+	}
+	if rest, ok := consume(action, "RENAME"); ok {
+		rest, _ = consume(rest, "COLUMN")
+		from, rest := identifier(rest)
+		rest, ok := consume(rest, "TO")
+		to, _ := identifier(rest)
+		if !ok || to == "" {
+			return false
+		}
+		for i, col := range t.columns {
+			if columnName(col) == normalize(from) {
+				_, def := identifier(col)
+				t.columns[i] = to + def
+				return true
+			}
+		}
+		return false
+	}
+	// MySQL: MODIFY [COLUMN] name definition, CHANGE [COLUMN] old new definition
+	if rest, ok := consume(action, "MODIFY"); ok {
+		rest, _ = consume(rest, "COLUMN")
+		return t.replaceColumn(columnName(rest), strings.TrimSpace(rest))
+	}
+	if rest, ok := consume(action, "CHANGE"); ok {
+		rest, _ = consume(rest, "COLUMN")
+		old, def := identifier(rest)
+		return t.replaceColumn(normalize(old), strings.TrimSpace(def))
+	}
+	return false
+}
+
+func (t *object) removeColumn(match func(string) bool) bool {
+	for i, col := range t.columns {
+		if match(col) {
+			t.columns = append(t.columns[:i], t.columns[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *object) replaceColumn(name, def string) bool {
+	for i, col := range t.columns {
+		if columnName(col) == name {
+			t.columns[i] = def
+			return true
+		}
+	}
+	return false
+}
+
+func (s *state) add(key string, obj *object) {
+	s.objects[key] = obj
+	s.order = append(s.order, key)
+}
+
+func (s *state) remove(key string) {
+	if s.objects[key] == nil {
+		return
+	}
+	delete(s.objects, key)
+	s.order = slices.DeleteFunc(s.order, func(k string) bool { return k == key })
+}
+
+func (s *state) rekey(from, to string) {
+	s.objects[to] = s.objects[from]
+	delete(s.objects, from)
+	for i, key := range s.order {
+		if key == from {
+			s.order[i] = to
+		}
+	}
+}
+
+func (s *state) unnamedKey() string {
+	s.next++
+	return fmt.Sprintf("#%d", s.next)
+}
+
+func (s *state) write(sb *strings.Builder) {
+	written := make(map[string]bool)
+	for _, key := range s.order {
+		obj := s.objects[key]
+		if written[key] {
+			continue
+		}
+		// Indexes are written with their table
+		if obj.table != "" && s.objects[obj.table] != nil {
+			continue
+		}
+		written[key] = true
+		sb.WriteString("\n")
+		obj.write(sb)
+
+		if obj.kind != "TABLE" {
+			continue
+		}
+		for _, indexKey := range s.order {
+			index := s.objects[indexKey]
+			if index != nil && index.table == key && !written[indexKey] {
+				written[indexKey] = true
+				index.write(sb)
+			}
+		}
+	}
+}
+
+func (o *object) write(sb *strings.Builder) {
+	if o.columns == nil {
+		sb.WriteString(compact(o.stmt))
+		sb.WriteString(";\n")
+		return
+	}
+
+	sb.WriteString(o.head)
+	sb.WriteString(" (\n")
+	for i, col := range o.columns {
+		sb.WriteString("    ")
+		sb.WriteString(strings.Join(strings.Fields(col), " "))
+		if i < len(o.columns)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(")")
+	if o.tail != "" {
+		sb.WriteString(" ")
+		sb.WriteString(o.tail)
+	}
+	sb.WriteString(";\n")
+	for _, action := range o.alters {
+		fmt.Fprintf(sb, "ALTER TABLE %s %s;\n", strings.TrimPrefix(o.head, "CREATE TABLE "), compact(action))
+	}
+}
+
+// compact drops blank lines and trailing whitespace left by removed comments
+func compact(stmt string) string {
+	var lines []string
+	for _, line := range strings.Split(stmt, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func objectKey(kind, name string) string {
+	return kind + " " + normalize(name)
+}
+
+// normalize makes identifiers comparable: unquoted and lower-case, with any
+// argument list (functions) removed
+func normalize(name string) string {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(name)
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// columnName returns the normalized name a column definition starts with
+func columnName(def string) string {
+	name, _ := identifier(def)
+	return normalize(name)
+}
+
+// identifier reads a possibly quoted, possibly schema-qualified name at the
+// start of s and returns it with the remaining text
+func identifier(s string) (string, string) {
+	s = strings.TrimLeft(s, " \t\r\n")
+	i := 0
+	for i < len(s) {
+		switch s[i] {
+		case '"', '`':
+			i = closingQuote(s, i)
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return s, ""
+			}
+			i += end + 1
+		default:
+			for i < len(s) && isIdentByte(s[i]) {
+				i++
+			}
+		}
+		if i < len(s) && s[i] == '.' {
+			i++
+			continue
+		}
+		break
+	}
+	return s[:i], s[i:]
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// consume strips the keywords, matched case-insensitively as whole words,
+// from the start of s. If they don't all match, s is returned unchanged.
+func consume(s string, keywords ...string) (string, bool) {
+	rest := s
+	for _, keyword := range keywords {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		if !hasPrefixFold(rest, keyword) || len(rest) > len(keyword) && isIdentByte(rest[len(keyword)]) {
+			return s, false
+		}
+		rest = rest[len(keyword):]
+	}
+	return rest, true
+}
+
+func firstWord(s string) string {
+	s = strings.TrimLeft(s, " \t\r\n")
+	i := 0
+	for i < len(s) && isIdentByte(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
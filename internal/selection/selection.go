@@ -0,0 +1,142 @@
+// Package selection reads the selection files editor plugins write to tell
+// bcopy exactly which files, and which lines of them, to copy
+package selection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// Version is the selection format version this build understands
+const Version = 1
+
+// File is one selected file, given as a file:// URI or as a path
+// (absolute, or relative to the selection root)
+type File struct {
+	URI  string `json:"uri,omitempty"`
+	Path string `json:"path,omitempty"`
+	// Ranges limits the file to these lines; empty selects the whole file
+	Ranges []collector.LineRange `json:"ranges,omitempty"`
+}
+
+// Selection is the document an editor sends or writes:
+//
+//	{"version": 1, "root": "/home/me/project",
+//	 "files": [{"uri": "file:///home/me/project/main.go", "ranges": [{"start": 10, "end": 42}]},
+//	           {"path": "go.mod"}]}
+type Selection struct {
+	Version int    `json:"version"`
+	Root    string `json:"root,omitempty"`
+	Files   []File `json:"files"`
+}
+
+// windowsURIPath matches the path of a file URI with a drive letter
+// (file:///C:/src), whose leading slash must be dropped
+var windowsURIPath = regexp.MustCompile(`^/[A-Za-z]:/`)
+
+// Parse decodes a selection document
+func Parse(r io.Reader) (*Selection, error) {
+	var sel Selection
+	if err := json.NewDecoder(r).Decode(&sel); err != nil {
+		return nil, fmt.Errorf("invalid selection: %w", err)
+	}
+	if err := sel.Validate(); err != nil {
+		return nil, err
+	}
+	return &sel, nil
+}
+
+// Validate checks the version and that there is something to select
+func (s *Selection) Validate() error {
+	if s.Version > Version {
+		return fmt.Errorf("selection version %d is newer than this bcopy supports (%d); upgrade bcopy", s.Version, Version)
+	}
+	if len(s.Files) == 0 {
+		return fmt.Errorf("selection has no files")
+	}
+	return nil
+}
+
+// Load reads a selection file
+func Load(path string) (*Selection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Resolve returns the absolute root of the selection (its own root, or
+// defaultRoot) and the selected files relative to it. Files outside the
+// root are an error. A file listed more than once has its ranges merged,
+// and listing it without ranges selects all of it.
+func (s *Selection) Resolve(defaultRoot string) (string, []collector.Selected, error) {
+	root := s.Root
+	if root == "" {
+		root = defaultRoot
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var selected []collector.Selected
+	index := make(map[string]int)
+	for _, f := range s.Files {
+		path, err := f.path()
+		if err != nil {
+			return "", nil, err
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", nil, fmt.Errorf("%s is outside the selection root %s", path, root)
+		}
+		rel = filepath.ToSlash(rel)
+
+		if i, ok := index[rel]; ok {
+			if len(f.Ranges) == 0 || len(selected[i].Ranges) == 0 {
+				selected[i].Ranges = nil
+			} else {
+				selected[i].Ranges = append(selected[i].Ranges, f.Ranges...)
+			}
+			continue
+		}
+		index[rel] = len(selected)
+		selected = append(selected, collector.Selected{RelPath: rel, Ranges: f.Ranges})
+	}
+	return root, selected, nil
+}
+
+func (f File) path() (string, error) {
+	if f.URI == "" {
+		if f.Path == "" {
+			return "", fmt.Errorf("selection entry has neither uri nor path")
+		}
+		return filepath.FromSlash(f.Path), nil
+	}
+
+	u, err := url.Parse(f.URI)
+	if err != nil {
+		return "", fmt.Errorf("invalid uri %q: %w", f.URI, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported uri %q: only file:// is supported", f.URI)
+	}
+	p := u.Path
+	if windowsURIPath.MatchString(p) {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p), nil
+}
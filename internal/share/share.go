@@ -0,0 +1,172 @@
+// Package share serves a payload once over the local network, so it can be
+// opened on another device without clipboard sync
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// ErrExpired is returned by Serve when nobody fetched the payload in time
+var ErrExpired = errors.New("share expired before the payload was fetched")
+
+// Server serves one payload at an unguessable URL until it is fetched once
+type Server struct {
+	listener net.Listener
+	token    string
+	payload  []byte
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Listen prepares a share of payload on addr. An empty host in addr picks
+// the first private IPv4 address of the machine.
+func Listen(addr string, payload []byte) (*Server, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		if host, err = LANAddress(); err != nil {
+			return nil, err
+		}
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		listener: listener,
+		token:    hex.EncodeToString(token),
+		payload:  payload,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// URL is the address to open on the other device
+func (s *Server) URL() string {
+	return fmt.Sprintf("http://%s/%s", s.listener.Addr(), s.token)
+}
+
+// Serve answers requests until the payload has been fetched once, ctx is
+// canceled, or timeout passes (ErrExpired)
+func (s *Server) Serve(ctx context.Context, timeout time.Duration) error {
+	server := &http.Server{Handler: http.HandlerFunc(s.handle), ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Serve(s.listener) }()
+
+	var result error
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		result = ctx.Err()
+	case <-time.After(timeout):
+		result = ErrExpired
+	case err := <-errCh:
+		return err
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+	return result
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/")
+	if r.Method != http.MethodGet || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		slog.Debug("share: rejected request", "remote", r.RemoteAddr, "path", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	served := false
+	s.once.Do(func() {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(s.payload)
+		served = true
+		slog.Info("share: payload fetched", "remote", r.RemoteAddr)
+		close(s.done)
+	})
+	if !served {
+		http.Error(w, "this share has already been used", http.StatusGone)
+	}
+}
+
+// LANAddress returns the first private IPv4 address of an interface that
+// is up, which other devices on the network can usually reach
+func LANAddress() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && ipNet.IP.To4() != nil && ipNet.IP.IsPrivate() {
+				return ipNet.IP.String(), nil
+			}
+		}
+	}
+	return "", errors.New("no local network address found; pass --addr with this machine's IP")
+}
+
+// QR renders text as a QR code for a terminal, two modules per character
+// cell using half blocks, light on dark
+func QR(text string) (string, error) {
+	code, err := qrcode.New(text, qrcode.Low)
+	if err != nil {
+		return "", err
+	}
+	bitmap := code.Bitmap()
+
+	light := func(y, x int) bool {
+		return y >= len(bitmap) || !bitmap[y][x]
+	}
+
+	var sb strings.Builder
+	for y := 0; y < len(bitmap); y += 2 {
+		for x := range bitmap[y] {
+			top, bottom := light(y, x), light(y+1, x)
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,141 @@
+// Package slots stores payloads under names in the local cache, so several
+// prepared contexts can be kept and copied back later
+package slots
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nodelike/bcopy/internal/history"
+)
+
+// ErrNotFound is returned for a slot that has never been saved
+var ErrNotFound = errors.New("slot not found")
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Slot describes a stored payload
+type Slot struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Dir is where slots are stored
+func Dir() (string, error) {
+	dir, err := history.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "slots"), nil
+}
+
+// ValidateName rejects names that are not usable as file names
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid slot name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+func path(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".md"), nil
+}
+
+// Save stores the payload written by write under name, replacing any
+// previous one. The slot is replaced atomically, so a failed write keeps
+// the old payload.
+func Save(name string, write func(io.Writer) error) (string, error) {
+	target, err := path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return target, os.Rename(tmp.Name(), target)
+}
+
+// Load returns the payload stored under name
+func Load(name string) (string, error) {
+	p, err := path(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return string(data), err
+}
+
+// Delete removes the slot name
+func Delete(name string) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return err
+}
+
+// List returns the stored slots, most recently saved first
+func List() ([]Slot, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Slot
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, Slot{Name: name, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ModTime.After(list[j].ModTime) })
+	return list, nil
+}
@@ -0,0 +1,282 @@
+// Package snap renders source code as a syntax-highlighted PNG, for sharing
+// snippets in chat tools that mangle long text
+package snap
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Layout, in pixels at scale 1; everything is drawn at 2x for sharp
+// results on high-density screens
+const (
+	scale       = 2
+	fontSize    = 13
+	lineHeight  = 20
+	padding     = 24 // around the window
+	inset       = 20 // inside the window
+	titleBar    = 32
+	gutterGap   = 16
+	tabWidth    = 4
+	maxColumns  = 120
+	defaultRows = 200
+)
+
+// Options controls what is rendered
+type Options struct {
+	// Title is shown in the window bar, and picks the lexer by file name
+	Title string
+	// Lexer overrides the language detection (e.g. "plaintext")
+	Lexer string
+	// Style is a chroma style name (default "monokai")
+	Style string
+	// MaxLines truncates longer content (default 200)
+	MaxLines int
+	// LineNumbers draws a gutter with line numbers
+	LineNumbers bool
+}
+
+type run struct {
+	text string
+	col  color.Color
+	bold bool
+}
+
+// Render draws content as a window with a title bar and returns a PNG
+func Render(content string, opts Options) ([]byte, error) {
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = defaultRows
+	}
+	style := styles.Get(opts.Style)
+	if opts.Style == "" {
+		style = styles.Get("monokai")
+	}
+
+	lines, err := highlight(content, opts.Title, opts.Lexer, style)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) > opts.MaxLines {
+		more := len(lines) - opts.MaxLines
+		lines = append(lines[:opts.MaxLines], []run{{text: fmt.Sprintf("… %d more lines", more), col: dim(style)}})
+	}
+
+	regular, err := newFace(gomono.TTF)
+	if err != nil {
+		return nil, err
+	}
+	bold, err := newFace(gomonobold.TTF)
+	if err != nil {
+		return nil, err
+	}
+	advance, _ := regular.GlyphAdvance('M')
+	charWidth := advance.Ceil()
+
+	columns := 0
+	for _, line := range lines {
+		width := 0
+		for _, r := range line {
+			width += utf8.RuneCountInString(r.text)
+		}
+		columns = max(columns, width)
+	}
+	columns = max(columns, 20)
+
+	gutter := 0
+	if opts.LineNumbers {
+		gutter = len(fmt.Sprint(len(lines)))*charWidth + gutterGap*scale
+	}
+
+	windowWidth := 2*inset*scale + gutter + columns*charWidth
+	windowHeight := titleBar*scale + 2*inset*scale + len(lines)*lineHeight*scale
+	width := windowWidth + 2*padding*scale
+	height := windowHeight + 2*padding*scale
+
+	bg := background(style)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(shade(bg, 0.6)), image.Point{}, draw.Src)
+
+	window := image.Rect(padding*scale, padding*scale, padding*scale+windowWidth, padding*scale+windowHeight)
+	fillRounded(img, window, 8*scale, bg)
+
+	// Window controls and title
+	for i, c := range []color.RGBA{{0xff, 0x5f, 0x56, 0xff}, {0xff, 0xbd, 0x2e, 0xff}, {0x27, 0xc9, 0x3f, 0xff}} {
+		fillCircle(img, window.Min.X+(inset+6+i*20)*scale, window.Min.Y+titleBar*scale/2, 6*scale, c)
+	}
+	if opts.Title != "" {
+		// Centered, but never over the window controls
+		titleX := window.Min.X + max((windowWidth-utf8.RuneCountInString(opts.Title)*charWidth)/2, (inset+80)*scale)
+		drawText(img, regular, opts.Title, titleX, window.Min.Y+titleBar*scale/2+5*scale, dim(style))
+	}
+
+	baseline := window.Min.Y + titleBar*scale + inset*scale + 14*scale
+	textX := window.Min.X + inset*scale + gutter
+	for i, line := range lines {
+		y := baseline + i*lineHeight*scale
+		if opts.LineNumbers && i < opts.MaxLines {
+			number := fmt.Sprintf("%*d", len(fmt.Sprint(len(lines))), i+1)
+			drawText(img, regular, number, window.Min.X+inset*scale, y, dim(style))
+		}
+		x := textX
+		for _, r := range line {
+			face := regular
+			if r.bold {
+				face = bold
+			}
+			drawText(img, face, r.text, x, y, r.col)
+			x += utf8.RuneCountInString(r.text) * charWidth
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// highlight tokenizes content and returns its lines as colored runs, with
+// tabs expanded and long lines cut at maxColumns
+func highlight(content, name, lexerName string, style *chroma.Style) ([][]run, error) {
+	var lexer chroma.Lexer
+	if lexerName != "" {
+		lexer = lexers.Get(lexerName)
+	} else {
+		lexer = lexers.Match(name)
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(content)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, strings.TrimRight(content, "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]run
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		var line []run
+		columns := 0
+		for _, token := range tokens {
+			text := strings.TrimRight(token.Value, "\r\n")
+			text = expandTabs(text, columns)
+			if n := utf8.RuneCountInString(text); columns+n > maxColumns {
+				text = string([]rune(text)[:max(maxColumns-columns, 0)]) + "…"
+			}
+			if text == "" {
+				continue
+			}
+			entry := style.Get(token.Type)
+			line = append(line, run{text: text, col: foreground(entry.Colour, style), bold: entry.Bold == chroma.Yes})
+			columns += utf8.RuneCountInString(text)
+			if columns > maxColumns {
+				break
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func expandTabs(s string, column int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		if r == '\t' {
+			n := tabWidth - column%tabWidth
+			sb.WriteString(strings.Repeat(" ", n))
+			column += n
+			continue
+		}
+		sb.WriteRune(r)
+		column++
+	}
+	return sb.String()
+}
+
+func newFace(ttf []byte) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: fontSize * scale, DPI: 72, Hinting: font.HintingFull})
+}
+
+func drawText(img draw.Image, face font.Face, text string, x, y int, c color.Color) {
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
+
+// foreground converts a token color, falling back to the style's text color
+func foreground(c chroma.Colour, style *chroma.Style) color.Color {
+	if !c.IsSet() {
+		c = style.Get(chroma.Text).Colour
+	}
+	if !c.IsSet() {
+		return color.RGBA{0xf8, 0xf8, 0xf2, 0xff}
+	}
+	return color.RGBA{c.Red(), c.Green(), c.Blue(), 0xff}
+}
+
+func background(style *chroma.Style) color.RGBA {
+	c := style.Get(chroma.Background).Background
+	if !c.IsSet() {
+		return color.RGBA{0x27, 0x28, 0x22, 0xff}
+	}
+	return color.RGBA{c.Red(), c.Green(), c.Blue(), 0xff}
+}
+
+func dim(style *chroma.Style) color.Color {
+	if c := style.Get(chroma.Comment).Colour; c.IsSet() {
+		return color.RGBA{c.Red(), c.Green(), c.Blue(), 0xff}
+	}
+	return color.RGBA{0x75, 0x71, 0x5e, 0xff}
+}
+
+func shade(c color.RGBA, f float64) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f), 0xff}
+}
+
+// fillRounded fills r with rounded corners of the given radius
+func fillRounded(img *image.RGBA, r image.Rectangle, radius int, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cx := min(max(x, r.Min.X+radius), r.Max.X-radius-1)
+			cy := min(max(y, r.Min.Y+radius), r.Max.Y-radius-1)
+			if dx, dy := x-cx, y-cy; dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+func fillCircle(img *image.RGBA, cx, cy, radius int, c color.RGBA) {
+	for y := cy - radius; y <= cy+radius; y++ {
+		for x := cx - radius; x <= cx+radius; x++ {
+			if dx, dy := x-cx, y-cy; dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
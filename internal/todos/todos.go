@@ -0,0 +1,53 @@
+// Package todos finds TODO, FIXME, HACK, and XXX comments in source files.
+package todos
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Item is one marked comment. Path is slash-separated and relative to the
+// project root. Author is filled in by callers that can blame the file.
+type Item struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Tag    string `json:"tag"`
+	Text   string `json:"text"`
+	Author string `json:"author,omitempty"`
+}
+
+// marker matches a tag right after a comment leader (//, #, /*, *, --, ;,
+// <!--, %), so identifiers like todoList or strings mentioning TODO mid-line
+// are not reported. A trailing block comment closer is dropped.
+var marker = regexp.MustCompile(`(?://+|#+|/\*+|^\s*\*|--|;+|<!--|%)\s*(TODO|FIXME|HACK|XXX)\b:?\s*(.*?)\s*(?:\*/|-->)?\s*$`)
+
+// Scan returns the marked comments of one file in line order
+func Scan(path, content string) []Item {
+	var items []Item
+	for i, line := range strings.Split(content, "\n") {
+		m := marker.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		items = append(items, Item{Path: path, Line: i + 1, Tag: m[1], Text: m[2]})
+	}
+	return items
+}
+
+// Markdown renders items as a payload section
+func Markdown(items []Item) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n---\n\n## TODOs (%d)\n\n", len(items))
+	for _, item := range items {
+		fmt.Fprintf(&sb, "- ./%s:%d %s", item.Path, item.Line, item.Tag)
+		if item.Author != "" {
+			fmt.Fprintf(&sb, " (%s)", item.Author)
+		}
+		if item.Text != "" {
+			fmt.Fprintf(&sb, ": %s", item.Text)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
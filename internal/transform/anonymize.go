@@ -0,0 +1,82 @@
+package transform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+var (
+	emailPattern    = regexp.MustCompile(`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+\.)+[A-Za-z]{2,}`)
+	internalPattern = regexp.MustCompile(`\b([A-Za-z0-9-]+\.)+(internal|corp|local|lan|intranet)\b`)
+)
+
+// AnonymizeOptions configures Anonymize
+type AnonymizeOptions struct {
+	// Replacements maps literal strings (company names, domains) to their
+	// stand-ins and is applied before the built-in rewrites
+	Replacements map[string]string
+}
+
+// ParseReplacements parses "old=new" pairs as accepted by
+// --anonymize-replace and the anonymize-replace config key
+func ParseReplacements(pairs []string) map[string]string {
+	replacements := make(map[string]string)
+	for _, pair := range pairs {
+		if old, replacement, ok := strings.Cut(pair, "="); ok && old != "" {
+			replacements[old] = replacement
+		}
+	}
+	return replacements
+}
+
+// Anonymize returns a transform that rewrites emails, internal hostnames,
+// and configured strings. Replacements are derived from a hash of the
+// original so the same value maps to the same stand-in across files.
+func Anonymize(opts AnonymizeOptions) collector.Transform {
+	// Replace longer keys first so "mail.acme.com" wins over "acme.com"
+	keys := make([]string, 0, len(opts.Replacements))
+	for key := range opts.Replacements {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	pairs := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		pairs = append(pairs, key, opts.Replacements[key])
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	return func(file *collector.FileData) {
+		content := replacer.Replace(file.Content)
+		content = emailPattern.ReplaceAllStringFunc(content, func(email string) string {
+			return "user-" + shortHash(email) + "@example.com"
+		})
+		content = internalPattern.ReplaceAllStringFunc(content, func(host string) string {
+			return "host-" + shortHash(host) + ".example.internal"
+		})
+		file.Content = content
+	}
+}
+
+// HashDirs replaces every directory component of relPath with a short
+// hash, keeping the file name so fence languages still apply. It is meant
+// for rewriting paths at output time: files keep their real paths during
+// collection, so history and deltas still match across runs.
+func HashDirs(relPath string) string {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		parts[i] = "d_" + shortHash(parts[i])
+	}
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:8]
+}
@@ -0,0 +1,161 @@
+package transform
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// APISurface returns a transform that reduces Go files to their exported
+// API: exported constants, variables, types, functions, and methods of
+// exported types, with their doc comments and without function bodies or
+// imports, much like godoc. Struct fields that are not exported are
+// dropped and noted. Other files, tests, and files that don't parse are
+// left alone.
+func APISurface() collector.Transform {
+	return func(file *collector.FileData) {
+		if filepath.Ext(file.RelPath) != ".go" || strings.HasSuffix(file.RelPath, "_test.go") {
+			return
+		}
+		if surface, ok := goAPISurface(file.RelPath, file.Content); ok {
+			file.Content = surface
+		}
+	}
+}
+
+// goAPISurface renders the exported API of one Go source file
+func goAPISurface(name, src string) (string, bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return "", false
+	}
+
+	ast.FileExports(f)
+
+	decls := f.Decls[:0]
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+		case *ast.FuncDecl:
+			if d.Recv != nil && !exportedReceiver(d.Recv) {
+				continue
+			}
+			d.Body = nil
+		}
+		decls = append(decls, decl)
+	}
+	f.Decls = decls
+
+	// Keep only the comments of what survived: the package doc and the doc
+	// and line comments of kept declarations, specs, and fields
+	kept := attachedComments(f)
+	var comments []*ast.CommentGroup
+	for _, c := range f.Comments {
+		if c == f.Doc || kept[c] {
+			comments = append(comments, c)
+		}
+	}
+	f.Comments = append(comments, filteredNotes(f)...)
+	sort.Slice(f.Comments, func(i, j int) bool {
+		return f.Comments[i].Pos() < f.Comments[j].Pos()
+	})
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// exportedReceiver reports whether a method's receiver type is exported
+func exportedReceiver(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	typ := recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// filteredNotes replaces the Incomplete flag of trimmed structs and
+// interfaces with a comment before the closing brace. go/printer only
+// writes its own note for incomplete types when a file has no comments.
+func filteredNotes(f *ast.File) []*ast.CommentGroup {
+	var notes []*ast.CommentGroup
+	note := func(closing token.Pos) {
+		notes = append(notes, &ast.CommentGroup{List: []*ast.Comment{
+			{Slash: closing - 1, Text: "// contains filtered or unexported fields"},
+		}})
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.StructType:
+			if t.Incomplete {
+				t.Incomplete = false
+				note(t.Fields.Closing)
+			}
+		case *ast.InterfaceType:
+			if t.Incomplete {
+				t.Incomplete = false
+				note(t.Methods.Closing)
+			}
+		}
+		return true
+	})
+	return notes
+}
+
+// attachedComments returns the doc and line comments the declarations of
+// f still refer to. Comments of removed nodes, and free-floating ones
+// (such as those in function bodies), are not among them.
+func attachedComments(f *ast.File) map[*ast.CommentGroup]bool {
+	kept := make(map[*ast.CommentGroup]bool)
+	keep := func(groups ...*ast.CommentGroup) {
+		for _, c := range groups {
+			if c != nil {
+				kept[c] = true
+			}
+		}
+	}
+	for _, decl := range f.Decls {
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GenDecl:
+				keep(n.Doc)
+			case *ast.FuncDecl:
+				keep(n.Doc)
+			case *ast.ValueSpec:
+				keep(n.Doc, n.Comment)
+			case *ast.TypeSpec:
+				keep(n.Doc, n.Comment)
+			case *ast.Field:
+				keep(n.Doc, n.Comment)
+			}
+			return true
+		})
+	}
+	return kept
+}
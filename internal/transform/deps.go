@@ -0,0 +1,43 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/manifest"
+)
+
+// DepsSummary returns a transform that replaces dependency manifests
+// (go.mod, package.json, requirements.txt, pyproject.toml, Cargo.toml)
+// with the runtime they require and their direct dependencies, one per
+// line. Manifests that don't parse, or whose summary would be no shorter,
+// are left alone.
+func DepsSummary() collector.Transform {
+	return func(file *collector.FileData) {
+		if !manifest.IsManifest(file.RelPath) {
+			return
+		}
+		m := manifest.Parse(file.RelPath, []byte(file.Content))
+		if m == nil {
+			return
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "# Direct dependencies of %s, summarized by bcopy --deps-summary\n", m.File)
+		if m.RuntimeVersion != "" {
+			fmt.Fprintf(&sb, "%s %s\n", m.Runtime, m.RuntimeVersion)
+		}
+		if len(m.Dependencies) > 0 {
+			sb.WriteString("\n")
+		}
+		for _, dep := range m.Dependencies {
+			sb.WriteString(dep.String() + "\n")
+		}
+
+		if sb.Len() < len(file.Content) {
+			file.Content = sb.String()
+			file.Language = "text"
+		}
+	}
+}
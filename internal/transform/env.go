@@ -0,0 +1,23 @@
+package transform
+
+import (
+	"regexp"
+
+	"github.com/nodelike/bcopy/internal/analyzer"
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// envAssignment matches KEY=value lines, optionally prefixed with export
+var envAssignment = regexp.MustCompile(`(?m)^(\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_.-]*\s*=\s*)(\S.*)$`)
+
+// MaskEnv returns a transform that replaces every value in dotenv files
+// with *** so keys stay visible but secrets don't leave the machine.
+// Templates such as .env.example are left alone.
+func MaskEnv() collector.Transform {
+	return func(file *collector.FileData) {
+		if !analyzer.IsEnvFile(file.RelPath) || analyzer.IsEnvTemplate(file.RelPath) {
+			return
+		}
+		file.Content = envAssignment.ReplaceAllString(file.Content, "${1}***")
+	}
+}
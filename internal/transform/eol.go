@@ -0,0 +1,50 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// eolSequences maps line ending names to the sequences they stand for
+var eolSequences = map[string]string{
+	"lf":   "\n",
+	"crlf": "\r\n",
+	"cr":   "\r",
+}
+
+// NormalizeEOL returns a transform that rewrites every line ending (CRLF,
+// LF, or lone CR) to the one named by mode: "lf" or "crlf". Mode
+// "editorconfig" rewrites each file to its own EndOfLine, which is the
+// end_of_line set in .editorconfig where there is one, and leaves files
+// with mixed line endings and no setting alone. Mode "keep" returns a nil
+// transform.
+func NormalizeEOL(mode string) (collector.Transform, error) {
+	toLF := strings.NewReplacer("\r\n", "\n", "\r", "\n")
+	rewrite := func(file *collector.FileData, eol string) {
+		content := toLF.Replace(file.Content)
+		if eol != "\n" {
+			content = strings.ReplaceAll(content, "\n", eol)
+		}
+		file.Content = content
+	}
+
+	switch mode {
+	case "keep", "":
+		return nil, nil
+	case "lf", "crlf":
+		eol := eolSequences[mode]
+		return func(file *collector.FileData) {
+			rewrite(file, eol)
+		}, nil
+	case "editorconfig":
+		return func(file *collector.FileData) {
+			if eol, ok := eolSequences[file.EndOfLine]; ok {
+				rewrite(file, eol)
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported line ending %q (use lf, crlf, editorconfig, or keep)", mode)
+	}
+}
@@ -0,0 +1,46 @@
+package transform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+	"github.com/nodelike/bcopy/internal/manifest"
+)
+
+// LockSummary returns a transform that replaces lock files (package-lock.json,
+// go.sum, Cargo.lock) with the exact versions of the direct dependencies
+// they lock, one per line. The manifest next to a lock file is read from
+// under root where the lock file doesn't say which dependencies are direct.
+// Lock files that don't parse are left alone.
+func LockSummary(root string) collector.Transform {
+	return func(file *collector.FileData) {
+		if !manifest.IsLockfile(file.RelPath) {
+			return
+		}
+		manifestPath := filepath.Join(root, filepath.Dir(file.RelPath), manifest.LockManifest(file.RelPath))
+		manifestData, _ := os.ReadFile(manifestPath)
+		l := manifest.ParseLock(file.RelPath, []byte(file.Content), manifestData)
+		if l == nil {
+			return
+		}
+
+		var sb strings.Builder
+		if l.Complete {
+			fmt.Fprintf(&sb, "# Direct dependency versions locked in %s (%d packages in total), summarized by bcopy --locks summary\n", l.File, l.Total)
+		} else {
+			fmt.Fprintf(&sb, "# Versions locked in %s, summarized by bcopy --locks summary\n", l.File)
+		}
+		if len(l.Dependencies) > 0 {
+			sb.WriteString("\n")
+		}
+		for _, dep := range l.Dependencies {
+			sb.WriteString(dep.String() + "\n")
+		}
+
+		file.Content = sb.String()
+		file.Language = "text"
+	}
+}
@@ -0,0 +1,65 @@
+package transform
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/nodelike/bcopy/internal/collector"
+)
+
+// RetabOptions configures Retab
+type RetabOptions struct {
+	// Width is the number of spaces per indentation level (0 = off)
+	Width int
+	// UseTabs converts leading spaces to tabs instead of tabs to spaces
+	UseTabs bool
+	// ByExt overrides Width for files with these extensions (".go": 0
+	// leaves Go files alone)
+	ByExt map[string]int
+}
+
+// Retab returns a transform that normalizes leading indentation. Tabs
+// advance to the next multiple of the width, so mixed indentation keeps
+// its visual alignment.
+func Retab(opts RetabOptions) collector.Transform {
+	return func(file *collector.FileData) {
+		width := opts.Width
+		if w, ok := opts.ByExt[strings.ToLower(filepath.Ext(file.RelPath))]; ok {
+			width = w
+		}
+		if width <= 0 {
+			return
+		}
+
+		lines := strings.SplitAfter(file.Content, "\n")
+		for i, line := range lines {
+			lines[i] = retabLine(line, width, opts.UseTabs)
+		}
+		file.Content = strings.Join(lines, "")
+	}
+}
+
+// retabLine rewrites the leading whitespace of line
+func retabLine(line string, width int, useTabs bool) string {
+	column, n := 0, 0
+	for ; n < len(line); n++ {
+		if line[n] == ' ' {
+			column++
+		} else if line[n] == '\t' {
+			column += width - column%width
+		} else {
+			break
+		}
+	}
+	if n == 0 {
+		return line
+	}
+
+	var indent string
+	if useTabs {
+		indent = strings.Repeat("\t", column/width) + strings.Repeat(" ", column%width)
+	} else {
+		indent = strings.Repeat(" ", column)
+	}
+	return indent + line[n:]
+}
@@ -0,0 +1,401 @@
+package ui
+
+// message is a catalog entry: how it is decorated and its English text,
+// which is also the fallback for languages that lack a translation
+type message struct {
+	kind  Kind
+	emoji string
+	text  string
+}
+
+// messages holds every status line bcopy prints, keyed by ID. Entries
+// without an emoji are plain text, used through T.
+var messages = map[string]message{
+	"collect.start": {Info, "📦", "Collecting files..."},
+	"collect.done":  {Done, "", "(%d files)"},
+	"done":          {Done, "", "Done"},
+	"found":         {Highlight, "✨", "Found %d files (%.2f MB)"},
+	"found.skipped": {Highlight, "✨", "Found %d files (%.2f MB), %d skipped due to errors"},
+	"none.found":    {Failure, "❌", "No files found matching the criteria"},
+	"none.changed":  {Done, "✓", "No files changed since the last run"},
+	"run.start":     {Info, "▶️ ", "Running %s..."},
+	"run.done":      {Done, "", "(exit status %d)"},
+
+	"error":          {Failure, "❌", "Error: %v"},
+	"error.history":  {Failure, "❌", "Error reading history: %v"},
+	"error.hard-max": {Failure, "❌", "Error: %s (%s) exceeds hard maximum (%s)"},
+	"hint.hard-max":  {Info, "", "This is a safety limit to prevent clipboard overflow.\nUse --hard-max to increase or --output to write to a file instead."},
+	"abort.on-error": {Failure, "❌", "Aborting: --on-error fail is set"},
+	"abort.pii":      {Failure, "❌", "Aborting: --fail-on-pii is set"},
+	"abort.license":  {Failure, "❌", "Aborting: --fail-on-license matched %s"},
+
+	"warn.not-git":       {Warning, "⚠️ ", "Warning: %s is not in a git repository"},
+	"warn.large":         {Warning, "⚠️ ", "Warning: %s contains more than %d files. This may take a while."},
+	"warn.large.home":    {Warning, "⚠️ ", "Warning: %s (a top-level directory in your home folder) contains more than %d files. This may take a while."},
+	"warn.mounts":        {Warning, "⚠️ ", "Skipped %d mount points on other filesystems (--one-file-system=false to include them)"},
+	"warn.quotas":        {Warning, "⚠️ ", "Left out %d files (%s) over their quotas"},
+	"warn.minified":      {Warning, "⚠️ ", "Left out %d minified files (%s); --minified truncate keeps their start"},
+	"warn.minified.one":  {Warning, "⚠️ ", "Left out %d minified file (%s); --minified truncate keeps its start"},
+	"warn.ext-limits":    {Warning, "⚠️ ", "Cut or left out %d files (%s) over their ext-limits"},
+	"warn.invalid-names": {Warning, "⚠️ ", "Warning: %d file names are not valid UTF-8; their headers show U+FFFD in place of the invalid bytes"},
+	"warn.threshold":     {Warning, "⚠️ ", "Warning: %s (%s) exceeds threshold (%s)"},
+	"warn.entry":         {Warning, "⚠️ ", "Warning: Entry point %s was not found or could not be read"},
+	"warn.permission":    {Warning, "⚠️ ", "%d paths skipped due to permission errors"},
+	"warn.read-errors":   {Warning, "⚠️ ", "%d paths skipped due to read errors (changed or removed during the run?)"},
+	"warn.pii":           {Warning, "⚠️ ", "Warning: %d possible personal data matches found"},
+	"warn.audit":         {Warning, "⚠️ ", "Warning: Could not write the audit log: %v"},
+	"warn.license":       {Warning, "⚠️ ", "Warning: %d selected files are under their own or third-party licenses"},
+	"warn.no-history":    {Warning, "⚠️ ", "Warning: No previous run recorded here, emitting all files"},
+	"warn.verify":        {Warning, "⚠️ ", "Warning: The clipboard holds %s instead of the %s copied; a clipboard manager may have truncated or changed it"},
+	"warn.verify-read":   {Warning, "⚠️ ", "Warning: Could not read the clipboard back to verify it: %v"},
+	"verified":           {Done, "✓", "Clipboard content verified (SHA-256 matches)"},
+
+	"label.total":     {Info, "", "Total size"},
+	"label.estimated": {Info, "", "Estimated size"},
+	"label.payload":   {Info, "", "Payload size"},
+
+	"suggest.header":   {Info, "💡", "Consider excluding (in .gitignore or an --ignore-file):"},
+	"suggest.none":     {Done, "✓", "No exclusions to suggest"},
+	"suggest.files":    {Info, "", "%s across %d files"},
+	"suggest.skipped":  {Info, "", "skipped as binary or too large"},
+	"suggest.included": {Info, "", "included"},
+
+	"prompt.continue":      {Warning, "", "Continue anyway?"},
+	"prompt.continue-copy": {Warning, "", "Continue copying to clipboard?"},
+	"aborted.large":        {Info, "", "Aborted. Narrow the selection or raise --warn-files."},
+	"canceled":             {Info, "", "Canceled by user"},
+
+	"module":     {Info, "🧩", "Module %s (%s, ./%s)"},
+	"package":    {Info, "🧩", "Package %s (%s, ./%s)"},
+	"dependency": {Info, "🔗", "Dependency ./%s"},
+	"delta":      {Info, "🔁", "Delta since %s: %d changed, %d removed"},
+
+	"clipboard.start":  {Info, "📋", "Copying %s to clipboard..."},
+	"copied":           {Success, "✅", "Successfully copied to clipboard!"},
+	"copied.via":       {Success, "✅", "Successfully copied to clipboard (%s)!"},
+	"copy.timeout":     {Info, "", "timed out after %s (raise --clipboard-timeout or write a file with --output)"},
+	"slot.copying":     {Info, "📋", "Copying slot %s (%s) to clipboard..."},
+	"slot.start":       {Info, "🗃 ", "Saving to slot %s..."},
+	"slot.saved":       {Success, "✅", "Saved to slot %s (bcopy load %s to copy it)"},
+	"export.start":     {Info, "📂", "Exporting files..."},
+	"export.done":      {Success, "✅", "Successfully exported %d files to %s!"},
+	"perdir.start":     {Info, "📂", "Writing per-directory payloads..."},
+	"perdir.done":      {Success, "✅", "Successfully wrote %d payloads to %s!"},
+	"zip.start":        {Info, "🗂 ", "Writing zip archive..."},
+	"file.start":       {Info, "📝", "Writing to file..."},
+	"compressed.start": {Info, "📝", "Writing %s-compressed file..."},
+	"compressed.ratio": {Highlight, "🗜 ", "%.2f MB → %.2f MB"},
+	"written":          {Success, "✅", "Successfully written to %s!"},
+	"unchanged":        {Done, "✓", "%s is up to date; nothing would change"},
+	"docs.written":     {Success, "✅", "Documentation written to %s"},
+
+	"paste.conflicts": {Warning, "⚠️ ", "%d files already exist with different content"},
+	"paste.wrote":     {Success, "✅", "Wrote %d files from %s to %s"},
+	"serve.listening": {Info, "🔌", "Editor server listening on %s"},
+	"serve.grpc":      {Info, "🔌", "gRPC server listening on %s"},
+	"share.sharing":   {Info, "📡", "Sharing %s (%s) once at:"},
+	"share.fetched":   {Success, "✅", "Payload fetched; share closed"},
+	"snap.written":    {Success, "✅", "Image written to %s (%s)"},
+	"snap.copied":     {Success, "✅", "Image copied to clipboard (%s)"},
+
+	"upgrade.current":     {Done, "✓", "bcopy %s is up to date"},
+	"upgrade.available":   {Highlight, "✨", "bcopy %s is available (installed: %s)"},
+	"upgrade.downloading": {Info, "⬇️ ", "Downloading %s..."},
+	"upgrade.done":        {Success, "✅", "Upgraded %s to %s!"},
+	"auth.stored":         {Done, "✓", "Stored %s token in the OS keychain"},
+	"auth.removed":        {Done, "✓", "Removed %s token"},
+}
+
+// catalogs holds the translations by language code. English is the text in
+// messages; a missing translation falls back to it.
+var catalogs = map[string]map[string]string{
+	"en": nil,
+	"ja": ja,
+	"zh": zh,
+	"es": es,
+}
+
+var ja = map[string]string{
+	"collect.start": "ファイルを収集しています...",
+	"collect.done":  "(%d 件)",
+	"done":          "完了",
+	"found":         "%d 件のファイルが見つかりました (%.2f MB)",
+	"found.skipped": "%d 件のファイルが見つかりました (%.2f MB)、エラーにより %d 件をスキップ",
+	"none.found":    "条件に一致するファイルはありません",
+	"none.changed":  "前回の実行以降に変更されたファイルはありません",
+	"run.start":     "%s を実行しています...",
+	"run.done":      "(終了ステータス %d)",
+
+	"error":          "エラー: %v",
+	"error.history":  "履歴の読み込みエラー: %v",
+	"error.hard-max": "エラー: %s (%s) が上限 (%s) を超えています",
+	"hint.hard-max":  "これはクリップボードのあふれを防ぐための安全上の上限です。\n--hard-max で上限を上げるか、--output でファイルに書き出してください。",
+	"abort.on-error": "中止します: --on-error fail が指定されています",
+	"abort.pii":      "中止します: --fail-on-pii が指定されています",
+	"abort.license":  "中止します: --fail-on-license に一致しました: %s",
+
+	"warn.not-git":       "警告: %s は git リポジトリ内にありません",
+	"warn.large":         "警告: %s には %d 件を超えるファイルがあります。時間がかかる場合があります。",
+	"warn.large.home":    "警告: %s (ホームフォルダ直下のディレクトリ) には %d 件を超えるファイルがあります。時間がかかる場合があります。",
+	"warn.mounts":        "他のファイルシステムのマウントポイント %d 件をスキップしました (含めるには --one-file-system=false)",
+	"warn.quotas":        "クォータを超えた %d 件のファイル (%s) を除外しました",
+	"warn.minified":      "圧縮 (minify) されたファイル %d 件 (%s) を除外しました。--minified truncate で先頭を残せます",
+	"warn.minified.one":  "圧縮 (minify) されたファイル %d 件 (%s) を除外しました。--minified truncate で先頭を残せます",
+	"warn.ext-limits":    "ext-limits を超えた %d 件のファイル (%s) を切り詰めるか除外しました",
+	"warn.invalid-names": "警告: %d 件のファイル名が有効な UTF-8 ではありません。ヘッダーでは無効なバイトが U+FFFD で表示されます",
+	"warn.threshold":     "警告: %s (%s) がしきい値 (%s) を超えています",
+	"warn.entry":         "警告: エントリポイント %s が見つからないか読み込めません",
+	"warn.permission":    "権限エラーにより %d 件のパスをスキップしました",
+	"warn.read-errors":   "読み込みエラーにより %d 件のパスをスキップしました (実行中に変更または削除された可能性があります)",
+	"warn.pii":           "警告: 個人情報の可能性がある箇所が %d 件見つかりました",
+	"warn.audit":         "警告: 監査ログに書き込めませんでした: %v",
+	"warn.license":       "警告: 選択したファイルのうち %d 件が独自またはサードパーティのライセンスです",
+	"warn.no-history":    "警告: ここでの前回の実行記録がないため、すべてのファイルを出力します",
+	"warn.verify":        "警告: クリップボードの内容はコピーした %[2]s ではなく %[1]s です。クリップボードマネージャーが切り詰めたか変更した可能性があります",
+	"warn.verify-read":   "警告: 検証のためにクリップボードを読み戻せませんでした: %v",
+	"verified":           "クリップボードの内容を検証しました (SHA-256 一致)",
+
+	"label.total":     "合計サイズ",
+	"label.estimated": "推定サイズ",
+	"label.payload":   "ペイロードサイズ",
+
+	"suggest.header":   "除外を検討してください (.gitignore または --ignore-file):",
+	"suggest.none":     "除外の提案はありません",
+	"suggest.files":    "%s (%d ファイル)",
+	"suggest.skipped":  "バイナリまたはサイズ超過のためスキップ",
+	"suggest.included": "含まれています",
+
+	"prompt.continue":      "続行しますか?",
+	"prompt.continue-copy": "クリップボードへのコピーを続行しますか?",
+	"aborted.large":        "中止しました。選択範囲を絞るか --warn-files を上げてください。",
+	"canceled":             "ユーザーによりキャンセルされました",
+
+	"module":     "モジュール %s (%s, ./%s)",
+	"package":    "パッケージ %s (%s, ./%s)",
+	"dependency": "依存先 ./%s",
+	"delta":      "%s 以降の差分: 変更 %d 件、削除 %d 件",
+
+	"clipboard.start":  "%s をクリップボードにコピーしています...",
+	"copied":           "クリップボードにコピーしました!",
+	"copied.via":       "クリップボードにコピーしました (%s)!",
+	"copy.timeout":     "%s 後にタイムアウトしました (--clipboard-timeout を上げるか --output でファイルに書き出してください)",
+	"slot.copying":     "スロット %s (%s) をクリップボードにコピーしています...",
+	"slot.start":       "スロット %s に保存しています...",
+	"slot.saved":       "スロット %s に保存しました (bcopy load %s でコピーできます)",
+	"export.start":     "ファイルを書き出しています...",
+	"export.done":      "%d 件のファイルを %s に書き出しました!",
+	"perdir.start":     "ディレクトリごとのペイロードを書き込んでいます...",
+	"perdir.done":      "%d 件のペイロードを %s に書き込みました!",
+	"zip.start":        "zip アーカイブを書き込んでいます...",
+	"file.start":       "ファイルに書き込んでいます...",
+	"compressed.start": "%s で圧縮したファイルを書き込んでいます...",
+	"written":          "%s に書き込みました!",
+	"unchanged":        "%s は最新です。変更はありません",
+	"docs.written":     "ドキュメントを %s に書き込みました",
+
+	"paste.conflicts": "%d 件のファイルが異なる内容で既に存在します",
+	"paste.wrote":     "%[2]s から %[3]s に %[1]d 件のファイルを書き込みました",
+	"serve.listening": "エディタサーバーが %s で待ち受けています",
+	"serve.grpc":      "gRPC サーバーが %s で待ち受けています",
+	"share.sharing":   "%s (%s) を一度だけ共有しています:",
+	"share.fetched":   "ペイロードが取得されました。共有を終了します",
+	"snap.written":    "画像を %s に書き込みました (%s)",
+	"snap.copied":     "画像をクリップボードにコピーしました (%s)",
+
+	"upgrade.current":     "bcopy %s は最新です",
+	"upgrade.available":   "bcopy %s が利用可能です (インストール済み: %s)",
+	"upgrade.downloading": "%s をダウンロードしています...",
+	"upgrade.done":        "%s を %s にアップグレードしました!",
+	"auth.stored":         "%s のトークンを OS のキーチェーンに保存しました",
+	"auth.removed":        "%s のトークンを削除しました",
+}
+
+var zh = map[string]string{
+	"collect.start": "正在收集文件...",
+	"collect.done":  "(%d 个文件)",
+	"done":          "完成",
+	"found":         "找到 %d 个文件 (%.2f MB)",
+	"found.skipped": "找到 %d 个文件 (%.2f MB)，%d 个因错误被跳过",
+	"none.found":    "没有符合条件的文件",
+	"none.changed":  "自上次运行以来没有文件变化",
+	"run.start":     "正在运行 %s...",
+	"run.done":      "(退出状态 %d)",
+
+	"error":          "错误: %v",
+	"error.history":  "读取历史记录出错: %v",
+	"error.hard-max": "错误: %s (%s) 超过硬上限 (%s)",
+	"hint.hard-max":  "这是防止剪贴板溢出的安全限制。\n使用 --hard-max 提高上限，或使用 --output 写入文件。",
+	"abort.on-error": "中止: 已设置 --on-error fail",
+	"abort.pii":      "中止: 已设置 --fail-on-pii",
+	"abort.license":  "中止: --fail-on-license 匹配到 %s",
+
+	"warn.not-git":       "警告: %s 不在 git 仓库中",
+	"warn.large":         "警告: %s 包含超过 %d 个文件，可能需要一些时间。",
+	"warn.large.home":    "警告: %s (主目录下的顶层目录) 包含超过 %d 个文件，可能需要一些时间。",
+	"warn.mounts":        "已跳过其他文件系统上的 %d 个挂载点 (使用 --one-file-system=false 包含它们)",
+	"warn.quotas":        "已排除超出配额的 %d 个文件 (%s)",
+	"warn.minified":      "已排除 %d 个压缩 (minified) 文件 (%s)；--minified truncate 可保留其开头",
+	"warn.minified.one":  "已排除 %d 个压缩 (minified) 文件 (%s)；--minified truncate 可保留其开头",
+	"warn.ext-limits":    "已截断或排除超出 ext-limits 的 %d 个文件 (%s)",
+	"warn.invalid-names": "警告: %d 个文件名不是有效的 UTF-8；其标题中的无效字节显示为 U+FFFD",
+	"warn.threshold":     "警告: %s (%s) 超过阈值 (%s)",
+	"warn.entry":         "警告: 入口点 %s 不存在或无法读取",
+	"warn.permission":    "%d 个路径因权限错误被跳过",
+	"warn.read-errors":   "%d 个路径因读取错误被跳过 (运行期间被修改或删除?)",
+	"warn.pii":           "警告: 发现 %d 处可能的个人数据",
+	"warn.audit":         "警告: 无法写入审计日志: %v",
+	"warn.license":       "警告: 所选文件中有 %d 个使用自身或第三方许可证",
+	"warn.no-history":    "警告: 此处没有上次运行的记录，将输出所有文件",
+	"warn.verify":        "警告: 剪贴板中的内容为 %s，而不是复制的 %s；剪贴板管理器可能截断或更改了它",
+	"warn.verify-read":   "警告: 无法读回剪贴板进行验证: %v",
+	"verified":           "剪贴板内容已验证 (SHA-256 一致)",
+
+	"label.total":     "总大小",
+	"label.estimated": "估计大小",
+	"label.payload":   "载荷大小",
+
+	"suggest.header":   "建议排除 (在 .gitignore 或 --ignore-file 中):",
+	"suggest.none":     "没有排除建议",
+	"suggest.files":    "%s，共 %d 个文件",
+	"suggest.skipped":  "因二进制或过大而跳过",
+	"suggest.included": "已包含",
+
+	"prompt.continue":      "仍要继续吗?",
+	"prompt.continue-copy": "继续复制到剪贴板吗?",
+	"aborted.large":        "已中止。请缩小选择范围或提高 --warn-files。",
+	"canceled":             "用户已取消",
+
+	"module":     "模块 %s (%s, ./%s)",
+	"package":    "包 %s (%s, ./%s)",
+	"dependency": "依赖 ./%s",
+	"delta":      "自 %s 以来的差异: %d 个已修改，%d 个已删除",
+
+	"clipboard.start":  "正在将 %s 复制到剪贴板...",
+	"copied":           "已成功复制到剪贴板!",
+	"copied.via":       "已成功复制到剪贴板 (%s)!",
+	"copy.timeout":     "%s 后超时 (提高 --clipboard-timeout 或使用 --output 写入文件)",
+	"slot.copying":     "正在将槽位 %s (%s) 复制到剪贴板...",
+	"slot.start":       "正在保存到槽位 %s...",
+	"slot.saved":       "已保存到槽位 %s (使用 bcopy load %s 复制)",
+	"export.start":     "正在导出文件...",
+	"export.done":      "已成功将 %d 个文件导出到 %s!",
+	"perdir.start":     "正在按目录写入负载...",
+	"perdir.done":      "已成功将 %d 个负载写入 %s!",
+	"zip.start":        "正在写入 zip 归档...",
+	"file.start":       "正在写入文件...",
+	"compressed.start": "正在写入 %s 压缩文件...",
+	"written":          "已成功写入 %s!",
+	"unchanged":        "%s 已是最新，不会有任何变化",
+	"docs.written":     "文档已写入 %s",
+
+	"paste.conflicts": "%d 个文件已存在且内容不同",
+	"paste.wrote":     "已将 %[1]d 个文件从 %[2]s 写入 %[3]s",
+	"serve.listening": "编辑器服务器正在监听 %s",
+	"serve.grpc":      "gRPC 服务器正在监听 %s",
+	"share.sharing":   "正在一次性共享 %s (%s)，地址:",
+	"share.fetched":   "负载已被获取；共享已关闭",
+	"snap.written":    "图片已写入 %s (%s)",
+	"snap.copied":     "图片已复制到剪贴板 (%s)",
+
+	"upgrade.current":     "bcopy %s 已是最新版本",
+	"upgrade.available":   "bcopy %s 可用 (已安装: %s)",
+	"upgrade.downloading": "正在下载 %s...",
+	"upgrade.done":        "已将 %s 升级到 %s!",
+	"auth.stored":         "已将 %s 令牌保存到系统钥匙串",
+	"auth.removed":        "已删除 %s 令牌",
+}
+
+var es = map[string]string{
+	"collect.start": "Recopilando archivos...",
+	"collect.done":  "(%d archivos)",
+	"done":          "Hecho",
+	"found":         "Se encontraron %d archivos (%.2f MB)",
+	"found.skipped": "Se encontraron %d archivos (%.2f MB), %d omitidos por errores",
+	"none.found":    "No hay archivos que cumplan los criterios",
+	"none.changed":  "Ningún archivo cambió desde la última ejecución",
+	"run.start":     "Ejecutando %s...",
+	"run.done":      "(código de salida %d)",
+
+	"error":          "Error: %v",
+	"error.history":  "Error al leer el historial: %v",
+	"error.hard-max": "Error: %s (%s) supera el máximo absoluto (%s)",
+	"hint.hard-max":  "Es un límite de seguridad para no desbordar el portapapeles.\nUse --hard-max para aumentarlo o --output para escribir en un archivo.",
+	"abort.on-error": "Abortando: --on-error fail está activo",
+	"abort.pii":      "Abortando: --fail-on-pii está activo",
+	"abort.license":  "Abortando: --fail-on-license coincidió con %s",
+
+	"warn.not-git":       "Advertencia: %s no está en un repositorio git",
+	"warn.large":         "Advertencia: %s contiene más de %d archivos. Esto puede tardar.",
+	"warn.large.home":    "Advertencia: %s (un directorio de primer nivel de su carpeta personal) contiene más de %d archivos. Esto puede tardar.",
+	"warn.mounts":        "Se omitieron %d puntos de montaje de otros sistemas de archivos (--one-file-system=false para incluirlos)",
+	"warn.quotas":        "Se omitieron %d archivos (%s) que excedían sus cuotas",
+	"warn.minified":      "Se omitieron %d archivos minificados (%s); --minified truncate conserva su inicio",
+	"warn.minified.one":  "Se omitió %d archivo minificado (%s); --minified truncate conserva su inicio",
+	"warn.ext-limits":    "Se recortaron u omitieron %d archivos (%s) que excedían sus ext-limits",
+	"warn.invalid-names": "Advertencia: %d nombres de archivo no son UTF-8 válido; sus encabezados muestran U+FFFD en lugar de los bytes inválidos",
+	"warn.threshold":     "Advertencia: %s (%s) supera el umbral (%s)",
+	"warn.entry":         "Advertencia: el punto de entrada %s no existe o no se pudo leer",
+	"warn.permission":    "%d rutas omitidas por errores de permisos",
+	"warn.read-errors":   "%d rutas omitidas por errores de lectura (¿cambiaron o se eliminaron durante la ejecución?)",
+	"warn.pii":           "Advertencia: se encontraron %d posibles datos personales",
+	"warn.audit":         "Advertencia: no se pudo escribir el registro de auditoría: %v",
+	"warn.license":       "Advertencia: %d archivos seleccionados tienen licencia propia o de terceros",
+	"warn.no-history":    "Advertencia: no hay ejecuciones previas registradas aquí, se emiten todos los archivos",
+	"warn.verify":        "Advertencia: el portapapeles contiene %s en lugar de los %s copiados; un gestor de portapapeles pudo truncarlo o cambiarlo",
+	"warn.verify-read":   "Advertencia: no se pudo leer el portapapeles para verificarlo: %v",
+	"verified":           "Contenido del portapapeles verificado (SHA-256 coincide)",
+
+	"label.total":     "Tamaño total",
+	"label.estimated": "Tamaño estimado",
+	"label.payload":   "Tamaño del contenido",
+
+	"suggest.header":   "Considere excluir (en .gitignore o un --ignore-file):",
+	"suggest.none":     "No hay exclusiones que sugerir",
+	"suggest.files":    "%s en %d archivos",
+	"suggest.skipped":  "omitido por ser binario o demasiado grande",
+	"suggest.included": "incluido",
+
+	"prompt.continue":      "¿Continuar de todos modos?",
+	"prompt.continue-copy": "¿Continuar copiando al portapapeles?",
+	"aborted.large":        "Abortado. Reduzca la selección o aumente --warn-files.",
+	"canceled":             "Cancelado por el usuario",
+
+	"module":     "Módulo %s (%s, ./%s)",
+	"package":    "Paquete %s (%s, ./%s)",
+	"dependency": "Dependencia ./%s",
+	"delta":      "Cambios desde %s: %d modificados, %d eliminados",
+
+	"clipboard.start":  "Copiando %s al portapapeles...",
+	"copied":           "¡Copiado al portapapeles!",
+	"copied.via":       "¡Copiado al portapapeles (%s)!",
+	"copy.timeout":     "tiempo agotado tras %s (aumente --clipboard-timeout o escriba un archivo con --output)",
+	"slot.copying":     "Copiando la ranura %s (%s) al portapapeles...",
+	"slot.start":       "Guardando en la ranura %s...",
+	"slot.saved":       "Guardado en la ranura %s (bcopy load %s para copiarlo)",
+	"export.start":     "Exportando archivos...",
+	"export.done":      "¡Se exportaron %d archivos a %s!",
+	"perdir.start":     "Escribiendo un archivo por directorio...",
+	"perdir.done":      "¡Se escribieron %d archivos en %s!",
+	"zip.start":        "Escribiendo archivo zip...",
+	"file.start":       "Escribiendo en el archivo...",
+	"compressed.start": "Escribiendo archivo comprimido con %s...",
+	"written":          "¡Escrito en %s!",
+	"unchanged":        "%s está al día; no cambiaría nada",
+	"docs.written":     "Documentación escrita en %s",
+
+	"paste.conflicts": "%d archivos ya existen con contenido distinto",
+	"paste.wrote":     "Se escribieron %d archivos de %s en %s",
+	"serve.listening": "Servidor del editor escuchando en %s",
+	"serve.grpc":      "Servidor gRPC escuchando en %s",
+	"share.sharing":   "Compartiendo %s (%s) una sola vez en:",
+	"share.fetched":   "Contenido descargado; se cerró el recurso compartido",
+	"snap.written":    "Imagen escrita en %s (%s)",
+	"snap.copied":     "Imagen copiada al portapapeles (%s)",
+
+	"upgrade.current":     "bcopy %s está actualizado",
+	"upgrade.available":   "bcopy %s está disponible (instalado: %s)",
+	"upgrade.downloading": "Descargando %s...",
+	"upgrade.done":        "¡%s actualizado a %s!",
+	"auth.stored":         "Token de %s guardado en el llavero del sistema",
+	"auth.removed":        "Token de %s eliminado",
+}
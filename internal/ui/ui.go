@@ -0,0 +1,228 @@
+// Package ui prints the status lines bcopy shows on stderr. Messages come
+// from a catalog keyed by ID, so they can be translated, and are decorated
+// with color and an emoji, with an ASCII tag in plain mode, or with the tag
+// alone and no ANSI codes in accessible mode.
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Kind classifies a message; it picks the color and the plain-mode tag
+type Kind int
+
+const (
+	Info      Kind = iota // a step or fact (cyan)
+	Highlight             // a summary worth noticing (magenta)
+	Success               // a finished action (bold green)
+	Done                  // a small confirmation (green)
+	Warning               // something skipped or suspicious (yellow)
+	Failure               // an error or abort (red)
+)
+
+var (
+	colors = map[Kind]string{
+		Info:      "\033[36m",
+		Highlight: "\033[35m",
+		Success:   "\033[1m\033[32m",
+		Done:      "\033[32m",
+		Warning:   "\033[33m",
+		Failure:   "\033[31m",
+	}
+	tags = map[Kind]string{
+		Info:      "[INFO]",
+		Highlight: "[INFO]",
+		Success:   "[OK]",
+		Done:      "[OK]",
+		Warning:   "[WARN]",
+		Failure:   "[ERROR]",
+	}
+)
+
+const reset = "\033[0m"
+
+// Output is where status lines are written
+var Output io.Writer = os.Stderr
+
+var (
+	lang       = "en"
+	plain      bool
+	accessible bool
+)
+
+// Setup selects the message language and whether emoji are replaced with
+// ASCII tags. Accessible mode also drops colors and gives every step its
+// own line, which screen readers announce cleanly. An empty lang is taken
+// from LC_ALL, LC_MESSAGES, or LANG, falling back to English.
+func Setup(language string, plainMessages, accessibleMode bool) error {
+	plain = plainMessages || accessibleMode
+	accessible = accessibleMode
+
+	if language == "" {
+		lang = localeLanguage()
+		return nil
+	}
+	language = strings.ToLower(language)
+	if _, ok := catalogs[language]; !ok {
+		return fmt.Errorf("unsupported language %q (use %s)", language, strings.Join(Languages(), ", "))
+	}
+	lang = language
+	return nil
+}
+
+// Languages returns the codes of the available catalogs
+func Languages() []string {
+	codes := make([]string, 0, len(catalogs))
+	for code := range catalogs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// localeLanguage maps a POSIX locale such as ja_JP.UTF-8 to a catalog code
+func localeLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		code, _, _ := strings.Cut(strings.ToLower(locale), "_")
+		code, _, _ = strings.Cut(code, ".")
+		if _, ok := catalogs[code]; ok {
+			return code
+		}
+		return "en"
+	}
+	return "en"
+}
+
+// N returns the ID of the singular form of message id (id + ".one") when
+// n is 1 and the catalog has one, and id otherwise
+func N(id string, n int) string {
+	if _, ok := messages[id+".one"]; ok && n == 1 {
+		return id + ".one"
+	}
+	return id
+}
+
+// T returns message id in the current language, formatted with args
+func T(id string, args ...any) string {
+	format, ok := catalogs[lang][id]
+	if !ok {
+		format = messages[id].text
+	}
+	if format == "" {
+		format = id
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Accessible reports whether accessible mode is on, for output that
+// animates or is only distinguished by color
+func Accessible() bool {
+	return accessible
+}
+
+// Paint wraps text in the color of kind, or returns it unchanged in
+// accessible mode
+func Paint(kind Kind, text string) string {
+	if accessible {
+		return text
+	}
+	return colors[kind] + text + reset
+}
+
+// decorate colors text and leads it with emoji, or with the kind's tag in
+// plain and accessible modes
+func decorate(kind Kind, emoji, text string) string {
+	lead := emoji
+	if plain {
+		lead = tags[kind]
+	}
+	return Paint(kind, lead+" "+text)
+}
+
+// Status prints message id on its own line
+func Status(id string, args ...any) {
+	m := messages[id]
+	fmt.Fprintln(Output, decorate(m.kind, m.emoji, T(id, args...)))
+}
+
+// Begin prints message id as the start of a step, leaving the line open
+// for Complete. In accessible mode the step gets a line of its own.
+func Begin(id string, args ...any) {
+	m := messages[id]
+	line := decorate(m.kind, m.emoji, T(id, args...))
+	if accessible {
+		fmt.Fprintln(Output, line)
+		return
+	}
+	fmt.Fprint(Output, line+" ")
+}
+
+// Complete ends a step started with Begin with a check mark, followed by
+// message id when it is not empty. In accessible mode it prints its own
+// line, saying "Done" when id is empty.
+func Complete(id string, args ...any) {
+	if accessible {
+		if id == "" {
+			id = "done"
+		}
+		fmt.Fprintln(Output, tags[Done]+" "+T(id, args...))
+		return
+	}
+
+	mark := "✓"
+	if plain {
+		mark = tags[Done]
+	}
+	line := Paint(Done, mark)
+	if id != "" {
+		line += " " + T(id, args...)
+	}
+	fmt.Fprintln(Output, line)
+}
+
+// Spin animates a spinner after a Begin line until stop is called, for
+// steps that can't report progress. Nothing is shown in accessible mode or
+// when Output is not a terminal.
+func Spin() (stop func()) {
+	f, ok := Output.(*os.File)
+	if accessible || !ok || !term.IsTerminal(int(f.Fd())) {
+		return func() {}
+	}
+
+	const frames = `|/-\`
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(f, "%c\b", frames[i%len(frames)])
+			select {
+			case <-quit:
+				fmt.Fprint(f, " \b")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-exited
+	}
+}
@@ -0,0 +1,109 @@
+package upgrade
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archiveOf returns a release .tar.gz holding binary as bcopy
+func archiveOf(t *testing.T, binary string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "bcopy", Mode: 0o755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(binary)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestApplyVerifiesChecksum(t *testing.T) {
+	archive := archiveOf(t, "new binary")
+	sum := sha256.Sum256(archive)
+	good := hex.EncodeToString(sum[:])
+	bad := strings.Repeat("0", len(good))
+
+	tests := []struct {
+		name      string
+		checksums string // "" publishes no checksums.txt
+		wantErr   string
+	}{
+		{"verified", good + "  " + ArchiveName() + "\n", ""},
+		{"mismatch", bad + "  " + ArchiveName() + "\n", "checksum mismatch"},
+		{"not listed", good + "  bcopy_Plan9_mips.tar.gz\n", "no checksum listed"},
+		{"no checksums", "", "refusing to install an unverified binary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+			mux.HandleFunc("/checksums", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(tt.checksums)) })
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			release := &Release{TagName: "v9.9.9", Assets: []Asset{{Name: ArchiveName(), URL: server.URL + "/archive"}}}
+			if tt.checksums != "" {
+				release.Assets = append(release.Assets, Asset{Name: checksumsAsset, URL: server.URL + "/checksums"})
+			}
+
+			exePath := filepath.Join(t.TempDir(), "bcopy")
+			if err := os.WriteFile(exePath, []byte("old binary"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			err := Apply(context.Background(), release, exePath)
+
+			want := "new binary"
+			if tt.wantErr != "" {
+				want = "old binary"
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Apply() error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := os.ReadFile(exePath); err != nil || string(got) != want {
+				t.Errorf("executable = %q, %v; want %q", got, err, want)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(exePath)); len(entries) != 1 {
+				t.Errorf("upgrade left files behind: %v", entries)
+			}
+		})
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.10.0", "1.9.3", true},
+		{"v1.0.1", "1.0.0", true},
+		{"1.0.0", "1.0.0", false},
+		{"1.0", "1.0.1", false},
+		{"2.0.0-rc1", "1.9.9", true},
+	}
+	for _, tt := range tests {
+		if got := IsNewer(tt.a, tt.b); got != tt.want {
+			t.Errorf("IsNewer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
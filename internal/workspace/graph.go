@@ -0,0 +1,298 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Graph is the internal dependency graph between the packages of a
+// project: workspace members, or the packages of a single Go module
+type Graph struct {
+	Packages []Member
+	// Deps maps a package directory to the directories it depends on
+	Deps map[string][]string
+}
+
+// BuildGraph detects the packages under root and their dependencies on
+// each other, from Go imports, package.json dependencies, and Cargo.toml
+// dependencies. Without a workspace, the packages of a root go.mod are used.
+func BuildGraph(root string) (*Graph, error) {
+	ws, err := Detect(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []Member
+	if ws != nil {
+		packages = ws.Members
+	} else if packages, err = goPackages(root); err != nil {
+		return nil, err
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	g := &Graph{Packages: packages, Deps: make(map[string][]string)}
+	for _, pkg := range packages {
+		var deps []string
+		switch pkg.Kind {
+		case Go:
+			deps, err = g.goDeps(root, pkg, ws != nil)
+		case Node:
+			deps, err = g.manifestDeps(root, pkg, "package.json", nodeDependencies)
+		case Cargo:
+			deps, err = g.manifestDeps(root, pkg, "Cargo.toml", cargoDependencies)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(deps)
+		g.Deps[pkg.Dir] = deps
+	}
+	return g, nil
+}
+
+// Find returns the packages matching name, like Workspace.Find
+func (g *Graph) Find(name string) []Member {
+	return (&Workspace{Members: g.Packages}).Find(name)
+}
+
+// Closure returns dirs plus every package directory they transitively
+// depend on, sorted
+func (g *Graph) Closure(dirs []string) []string {
+	seen := make(map[string]bool)
+	var visit func(dir string)
+	visit = func(dir string) {
+		if seen[dir] {
+			return
+		}
+		seen[dir] = true
+		for _, dep := range g.Deps[dir] {
+			visit(dep)
+		}
+	}
+	for _, dir := range dirs {
+		visit(dir)
+	}
+
+	closure := make([]string, 0, len(seen))
+	for dir := range seen {
+		closure = append(closure, dir)
+	}
+	sort.Strings(closure)
+	return closure
+}
+
+// WriteText writes one line per package followed by its dependencies
+func (g *Graph) WriteText(w io.Writer) {
+	names := g.names()
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(w, "%s (./%s)\n", pkg.Name, pkg.Dir)
+		for _, dep := range g.Deps[pkg.Dir] {
+			fmt.Fprintf(w, "  → %s\n", names[dep])
+		}
+	}
+}
+
+// WriteDOT writes the graph in Graphviz DOT format
+func (g *Graph) WriteDOT(w io.Writer) {
+	names := g.names()
+	fmt.Fprintln(w, "digraph packages {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(w, "  %s;\n", strconv.Quote(pkg.Name))
+	}
+	for _, pkg := range g.Packages {
+		for _, dep := range g.Deps[pkg.Dir] {
+			fmt.Fprintf(w, "  %s -> %s;\n", strconv.Quote(pkg.Name), strconv.Quote(names[dep]))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (g *Graph) names() map[string]string {
+	names := make(map[string]string, len(g.Packages))
+	for _, pkg := range g.Packages {
+		names[pkg.Dir] = pkg.Name
+	}
+	return names
+}
+
+// goPackages lists the directories holding Go files of the module rooted
+// at root, named by import path
+func goPackages(root string) ([]Member, error) {
+	modulePath := goModulePath(filepath.Join(root, "go.mod"))
+	if modulePath == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var packages []Member
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != root && (skipDirs[d.Name()] || d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".")) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil || seen[rel] {
+			return nil
+		}
+		seen[rel] = true
+
+		dir := filepath.ToSlash(rel)
+		name := modulePath
+		if dir != "." {
+			name = modulePath + "/" + dir
+		}
+		packages = append(packages, Member{Kind: Go, Name: name, Dir: dir})
+		return nil
+	})
+	return packages, err
+}
+
+// goDeps returns the packages imported by the Go files of pkg. A workspace
+// member spans its whole directory tree; a plain package only its own
+// directory.
+func (g *Graph) goDeps(root string, pkg Member, recursive bool) ([]string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(pkg.Dir))
+	imports := make(map[string]bool)
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != dir && (!recursive || skipDirs[d.Name()] || d.Name() == "testdata") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, spec := range file.Imports {
+			if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports[importPath] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []string
+	for _, other := range g.Packages {
+		if other.Dir == pkg.Dir || other.Kind != Go {
+			continue
+		}
+		for importPath := range imports {
+			// Workspace members own every package under their module path
+			if importPath == other.Name || (recursive && strings.HasPrefix(importPath, other.Name+"/")) {
+				deps = append(deps, other.Dir)
+				break
+			}
+		}
+	}
+	return deps, nil
+}
+
+// manifestDeps returns the packages named as dependencies in pkg's manifest
+func (g *Graph) manifestDeps(root string, pkg Member, manifest string, parse func([]byte) ([]string, error)) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(pkg.Dir), manifest))
+	if err != nil {
+		return nil, nil
+	}
+	names, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path.Join(pkg.Dir, manifest), err)
+	}
+
+	byName := make(map[string]string)
+	for _, other := range g.Packages {
+		if other.Kind == pkg.Kind && other.Dir != pkg.Dir {
+			byName[other.Name] = other.Dir
+		}
+	}
+
+	var deps []string
+	for _, name := range names {
+		if dir, ok := byName[name]; ok {
+			deps = append(deps, dir)
+		}
+	}
+	return deps, nil
+}
+
+// nodeDependencies lists every dependency name in a package.json
+func nodeDependencies(data []byte) ([]string, error) {
+	var manifest struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.PeerDependencies} {
+		for name := range deps {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// cargoDependencies lists every dependency name in a Cargo.toml
+func cargoDependencies(data []byte) ([]string, error) {
+	var manifest struct {
+		Dependencies      map[string]any `toml:"dependencies"`
+		DevDependencies   map[string]any `toml:"dev-dependencies"`
+		BuildDependencies map[string]any `toml:"build-dependencies"`
+	}
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, deps := range []map[string]any{manifest.Dependencies, manifest.DevDependencies, manifest.BuildDependencies} {
+		for name, spec := range deps {
+			// Renamed dependencies: foo = { package = "real-name", ... }
+			if table, ok := spec.(map[string]any); ok {
+				if real, ok := table["package"].(string); ok {
+					name = real
+				}
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,305 @@
+// Package workspace detects multi-module repositories (Go workspaces,
+// pnpm/yarn/npm workspaces, and Cargo workspaces) and resolves their
+// members from the workspace manifests.
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// Kind identifies a workspace manifest format
+type Kind string
+
+const (
+	Go    Kind = "go"
+	Node  Kind = "node"
+	Cargo Kind = "cargo"
+)
+
+// Member is one module or package of a workspace
+type Member struct {
+	Kind Kind
+	// Name is the module path, package name, or crate name
+	Name string
+	// Dir is slash-separated and relative to the workspace root
+	Dir string
+}
+
+// Workspace lists the members found under a root
+type Workspace struct {
+	Root    string
+	Members []Member
+}
+
+// skipDirs are never searched for workspace members
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "target": true, "vendor": true, "dist": true,
+}
+
+// Detect reads every workspace manifest at root. It returns nil if root is
+// not a workspace.
+func Detect(root string) (*Workspace, error) {
+	ws := &Workspace{Root: root}
+
+	detectors := []func(string) ([]Member, error){goMembers, nodeMembers, cargoMembers}
+	for _, detect := range detectors {
+		members, err := detect(root)
+		if err != nil {
+			return nil, err
+		}
+		ws.Members = append(ws.Members, members...)
+	}
+
+	if len(ws.Members) == 0 {
+		return nil, nil
+	}
+	sort.Slice(ws.Members, func(i, j int) bool { return ws.Members[i].Dir < ws.Members[j].Dir })
+	return ws, nil
+}
+
+// Find returns the members matching name, by package name, directory, or
+// the last element of either
+func (ws *Workspace) Find(name string) []Member {
+	var found []Member
+	for _, m := range ws.Members {
+		if m.Name == name || m.Dir == name || path.Base(m.Name) == name || path.Base(m.Dir) == name {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// Names lists the member names, for error messages
+func (ws *Workspace) Names() []string {
+	names := make([]string, len(ws.Members))
+	for i, m := range ws.Members {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// goMembers reads the use directives of go.work
+func goMembers(root string) ([]Member, error) {
+	file, err := os.Open(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "use (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, strings.Trim(line, `"`))
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	for _, dir := range dirs {
+		dir = path.Clean(filepath.ToSlash(dir))
+		name := goModulePath(filepath.Join(root, filepath.FromSlash(dir), "go.mod"))
+		if name == "" {
+			name = dir
+		}
+		members = append(members, Member{Kind: Go, Name: name, Dir: dir})
+	}
+	return members, nil
+}
+
+// goModulePath returns the module directive of a go.mod file
+func goModulePath(goMod string) string {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// nodeMembers reads pnpm-workspace.yaml or the workspaces field of
+// package.json (yarn and npm)
+func nodeMembers(root string) ([]Member, error) {
+	var patterns []string
+
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		var manifest struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("pnpm-workspace.yaml: %w", err)
+		}
+		patterns = manifest.Packages
+	} else if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var manifest struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("package.json: %w", err)
+		}
+		if len(manifest.Workspaces) > 0 {
+			// Either an array of globs or {"packages": [...]}
+			if err := json.Unmarshal(manifest.Workspaces, &patterns); err != nil {
+				var nested struct {
+					Packages []string `json:"packages"`
+				}
+				if err := json.Unmarshal(manifest.Workspaces, &nested); err != nil {
+					return nil, fmt.Errorf("package.json workspaces: %w", err)
+				}
+				patterns = nested.Packages
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	dirs, err := expandPatterns(root, patterns, "package.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	for _, dir := range dirs {
+		name := dir
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "package.json")); err == nil {
+			var pkg struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(data, &pkg) == nil && pkg.Name != "" {
+				name = pkg.Name
+			}
+		}
+		members = append(members, Member{Kind: Node, Name: name, Dir: dir})
+	}
+	return members, nil
+}
+
+// cargoMembers reads the [workspace] members of Cargo.toml
+func cargoMembers(root string) ([]Member, error) {
+	data, err := os.ReadFile(filepath.Join(root, "Cargo.toml"))
+	if err != nil {
+		return nil, nil
+	}
+
+	var manifest struct {
+		Workspace struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("Cargo.toml: %w", err)
+	}
+	if len(manifest.Workspace.Members) == 0 {
+		return nil, nil
+	}
+
+	patterns := manifest.Workspace.Members
+	for _, exclude := range manifest.Workspace.Exclude {
+		patterns = append(patterns, "!"+exclude)
+	}
+	dirs, err := expandPatterns(root, patterns, "Cargo.toml")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+	for _, dir := range dirs {
+		name := dir
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "Cargo.toml")); err == nil {
+			var crate struct {
+				Package struct {
+					Name string `toml:"name"`
+				} `toml:"package"`
+			}
+			if toml.Unmarshal(data, &crate) == nil && crate.Package.Name != "" {
+				name = crate.Package.Name
+			}
+		}
+		members = append(members, Member{Kind: Cargo, Name: name, Dir: dir})
+	}
+	return members, nil
+}
+
+// expandPatterns resolves member globs (with ** and ! negations) to the
+// directories under root that contain manifest
+func expandPatterns(root string, patterns []string, manifest string) ([]string, error) {
+	var include, exclude []glob.Glob
+	for _, p := range patterns {
+		negate := strings.HasPrefix(p, "!")
+		p = path.Clean(strings.TrimPrefix(strings.TrimPrefix(p, "!"), "./"))
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			return nil, fmt.Errorf("workspace pattern %q: %w", p, err)
+		}
+		if negate {
+			exclude = append(exclude, g)
+		} else {
+			include = append(include, g)
+		}
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p != root && skipDirs[d.Name()] {
+			return fs.SkipDir
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !matchAny(include, rel) || matchAny(exclude, rel) {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(p, manifest)); err == nil {
+			dirs = append(dirs, rel)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+func matchAny(globs []glob.Glob, s string) bool {
+	for _, g := range globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}